@@ -0,0 +1,126 @@
+// Package apikey protects the GW2 API keys stored for users: keys are
+// encrypted with a versioned AES-GCM master key and looked up by keyed hash.
+package apikey
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/NesoHQ/gw2style/config"
+)
+
+var ErrUnknownKeyVersion = errors.New("unknown encryption key version")
+
+// Cipher encrypts API keys with the current master key and decrypts them with
+// any configured one, so master keys can be rotated.
+type Cipher struct {
+	current    string
+	aeads      map[string]cipher.AEAD
+	hashSecret []byte
+}
+
+func NewCipher(cnf *config.Config) (*Cipher, error) {
+	keys, err := cnf.EncryptionKeys()
+	if err != nil {
+		return nil, err
+	}
+
+	current, err := cnf.CurrentEncryptionKey()
+	if err != nil {
+		return nil, err
+	}
+
+	c := &Cipher{
+		current:    current.Version,
+		aeads:      make(map[string]cipher.AEAD, len(keys)),
+		hashSecret: []byte(cnf.ApiKeyHashSecret),
+	}
+	for _, key := range keys {
+		block, err := aes.NewCipher(key.Key)
+		if err != nil {
+			return nil, fmt.Errorf("invalid encryption key %q: %w", key.Version, err)
+		}
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, fmt.Errorf("invalid encryption key %q: %w", key.Version, err)
+		}
+		c.aeads[key.Version] = aead
+	}
+
+	return c, nil
+}
+
+// CurrentVersion is the version of the key Encrypt uses
+func (c *Cipher) CurrentVersion() string {
+	return c.current
+}
+
+// Encrypt seals an API key with the current master key. The user ID is bound
+// as additional data so a ciphertext cannot be copied to another user. The
+// result has the form "version:base64(nonce|ciphertext)".
+func (c *Cipher) Encrypt(apiKey, userID string) (string, error) {
+	aead := c.aeads[c.current]
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	sealed := aead.Seal(nonce, nonce, []byte(apiKey), []byte(userID))
+	return c.current + ":" + base64.RawStdEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypt opens a value produced by Encrypt for the same user
+func (c *Cipher) Decrypt(encrypted, userID string) (string, error) {
+	version, encoded, ok := strings.Cut(encrypted, ":")
+	if !ok {
+		return "", fmt.Errorf("malformed encrypted API key")
+	}
+
+	aead, ok := c.aeads[version]
+	if !ok {
+		return "", fmt.Errorf("%w: %s", ErrUnknownKeyVersion, version)
+	}
+
+	sealed, err := base64.RawStdEncoding.DecodeString(encoded)
+	if err != nil || len(sealed) < aead.NonceSize() {
+		return "", fmt.Errorf("malformed encrypted API key")
+	}
+
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	plaintext, err := aead.Open(nil, nonce, ciphertext, []byte(userID))
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt API key: %w", err)
+	}
+
+	return string(plaintext), nil
+}
+
+// KeyVersion returns the master key version an encrypted value was sealed with
+func KeyVersion(encrypted string) string {
+	version, _, _ := strings.Cut(encrypted, ":")
+	return version
+}
+
+// Hash returns the keyed hash used to find a user by API key without storing it in plaintext
+func (c *Cipher) Hash(apiKey string) string {
+	mac := hmac.New(sha256.New, c.hashSecret)
+	mac.Write([]byte(apiKey))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Mask hides all but the start and end of an API key
+func Mask(apiKey string) string {
+	if len(apiKey) <= 12 {
+		return strings.Repeat("*", len(apiKey))
+	}
+	return apiKey[:8] + strings.Repeat("*", 8) + apiKey[len(apiKey)-4:]
+}
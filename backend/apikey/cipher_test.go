@@ -0,0 +1,139 @@
+package apikey
+
+import (
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/NesoHQ/gw2style/config"
+)
+
+const testAPIKey = "ABCDEF01-2345-6789-ABCD-EF0123456789ABCDEF01-2345-6789-ABCD-EF0123456789"
+
+func testEncryptionKey(b byte) string {
+	return base64.StdEncoding.EncodeToString([]byte(strings.Repeat(string(b), config.EncryptionKeySize)))
+}
+
+func newTestCipher(t *testing.T, keys, version string) *Cipher {
+	t.Helper()
+
+	c, err := NewCipher(&config.Config{
+		ApiKeyEncryptionKeys:    keys,
+		ApiKeyEncryptionVersion: version,
+		ApiKeyHashSecret:        strings.Repeat("s", 32),
+	})
+	if err != nil {
+		t.Fatalf("NewCipher: %v", err)
+	}
+	return c
+}
+
+func TestEncryptDecryptRoundTrip(t *testing.T) {
+	c := newTestCipher(t, "v1:"+testEncryptionKey('a'), "")
+
+	encrypted, err := c.Encrypt(testAPIKey, "user-1")
+	if err != nil {
+		t.Fatalf("Encrypt: %v", err)
+	}
+	if strings.Contains(encrypted, testAPIKey) {
+		t.Fatal("encrypted value contains the API key")
+	}
+	if KeyVersion(encrypted) != "v1" {
+		t.Errorf("KeyVersion = %q, want v1", KeyVersion(encrypted))
+	}
+
+	again, err := c.Encrypt(testAPIKey, "user-1")
+	if err != nil {
+		t.Fatalf("Encrypt: %v", err)
+	}
+	if again == encrypted {
+		t.Error("encrypting twice gave the same value, nonces are reused")
+	}
+
+	decrypted, err := c.Decrypt(encrypted, "user-1")
+	if err != nil {
+		t.Fatalf("Decrypt: %v", err)
+	}
+	if decrypted != testAPIKey {
+		t.Errorf("Decrypt = %q, want %q", decrypted, testAPIKey)
+	}
+}
+
+func TestDecryptRejectsTampering(t *testing.T) {
+	c := newTestCipher(t, "v1:"+testEncryptionKey('a'), "")
+
+	encrypted, err := c.Encrypt(testAPIKey, "user-1")
+	if err != nil {
+		t.Fatalf("Encrypt: %v", err)
+	}
+
+	if _, err := c.Decrypt(encrypted, "user-2"); err == nil {
+		t.Error("Decrypt succeeded for another user")
+	}
+
+	version, encoded, _ := strings.Cut(encrypted, ":")
+	sealed, _ := base64.RawStdEncoding.DecodeString(encoded)
+	sealed[len(sealed)-1] ^= 1
+	tampered := version + ":" + base64.RawStdEncoding.EncodeToString(sealed)
+	if _, err := c.Decrypt(tampered, "user-1"); err == nil {
+		t.Error("Decrypt succeeded for a modified ciphertext")
+	}
+
+	for _, malformed := range []string{"", "v1", "v1:!!!", "v1:AAAA"} {
+		if _, err := c.Decrypt(malformed, "user-1"); err == nil {
+			t.Errorf("Decrypt(%q) succeeded", malformed)
+		}
+	}
+}
+
+func TestKeyRotation(t *testing.T) {
+	old := newTestCipher(t, "v1:"+testEncryptionKey('a'), "")
+	encrypted, err := old.Encrypt(testAPIKey, "user-1")
+	if err != nil {
+		t.Fatalf("Encrypt: %v", err)
+	}
+
+	rotated := newTestCipher(t, "v1:"+testEncryptionKey('a')+",v2:"+testEncryptionKey('b'), "v2")
+	if rotated.CurrentVersion() != "v2" {
+		t.Errorf("CurrentVersion = %q, want v2", rotated.CurrentVersion())
+	}
+
+	decrypted, err := rotated.Decrypt(encrypted, "user-1")
+	if err != nil || decrypted != testAPIKey {
+		t.Fatalf("Decrypt of a v1 value = %q, %v", decrypted, err)
+	}
+
+	reencrypted, err := rotated.Encrypt(testAPIKey, "user-1")
+	if err != nil {
+		t.Fatalf("Encrypt: %v", err)
+	}
+	if KeyVersion(reencrypted) != "v2" {
+		t.Errorf("KeyVersion = %q, want v2", KeyVersion(reencrypted))
+	}
+
+	// Once v1 is retired its values can no longer be read
+	retired := newTestCipher(t, "v2:"+testEncryptionKey('b'), "")
+	if _, err := retired.Decrypt(encrypted, "user-1"); !errors.Is(err, ErrUnknownKeyVersion) {
+		t.Errorf("err = %v, want ErrUnknownKeyVersion", err)
+	}
+}
+
+func TestHashAndMask(t *testing.T) {
+	c := newTestCipher(t, "v1:"+testEncryptionKey('a'), "")
+
+	if c.Hash(testAPIKey) != c.Hash(testAPIKey) {
+		t.Error("Hash is not deterministic")
+	}
+	if c.Hash(testAPIKey) == c.Hash(testAPIKey+"x") {
+		t.Error("different keys have the same hash")
+	}
+
+	masked := Mask(testAPIKey)
+	if masked != "ABCDEF01********6789" {
+		t.Errorf("Mask = %q", masked)
+	}
+	if Mask("short") != "*****" {
+		t.Errorf("Mask(short) = %q", Mask("short"))
+	}
+}
@@ -0,0 +1,165 @@
+package bot
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+const banUsage = "Usage: `!ban <account> <duration|permanent> <reason>` or `!unban <account> [reason]`. " +
+	"Durations are hours or days, e.g. `12h` or `7d`. Quote account names with spaces: `!ban \"Some Player.1234\" 7d spam`"
+
+// unknownAccountMessage is the API error for an account that is neither a
+// registered account name nor a GW2 account ID (repo.ErrUnknownAccount)
+const unknownAccountMessage = "unknown account"
+
+type BanRequest struct {
+	Account            string `json:"account"`
+	Reason             string `json:"reason"`
+	DurationHours      int    `json:"duration_hours"`
+	ModeratorUsername  string `json:"moderator_username"`
+	ModeratorDiscordID string `json:"moderator_discord_id"`
+}
+
+type LiftBanRequest struct {
+	ModeratorUsername  string `json:"moderator_username"`
+	ModeratorDiscordID string `json:"moderator_discord_id"`
+	Reason             string `json:"reason"`
+}
+
+// handleMessageCreate runs the !ban and !unban commands posted in the moderation channel
+func (b *Bot) handleMessageCreate(s *discordgo.Session, m *discordgo.MessageCreate) {
+	if m.Author == nil || m.Author.Bot || m.ChannelID != b.config.DiscordModChannel {
+		return
+	}
+
+	command, args, _ := strings.Cut(strings.TrimSpace(m.Content), " ")
+	switch command {
+	case "!ban":
+		b.handleBanCommand(m.Message, args)
+	case "!unban":
+		b.handleUnbanCommand(m.Message, args)
+	}
+}
+
+func (b *Bot) handleBanCommand(msg *discordgo.Message, args string) {
+	account, rest := splitAccount(args)
+	durationArg, reason, _ := strings.Cut(rest, " ")
+	reason = strings.TrimSpace(reason)
+
+	hours, ok := parseBanDuration(durationArg)
+	if account == "" || !ok || reason == "" {
+		b.session.ChannelMessageSendReply(msg.ChannelID, banUsage, msg.Reference())
+		return
+	}
+
+	slog.Info("Processing ban", "account", account, "hours", hours, "moderator", msg.Author.Username)
+
+	status, err := b.postAdmin("/admin/bans", BanRequest{
+		Account:            account,
+		Reason:             reason,
+		DurationHours:      hours,
+		ModeratorUsername:  msg.Author.Username,
+		ModeratorDiscordID: msg.Author.ID,
+	})
+	if err != nil {
+		slog.Error("Error calling API", "error", err)
+		b.sendErrorReply(msg.ChannelID, msg.ID, "Failed to ban "+account)
+		return
+	}
+	if status == http.StatusNotFound {
+		b.sendErrorReply(msg.ChannelID, msg.ID, unknownAccountReply(account))
+		return
+	}
+	if status != http.StatusCreated {
+		slog.Error("API returned error", "status", status)
+		b.sendErrorReply(msg.ChannelID, msg.ID, fmt.Sprintf("Failed to ban %s (status: %d)", account, status))
+		return
+	}
+
+	length := "permanently"
+	if hours > 0 {
+		length = "for " + durationArg
+	}
+	b.session.ChannelMessageSend(msg.ChannelID, fmt.Sprintf("🔨 **%s** has been banned %s by %s: %s", account, length, msg.Author.Username, reason))
+}
+
+func (b *Bot) handleUnbanCommand(msg *discordgo.Message, args string) {
+	account, reason := splitAccount(args)
+	if account == "" {
+		b.session.ChannelMessageSendReply(msg.ChannelID, banUsage, msg.Reference())
+		return
+	}
+
+	slog.Info("Processing unban", "account", account, "moderator", msg.Author.Username)
+
+	status, message, err := b.postAdminMessage("/admin/bans/"+url.PathEscape(account)+"/lift", LiftBanRequest{
+		ModeratorUsername:  msg.Author.Username,
+		ModeratorDiscordID: msg.Author.ID,
+		Reason:             strings.TrimSpace(reason),
+	})
+	if err != nil {
+		slog.Error("Error calling API", "error", err)
+		b.sendErrorReply(msg.ChannelID, msg.ID, "Failed to unban "+account)
+		return
+	}
+	if status == http.StatusNotFound && message == unknownAccountMessage {
+		b.sendErrorReply(msg.ChannelID, msg.ID, unknownAccountReply(account))
+		return
+	}
+	if status == http.StatusNotFound {
+		b.sendErrorReply(msg.ChannelID, msg.ID, account+" is not banned")
+		return
+	}
+	if status != http.StatusOK {
+		slog.Error("API returned error", "status", status)
+		b.sendErrorReply(msg.ChannelID, msg.ID, fmt.Sprintf("Failed to unban %s (status: %d)", account, status))
+		return
+	}
+
+	b.session.ChannelMessageSend(msg.ChannelID, fmt.Sprintf("✅ Ban on **%s** lifted by %s", account, msg.Author.Username))
+}
+
+func unknownAccountReply(account string) string {
+	return fmt.Sprintf("Unknown account %s: use the GW2 account name of a registered user or a GW2 account ID", account)
+}
+
+// splitAccount takes the account off the front of args. GW2 account names
+// may contain spaces, so a double-quoted account is read up to the closing quote.
+func splitAccount(args string) (account, rest string) {
+	args = strings.TrimSpace(args)
+	if quoted, ok := strings.CutPrefix(args, `"`); ok {
+		account, rest, _ = strings.Cut(quoted, `"`)
+		return strings.TrimSpace(account), strings.TrimSpace(rest)
+	}
+	account, rest, _ = strings.Cut(args, " ")
+	return account, strings.TrimSpace(rest)
+}
+
+// parseBanDuration reads "permanent", "<n>h" or "<n>d" as hours, 0 meaning permanent
+func parseBanDuration(s string) (int, bool) {
+	if s == "permanent" || s == "perm" {
+		return 0, true
+	}
+	if len(s) < 2 {
+		return 0, false
+	}
+
+	n, err := strconv.Atoi(s[:len(s)-1])
+	if err != nil || n <= 0 {
+		return 0, false
+	}
+
+	switch s[len(s)-1] {
+	case 'h':
+		return n, true
+	case 'd':
+		return n * 24, true
+	}
+	return 0, false
+}
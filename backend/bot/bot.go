@@ -7,15 +7,19 @@ import (
 	"log/slog"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/NesoHQ/gw2style/config"
+	"github.com/NesoHQ/gw2style/serviceauth"
 	"github.com/bwmarrin/discordgo"
 )
 
 type Bot struct {
-	session *discordgo.Session
-	config  *config.Config
-	apiURL  string
+	session    *discordgo.Session
+	config     *config.Config
+	apiURL     string
+	signer     *serviceauth.Signer
+	httpClient *http.Client
 }
 
 type PublishRequest struct {
@@ -35,14 +39,23 @@ func NewBot(cfg *config.Config) (*Bot, error) {
 		return nil, fmt.Errorf("error creating Discord session: %w", err)
 	}
 
+	// Admin API requests are signed with a service key, never the Discord token
+	signer, err := serviceauth.NewSigner(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("error loading service key: %w", err)
+	}
+
 	bot := &Bot{
-		session: session,
-		config:  cfg,
-		apiURL:  fmt.Sprintf("http://localhost:%d/api/v1", cfg.HttpPort),
+		session:    session,
+		config:     cfg,
+		apiURL:     fmt.Sprintf("http://localhost:%d/api/v1", cfg.HttpPort),
+		signer:     signer,
+		httpClient: &http.Client{Timeout: 10 * time.Second},
 	}
 
 	// Register event handlers
 	session.AddHandler(bot.handleReactionAdd)
+	session.AddHandler(bot.handleMessageCreate)
 
 	// Set intents - need message content to read webhook messages
 	session.Identify.Intents = discordgo.IntentsGuildMessageReactions |
@@ -85,6 +98,17 @@ func (b *Bot) handleReactionAdd(s *discordgo.Session, r *discordgo.MessageReacti
 		return
 	}
 
+	// Report messages carry a report ID and are handled separately
+	if reportID := extractReportID(msg); reportID != "" {
+		user, err := s.User(r.UserID)
+		if err != nil {
+			slog.Error("Error fetching user", "error", err)
+			return
+		}
+		b.handleReportReaction(reportID, r.Emoji.Name, user, msg)
+		return
+	}
+
 	// Extract post ID from message content or embeds
 	postID := extractPostID(msg)
 	if postID == "" {
@@ -120,33 +144,16 @@ func (b *Bot) handleApproval(postID string, user *discordgo.User, msg *discordgo
 		ModeratorDiscordID: user.ID,
 	}
 
-	jsonData, err := json.Marshal(reqBody)
-	if err != nil {
-		slog.Error("Error marshaling request", "error", err)
-		return
-	}
-
-	req, err := http.NewRequest("POST", fmt.Sprintf("%s/admin/posts/%s/publish", b.apiURL, postID), bytes.NewBuffer(jsonData))
-	if err != nil {
-		slog.Error("Error creating request", "error", err)
-		return
-	}
-
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", "Bot "+b.config.DiscordBotToken)
-
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	status, err := b.postAdmin(fmt.Sprintf("/admin/posts/%s/publish", postID), reqBody)
 	if err != nil {
 		slog.Error("Error calling API", "error", err)
 		b.sendErrorReply(msg.ChannelID, msg.ID, "Failed to approve post")
 		return
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		slog.Error("API returned error", "status", resp.StatusCode)
-		b.sendErrorReply(msg.ChannelID, msg.ID, fmt.Sprintf("Failed to approve post (status: %d)", resp.StatusCode))
+	if status != http.StatusOK {
+		slog.Error("API returned error", "status", status)
+		b.sendErrorReply(msg.ChannelID, msg.ID, fmt.Sprintf("Failed to approve post (status: %d)", status))
 		return
 	}
 
@@ -181,33 +188,16 @@ func (b *Bot) handleRejection(postID string, user *discordgo.User, msg *discordg
 		Reason:             "Rejected by moderator",
 	}
 
-	jsonData, err := json.Marshal(reqBody)
-	if err != nil {
-		slog.Error("Error marshaling request", "error", err)
-		return
-	}
-
-	req, err := http.NewRequest("POST", fmt.Sprintf("%s/admin/posts/%s/reject", b.apiURL, postID), bytes.NewBuffer(jsonData))
-	if err != nil {
-		slog.Error("Error creating request", "error", err)
-		return
-	}
-
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", "Bot "+b.config.DiscordBotToken)
-
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	status, err := b.postAdmin(fmt.Sprintf("/admin/posts/%s/reject", postID), reqBody)
 	if err != nil {
 		slog.Error("Error calling API", "error", err)
 		b.sendErrorReply(msg.ChannelID, msg.ID, "Failed to reject post")
 		return
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		slog.Error("API returned error", "status", resp.StatusCode)
-		b.sendErrorReply(msg.ChannelID, msg.ID, fmt.Sprintf("Failed to reject post (status: %d)", resp.StatusCode))
+	if status != http.StatusOK {
+		slog.Error("API returned error", "status", status)
+		b.sendErrorReply(msg.ChannelID, msg.ID, fmt.Sprintf("Failed to reject post (status: %d)", status))
 		return
 	}
 
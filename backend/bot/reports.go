@@ -0,0 +1,137 @@
+package bot
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+type ResolveReportRequest struct {
+	ModeratorUsername  string `json:"moderator_username"`
+	ModeratorDiscordID string `json:"moderator_discord_id"`
+	Reason             string `json:"reason"`
+	Unpublish          bool   `json:"unpublish"`
+}
+
+// handleReportReaction resolves or dismisses a report based on the moderator's reaction
+func (b *Bot) handleReportReaction(reportID, emoji string, user *discordgo.User, msg *discordgo.Message) {
+	var action string
+	reqBody := ResolveReportRequest{
+		ModeratorUsername:  user.Username,
+		ModeratorDiscordID: user.ID,
+	}
+
+	switch emoji {
+	case "✅":
+		action = "resolve"
+		reqBody.Reason = "Report upheld by moderator"
+		reqBody.Unpublish = true
+	case "❌":
+		action = "dismiss"
+		reqBody.Reason = "Report dismissed by moderator"
+	default:
+		// Ignore other reactions
+		return
+	}
+
+	slog.Info("Processing report", "reportID", reportID, "action", action, "moderator", user.Username)
+
+	status, err := b.postAdmin(fmt.Sprintf("/admin/reports/%s/%s", reportID, action), reqBody)
+	if err != nil {
+		slog.Error("Error calling API", "error", err)
+		b.sendErrorReply(msg.ChannelID, msg.ID, fmt.Sprintf("Failed to %s report", action))
+		return
+	}
+
+	if status != http.StatusOK {
+		slog.Error("API returned error", "status", status)
+		b.sendErrorReply(msg.ChannelID, msg.ID, fmt.Sprintf("Failed to %s report (status: %d)", action, status))
+		return
+	}
+
+	var resultMsg string
+	if reqBody.Unpublish {
+		resultMsg = fmt.Sprintf("✅ Report #%s has been **UPHELD** by %s and the post was unpublished", reportID, user.Username)
+	} else {
+		resultMsg = fmt.Sprintf("❌ Report #%s has been **DISMISSED** by %s", reportID, user.Username)
+	}
+	b.session.ChannelMessageSend(msg.ChannelID, resultMsg)
+
+	// Delete the moderation message
+	err = b.session.ChannelMessageDelete(msg.ChannelID, msg.ID)
+	if err != nil {
+		slog.Error("Error deleting moderation message", "error", err)
+	}
+
+	slog.Info("Report handled successfully", "reportID", reportID, "action", action)
+}
+
+// postAdmin sends a signed JSON request to a bot-authenticated admin endpoint and returns the status code
+func (b *Bot) postAdmin(path string, body interface{}) (int, error) {
+	status, _, err := b.postAdminMessage(path, body)
+	return status, err
+}
+
+// postAdminMessage is postAdmin that also returns the message of an error
+// response, for replies that need to tell apart failures sharing a status
+func (b *Bot) postAdminMessage(path string, body interface{}) (int, string, error) {
+	jsonData, err := json.Marshal(body)
+	if err != nil {
+		return 0, "", fmt.Errorf("error marshaling request: %w", err)
+	}
+
+	req, err := http.NewRequest("POST", b.apiURL+path, bytes.NewReader(jsonData))
+	if err != nil {
+		return 0, "", fmt.Errorf("error creating request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	if err := b.signer.Sign(req, jsonData); err != nil {
+		return 0, "", err
+	}
+
+	resp, err := b.httpClient.Do(req)
+	if err != nil {
+		return 0, "", err
+	}
+	defer resp.Body.Close()
+
+	var payload struct {
+		Message string `json:"message"`
+	}
+	if resp.StatusCode >= http.StatusBadRequest {
+		json.NewDecoder(io.LimitReader(resp.Body, 1<<16)).Decode(&payload)
+	}
+	return resp.StatusCode, payload.Message, nil
+}
+
+// extractReportID extracts the report ID from a report moderation message
+// Expected content format: "🚩 **New report awaiting review** (Report ID: 12)"
+func extractReportID(msg *discordgo.Message) string {
+	if msg.Content != "" {
+		idIndex := strings.Index(msg.Content, "(Report ID: ")
+		if idIndex != -1 {
+			idStart := idIndex + len("(Report ID: ")
+			idEnd := strings.Index(msg.Content[idStart:], ")")
+			if idEnd != -1 {
+				return msg.Content[idStart : idStart+idEnd]
+			}
+		}
+	}
+
+	for _, embed := range msg.Embeds {
+		for _, field := range embed.Fields {
+			if field.Name == "Report ID" {
+				return field.Value
+			}
+		}
+	}
+
+	return ""
+}
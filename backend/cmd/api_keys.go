@@ -0,0 +1,90 @@
+package cmd
+
+import (
+	"encoding/base64"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/NesoHQ/gw2style/apikey"
+	"github.com/NesoHQ/gw2style/config"
+	"github.com/NesoHQ/gw2style/db"
+	"github.com/NesoHQ/gw2style/repo"
+)
+
+var encryptionKeyList = keyList[[]config.EncryptionKey]{
+	envVar:   "API_KEY_ENCRYPTION_KEYS",
+	idFlag:   "version",
+	idPrefix: "v",
+	size:     config.EncryptionKeySize,
+	encoding: base64.StdEncoding,
+	parse:    config.ParseEncryptionKeys,
+}
+
+// APIKeys manages the encryption of the GW2 API keys stored for users.
+//
+// Rotating the master key:
+//  1. generate a key and append it to API_KEY_ENCRYPTION_KEYS
+//  2. set API_KEY_ENCRYPTION_VERSION to it and restart every instance
+//  3. run `api-keys encrypt` to re-encrypt the existing rows
+//  4. remove the old key from API_KEY_ENCRYPTION_KEYS
+func APIKeys(args []string) {
+	if len(args) == 0 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	switch args[0] {
+	case "generate-key":
+		generateEncryptionKey(args[1:])
+	case "encrypt":
+		encryptAPIKeys(args[1:])
+	default:
+		fmt.Fprintf(os.Stderr, "unknown api-keys command %q\n\n%s", args[0], usage)
+		os.Exit(2)
+	}
+}
+
+func generateEncryptionKey(args []string) {
+	version := encryptionKeyList.generate("api-keys generate-key", args)
+	fmt.Printf("Then set API_KEY_ENCRYPTION_VERSION=%s and run `gw2style api-keys encrypt`.\n", version)
+}
+
+func encryptAPIKeys(args []string) {
+	flags := flag.NewFlagSet("api-keys encrypt", flag.ExitOnError)
+	batchSize := flags.Int("batch", 500, "number of users updated per transaction")
+	flags.Parse(args)
+
+	if *batchSize < 1 {
+		fmt.Fprintln(os.Stderr, "-batch must be at least 1")
+		os.Exit(2)
+	}
+
+	cnf := config.GetConfig()
+
+	cipher, err := apikey.NewCipher(cnf)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	DB, err := db.GetDbConnection(cnf.DB)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	defer db.CloseDB(DB)
+
+	// The encrypted columns are added by a migration
+	if err := db.MigrateDB(DB, cnf.MigrationSource); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	updated, err := repo.NewUserRepo(DB, cipher).EncryptAPIKeys(*batchSize)
+	fmt.Printf("Encrypted %d API keys with key version %s.\n", updated, cipher.CurrentVersion())
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
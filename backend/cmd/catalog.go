@@ -0,0 +1,87 @@
+package cmd
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"slices"
+	"strings"
+
+	"github.com/NesoHQ/gw2style/config"
+	"github.com/NesoHQ/gw2style/db"
+	"github.com/NesoHQ/gw2style/gw2api"
+	"github.com/NesoHQ/gw2style/jobs"
+	"github.com/NesoHQ/gw2style/repo"
+)
+
+// Catalog maintains the local copy of the GW2 cosmetics served under
+// /api/v1/catalog. Run `catalog sync` after every GW2 release; it only
+// fetches what is new unless -full is given.
+func Catalog(args []string) {
+	if len(args) == 0 || args[0] != "sync" {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	flags := flag.NewFlagSet("catalog sync", flag.ExitOnError)
+	full := flags.Bool("full", false, "refetch entries that are already stored")
+	only := flags.String("only", "", "comma separated catalogs to sync (skins, colors, outfits, gliders, mount_skins)")
+	flags.Parse(args[1:])
+
+	kinds := repo.CatalogKinds
+	if *only != "" {
+		kinds = nil
+		for _, name := range strings.Split(*only, ",") {
+			kind := repo.CatalogKind(strings.TrimSpace(name))
+			if !slices.Contains(repo.CatalogKinds, kind) {
+				fmt.Fprintf(os.Stderr, "unknown catalog %q\n", kind)
+				os.Exit(2)
+			}
+			kinds = append(kinds, kind)
+		}
+	}
+
+	cnf := config.GetConfig()
+
+	DB, err := db.GetDbConnection(cnf.DB)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	defer db.CloseDB(DB)
+
+	if err := db.MigrateDB(DB, cnf.MigrationSource); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	// Stored batches are kept when interrupted, the next run picks up the rest
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	sync := jobs.NewCatalogSync(repo.NewCatalogRepository(DB.DB), gw2api.NewClient(cnf))
+	fetched := 0
+	for _, kind := range kinds {
+		result, err := sync.SyncKind(ctx, kind, *full)
+		fmt.Printf("%-12s %6d on GW2, %6d fetched\n", kind, result.Total, result.Fetched)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		if kind != repo.CatalogColors {
+			fetched += result.Fetched
+		}
+	}
+
+	// Posts are searchable by the names of their skins, which may only just have been stored
+	if fetched > 0 {
+		reindexed, err := repo.NewPostRepository(DB.DB).ReindexEquipment(ctx)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		fmt.Printf("reindexed %d posts with equipment\n", reindexed)
+	}
+}
@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+)
+
+// keyList is a comma separated list of "id:secret" entries read from envVar,
+// such as JWT_KEYS or SERVICE_AUTH_KEYS
+type keyList[T any] struct {
+	envVar   string
+	idFlag   string // Flag that sets the ID of a generated entry
+	idPrefix string // Generated IDs default to idPrefix followed by the current time
+	size     int    // Secret length in bytes
+	encoding *base64.Encoding
+	parse    func(string) (T, error) // Parser the server reads envVar with
+}
+
+// generate handles a generate command: it prints a new entry with a random
+// secret for the list and returns the entry's ID
+func (l keyList[T]) generate(command string, args []string) string {
+	flags := flag.NewFlagSet(command, flag.ExitOnError)
+	id := flags.String(l.idFlag, l.idPrefix+time.Now().UTC().Format("20060102150405"), l.idFlag+" of the new key")
+	flags.Parse(args)
+
+	secret := make([]byte, l.size)
+	if _, err := rand.Read(secret); err != nil {
+		fmt.Fprintln(os.Stderr, "failed to generate key:", err)
+		os.Exit(1)
+	}
+	entry := *id + ":" + l.encoding.EncodeToString(secret)
+
+	// Validate the entry the same way the server will
+	if _, err := l.parse(entry); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("Append this entry to %s (comma separated):\n", l.envVar)
+	fmt.Println()
+	fmt.Println("  " + entry)
+	fmt.Println()
+	return *id
+}
@@ -0,0 +1,108 @@
+package cmd
+
+import (
+	"flag"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/NesoHQ/gw2style/gw2api"
+	"github.com/NesoHQ/gw2style/gw2api/gw2fake"
+)
+
+const fakeAccountID = "00000000-0000-0000-0000-000000000001"
+
+// GW2Fake serves the fake GW2 API so the server can run offline with
+// GW2_API_BASE_URL pointing at it
+func GW2Fake(args []string) {
+	flags := flag.NewFlagSet("gw2-fake", flag.ExitOnError)
+	addr := flags.String("addr", "127.0.0.1:8090", "address to listen on")
+	key := flags.String("key", "FAKE0000-0000-0000-0000-000000000000", "API key the fake accepts")
+	account := flags.String("account", "Fake Player.1234", "account name of the key")
+	permissions := flags.String("permissions", strings.Join(gw2api.RequiredPermissions, ","), "comma separated permissions of the key")
+	flags.Parse(args)
+
+	fake := gw2fake.New()
+	fake.AddKey(*key, gw2fake.Key{
+		Name:        "gw2style fake",
+		Permissions: strings.Split(*permissions, ","),
+		Account: gw2api.Account{
+			ID:      fakeAccountID,
+			Name:    *account,
+			World:   1001,
+			Created: time.Date(2012, 8, 28, 0, 0, 0, 0, time.UTC),
+		},
+	})
+	seedCosmetics(fake)
+	seedCharacter(fake)
+
+	fmt.Printf("Fake GW2 API listening on http://%s\n", *addr)
+	fmt.Printf("Set GW2_API_BASE_URL=http://%s and log in with %s\n", *addr, *key)
+
+	if err := http.ListenAndServe(*addr, fake); err != nil {
+		slog.Error("Fake GW2 API stopped", "error", err.Error())
+		os.Exit(1)
+	}
+}
+
+// seedCosmetics registers a few skins, dyes and other cosmetics so posts with
+// equipment can be created against the fake
+func seedCosmetics(fake *gw2fake.Server) {
+	dyeable := []*gw2api.DyeSlot{{ColorID: 1, Material: "cloth"}, {ColorID: 1, Material: "leather"}, {ColorID: 1, Material: "metal"}, nil}
+
+	id := 1
+	for _, slot := range []string{"Helm", "Shoulders", "Coat", "Gloves", "Leggings", "Boots", "HelmAquatic"} {
+		skin := gw2api.Skin{ID: id, Name: "Fake " + slot, Type: "Armor", Rarity: "Exotic"}
+		skin.Details.Type = slot
+		skin.Details.WeightClass = "Light"
+		skin.Details.DyeSlots = &gw2api.SkinDyeSlots{Default: dyeable}
+		fake.AddSkin(skin)
+		id++
+	}
+	for _, weapon := range []string{"Greatsword", "Sword", "Shield", "Staff", "Trident"} {
+		skin := gw2api.Skin{ID: id, Name: "Fake " + weapon, Type: "Weapon", Rarity: "Exotic"}
+		skin.Details.Type = weapon
+		fake.AddSkin(skin)
+		id++
+	}
+	fake.AddSkin(gw2api.Skin{ID: id, Name: "Fake Backpack", Type: "Back", Rarity: "Exotic"})
+
+	for i, name := range []string{"Dye Remover", "Black", "Abyss", "Celestial"} {
+		fake.AddColor(gw2api.Color{ID: i + 1, Name: name, BaseRGB: []int{128, 26, 26}, Categories: []string{"Gray", "Vibrant", "Starter"}})
+	}
+	fake.AddOutfit(gw2api.Outfit{ID: 1, Name: "Fake Outfit"})
+	fake.AddGlider(gw2api.Glider{ID: 1, Name: "Fake Glider", DefaultDyes: []int{1, 1, 1, 1}})
+	fake.AddMountSkin(gw2api.MountSkin{ID: 1, Name: "Fake Raptor", Mount: "raptor", DyeSlots: make([]gw2api.DyeSlot, 4)})
+}
+
+// seedCharacter gives the fake account a character wearing the seeded skins,
+// so importing a look from a character can be tried against the fake
+func seedCharacter(fake *gw2fake.Server) {
+	dye := func(id int) *int { return &id }
+
+	// The coat was never transmuted and shows the default skin of its item
+	fake.AddItem(gw2api.Item{ID: 100, Name: "Fake Coat", Type: "Armor", Rarity: "Exotic", DefaultSkin: 3})
+
+	fake.AddCharacter(fakeAccountID, gw2fake.Character{
+		Core: gw2api.CharacterCore{
+			Name:       "Fake Character",
+			Race:       "Sylvari",
+			Gender:     "Female",
+			Profession: "Mesmer",
+			Level:      80,
+			Created:    time.Date(2013, 1, 1, 0, 0, 0, 0, time.UTC),
+		},
+		Equipment: []gw2api.EquippedItem{
+			{ID: 101, Slot: "Helm", Skin: 1, Dyes: []*int{dye(2), dye(3), nil, nil}, Location: "Equipped"},
+			{ID: 102, Slot: "Shoulders", Skin: 2, Dyes: []*int{dye(3), dye(3), dye(4), nil}, Location: "Equipped"},
+			{ID: 100, Slot: "Coat", Dyes: []*int{dye(2), nil, nil, nil}, Location: "Equipped"},
+			{ID: 103, Slot: "WeaponA1", Skin: 9, Location: "Equipped"},
+			{ID: 104, Slot: "Backpack", Skin: 13, Location: "Equipped"},
+			// Kept in another equipment tab, so not part of the look
+			{ID: 105, Slot: "Boots", Skin: 6, Location: "Armory"},
+		},
+	})
+}
@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"os"
+
+	"github.com/NesoHQ/gw2style/config"
+)
+
+var jwtKeyList = keyList[[]config.JWTKey]{
+	envVar:   "JWT_KEYS",
+	idFlag:   "kid",
+	idPrefix: "k",
+	size:     32,
+	encoding: base64.RawURLEncoding,
+	parse:    config.ParseJWTKeys,
+}
+
+// JWTKeys manages the keys tokens are signed with.
+//
+// Rotating keys without logging everyone out:
+//  1. generate a key and append it to JWT_KEYS, keep JWT_SIGNING_KEY_ID unchanged
+//  2. once every instance runs with the new key, point JWT_SIGNING_KEY_ID at it
+//  3. after the access token lifetime has passed, remove the old key from JWT_KEYS
+func JWTKeys(args []string) {
+	if len(args) == 0 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	switch args[0] {
+	case "generate":
+		generateJWTKey(args[1:])
+	case "list":
+		listJWTKeys()
+	default:
+		fmt.Fprintf(os.Stderr, "unknown jwt-keys command %q\n\n%s", args[0], usage)
+		os.Exit(2)
+	}
+}
+
+func generateJWTKey(args []string) {
+	kid := jwtKeyList.generate("jwt-keys generate", args)
+	fmt.Printf("Then set JWT_SIGNING_KEY_ID=%s once every instance knows the key.\n", kid)
+}
+
+func listJWTKeys() {
+	cnf := config.GetConfig()
+
+	keys, err := cnf.JWTKeys()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	signingKey, err := cnf.JWTSigningKey()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("%-24s %-18s %s\n", "KID", "FINGERPRINT", "USE")
+	for _, key := range keys {
+		use := "verify"
+		if key.ID == signingKey.ID {
+			use = "sign, verify"
+		}
+		sum := sha256.Sum256(key.Secret)
+		fmt.Printf("%-24s %-18s %s\n", key.ID, hex.EncodeToString(sum[:8]), use)
+	}
+}
@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/NesoHQ/gw2style/config"
+	"github.com/NesoHQ/gw2style/db"
+	"github.com/NesoHQ/gw2style/repo"
+)
+
+// Roles changes user roles from the command line. It is how the first admin
+// is appointed; after that admins use the /api/v1/admin/users endpoints.
+func Roles(args []string) {
+	if len(args) == 0 || args[0] != "set" {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	flags := flag.NewFlagSet("roles set", flag.ExitOnError)
+	userID := flags.String("user", "", "GW2 account ID of the user")
+	role := flags.String("role", "", "user, trusted_creator, moderator or admin")
+	reason := flags.String("reason", "", "reason recorded in the audit log")
+	flags.Parse(args[1:])
+
+	if *userID == "" || !repo.ValidRole(*role) {
+		fmt.Fprintln(os.Stderr, "-user and a valid -role are required")
+		os.Exit(2)
+	}
+
+	cnf := config.GetConfig()
+
+	DB, err := db.GetDbConnection(cnf.DB)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	defer db.CloseDB(DB)
+
+	if err := db.MigrateDB(DB, cnf.MigrationSource); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	change, err := repo.NewRoleRepository(DB.DB).SetUserRole(context.Background(), *userID, *role, repo.RoleChangedByCLI, *reason)
+	if errors.Is(err, repo.ErrRoleUnchanged) {
+		fmt.Printf("User %s already has role %s.\n", *userID, *role)
+		return
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("Changed role of %s from %s to %s.\n", change.Username, change.OldRole, change.NewRole)
+}
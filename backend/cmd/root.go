@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+)
+
+const usage = `Usage: gw2style [command]
+
+Commands:
+  serve                        Start the HTTP server and Discord bot (default)
+  jwt-keys generate [-kid ID]  Generate a new JWT signing key
+  jwt-keys list                List the configured JWT keys
+  service-keys generate [-kid ID]
+                               Generate a new key for signing bot requests
+  api-keys generate-key [-version V]
+                               Generate a new API key encryption key
+  api-keys encrypt [-batch N]  Encrypt plaintext API keys and re-encrypt old versions
+  gw2-fake [-addr ADDR]        Serve a fake GW2 API for offline development
+  catalog sync [-full] [-only KINDS]
+                               Copy new GW2 skins, dyes, outfits, gliders and mount skins
+  roles set -user ID -role ROLE [-reason R]
+                               Change a user's role (user, trusted_creator, moderator, admin)
+`
+
+// Execute runs the command named by the first argument
+func Execute() {
+	args := os.Args[1:]
+	if len(args) == 0 {
+		Serve()
+		return
+	}
+
+	switch args[0] {
+	case "serve":
+		Serve()
+	case "jwt-keys":
+		JWTKeys(args[1:])
+	case "service-keys":
+		ServiceKeys(args[1:])
+	case "api-keys":
+		APIKeys(args[1:])
+	case "gw2-fake":
+		GW2Fake(args[1:])
+	case "catalog":
+		Catalog(args[1:])
+	case "roles":
+		Roles(args[1:])
+	case "help", "-h", "--help":
+		fmt.Print(usage)
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n%s", args[0], usage)
+		os.Exit(2)
+	}
+}
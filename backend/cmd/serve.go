@@ -1,20 +1,26 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"log/slog"
 	"os"
 	"os/signal"
 	"syscall"
 
+	"github.com/NesoHQ/gw2style/apikey"
 	"github.com/NesoHQ/gw2style/bot"
 	"github.com/NesoHQ/gw2style/config"
 	"github.com/NesoHQ/gw2style/db"
+	"github.com/NesoHQ/gw2style/gw2api"
+	"github.com/NesoHQ/gw2style/jobs"
 	"github.com/NesoHQ/gw2style/logger"
 	"github.com/NesoHQ/gw2style/repo"
 	"github.com/NesoHQ/gw2style/rest"
 	"github.com/NesoHQ/gw2style/rest/handlers"
 	"github.com/NesoHQ/gw2style/rest/middlewares"
+	"github.com/NesoHQ/gw2style/rest/utils"
+	"github.com/NesoHQ/gw2style/serviceauth"
 )
 
 func Serve() {
@@ -40,10 +46,40 @@ func Serve() {
 		os.Exit(1)
 	}
 
-	userRepo := repo.NewUserRepo(DB)
+	jwtSigner, err := utils.NewJWTSigner(cnf)
+	if err != nil {
+		slog.Error("Failed to load JWT keys:", logger.Extra(map[string]any{
+			"error": err.Error(),
+		}))
+		fmt.Println(err)
+		os.Exit(1)
+	}
 
-	handlers := handlers.NewHandler(cnf, DB, userRepo)
-	middlewares := middlewares.NewMiddleware(cnf)
+	apiKeyCipher, err := apikey.NewCipher(cnf)
+	if err != nil {
+		slog.Error("Failed to load API key encryption keys:", logger.Extra(map[string]any{
+			"error": err.Error(),
+		}))
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	serviceVerifier, err := serviceauth.NewVerifier(cnf)
+	if err != nil {
+		slog.Error("Failed to load service auth keys:", logger.Extra(map[string]any{
+			"error": err.Error(),
+		}))
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	userRepo := repo.NewUserRepo(DB, apiKeyCipher)
+	sessionRepo := repo.NewSessionRepository(DB.DB)
+
+	gw2Client := gw2api.NewClient(cnf)
+
+	handlers := handlers.NewHandler(cnf, DB, userRepo, sessionRepo, jwtSigner, gw2Client)
+	middlewares := middlewares.NewMiddleware(cnf, jwtSigner, serviceVerifier, sessionRepo, repo.NewRoleRepository(DB.DB), repo.NewBanRepository(DB.DB))
 
 	server, err := rest.NewServer(middlewares, cnf, handlers)
 	if err != nil {
@@ -78,6 +114,11 @@ func Serve() {
 	// Start HTTP server
 	server.Start()
 
+	// Recheck stored GW2 API keys in the background
+	jobsCtx, stopJobs := context.WithCancel(context.Background())
+	defer stopJobs()
+	go jobs.NewAPIKeyRevalidator(cnf, userRepo, sessionRepo, gw2Client).Run(jobsCtx)
+
 	// Wait for interrupt signal to gracefully shutdown
 	slog.Info("Server and Discord bot are running. Press CTRL+C to exit.")
 	sc := make(chan os.Signal, 1)
@@ -0,0 +1,34 @@
+package cmd
+
+import (
+	"encoding/base64"
+	"fmt"
+	"os"
+
+	"github.com/NesoHQ/gw2style/config"
+)
+
+var serviceKeyList = keyList[[]config.ServiceKey]{
+	envVar:   "SERVICE_AUTH_KEYS",
+	idFlag:   "kid",
+	idPrefix: "s",
+	size:     32,
+	encoding: base64.RawURLEncoding,
+	parse:    config.ParseServiceKeys,
+}
+
+// ServiceKeys manages the keys the Discord bot signs admin API requests with.
+//
+// Rotating keys without rejecting bot requests:
+//  1. generate a key and append it to SERVICE_AUTH_KEYS, keep SERVICE_AUTH_KEY_ID unchanged
+//  2. once every instance accepts the new key, point SERVICE_AUTH_KEY_ID at it
+//  3. remove the old key from SERVICE_AUTH_KEYS
+func ServiceKeys(args []string) {
+	if len(args) == 0 || args[0] != "generate" {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	kid := serviceKeyList.generate("service-keys generate", args[1:])
+	fmt.Printf("Then set SERVICE_AUTH_KEY_ID=%s once every instance knows the key.\n", kid)
+}
@@ -0,0 +1,90 @@
+package config
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// EncryptionKeySize is the AES-256 key length in bytes
+const EncryptionKeySize = 32
+
+// EncryptionKey is one versioned AES key stored API keys can be encrypted with
+type EncryptionKey struct {
+	Version string
+	Key     []byte
+}
+
+// ParseEncryptionKeys parses an API_KEY_ENCRYPTION_KEYS value of the form
+// "v1:base64key,v2:base64key" where every key decodes to 32 bytes
+func ParseEncryptionKeys(value string) ([]EncryptionKey, error) {
+	var keys []EncryptionKey
+	seen := map[string]bool{}
+
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		version, encoded, ok := strings.Cut(entry, ":")
+		if !ok {
+			return nil, fmt.Errorf("API_KEY_ENCRYPTION_KEYS entries must be version:base64key")
+		}
+		if !jwtKeyIDPattern.MatchString(version) {
+			return nil, fmt.Errorf("encryption key version %q may only contain letters, digits, _ and -", version)
+		}
+		if seen[version] {
+			return nil, fmt.Errorf("encryption key version %q is listed twice", version)
+		}
+
+		key, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			key, err = base64.RawURLEncoding.DecodeString(encoded)
+		}
+		if err != nil || len(key) != EncryptionKeySize {
+			return nil, fmt.Errorf("encryption key %q must be %d bytes encoded as base64", version, EncryptionKeySize)
+		}
+
+		seen[version] = true
+		keys = append(keys, EncryptionKey{Version: version, Key: key})
+	}
+
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("API_KEY_ENCRYPTION_KEYS does not contain any key")
+	}
+
+	return keys, nil
+}
+
+// EncryptionKeys returns every configured API key encryption key
+func (c *Config) EncryptionKeys() ([]EncryptionKey, error) {
+	return ParseEncryptionKeys(c.ApiKeyEncryptionKeys)
+}
+
+// CurrentEncryptionKey returns the key new API keys are encrypted with: the one
+// named by API_KEY_ENCRYPTION_VERSION, or the first configured key.
+func (c *Config) CurrentEncryptionKey() (EncryptionKey, error) {
+	keys, err := c.EncryptionKeys()
+	if err != nil {
+		return EncryptionKey{}, err
+	}
+
+	if c.ApiKeyEncryptionVersion == "" {
+		return keys[0], nil
+	}
+	for _, key := range keys {
+		if key.Version == c.ApiKeyEncryptionVersion {
+			return key, nil
+		}
+	}
+
+	return EncryptionKey{}, fmt.Errorf("API_KEY_ENCRYPTION_VERSION %q is not listed in API_KEY_ENCRYPTION_KEYS", c.ApiKeyEncryptionVersion)
+}
+
+// ApiKeyRevalidationInterval is how long a stored API key is trusted before
+// the revalidation job checks it against the GW2 API again
+func (c *Config) ApiKeyRevalidationInterval() time.Duration {
+	return time.Duration(c.ApiKeyRevalidationHours) * time.Hour
+}
@@ -14,17 +14,37 @@ const (
 )
 
 type Config struct {
-	Version              string `mapstructure:"VERSION"                           validate:"required"`
-	Mode                 Mode   `mapstructure:"MODE"                              validate:"required"`
-	ServiceName          string `mapstructure:"SERVICE_NAME"                      validate:"required"`
-	HttpPort             int    `mapstructure:"HTTP_PORT"                         validate:"required"`
-	MigrationSource      string `mapstructure:"MIGRATION_SOURCE"                  validate:"required"`
-	JwtSecret            string `mapstructure:"JWT_SECRET"               validate:"required"`
-	DiscordBotToken      string `mapstructure:"DISCORD_BOT_TOKEN"        validate:"required"`
-	DiscordWebhookURL    string `mapstructure:"DISCORD_WEBHOOK_URL"      validate:"required"`
-	DiscordModChannel    string `mapstructure:"DISCORD_MOD_CHANNEL_ID"   validate:"required"`
-	DiscordPublicWebhook string `mapstructure:"DISCORD_PUBLIC_WEBHOOK_URL"`
-	DB                   DBConfig
+	Version                 string `mapstructure:"VERSION"                           validate:"required"`
+	Mode                    Mode   `mapstructure:"MODE"                              validate:"required"`
+	ServiceName             string `mapstructure:"SERVICE_NAME"                      validate:"required"`
+	HttpPort                int    `mapstructure:"HTTP_PORT"                         validate:"required"`
+	TrustProxyHeaders       bool   `mapstructure:"TRUST_PROXY_HEADERS"`
+	CorsAllowedOrigins      string `mapstructure:"CORS_ALLOWED_ORIGINS"`
+	MigrationSource         string `mapstructure:"MIGRATION_SOURCE"                  validate:"required"`
+	JwtSecret               string `mapstructure:"JWT_SECRET"               validate:"required_without=JwtKeys"`
+	JwtKeys                 string `mapstructure:"JWT_KEYS"                 validate:"required_without=JwtSecret"`
+	JwtSigningKeyID         string `mapstructure:"JWT_SIGNING_KEY_ID"`
+	JwtIssuer               string `mapstructure:"JWT_ISSUER"               validate:"required"`
+	JwtAudience             string `mapstructure:"JWT_AUDIENCE"             validate:"required"`
+	AccessTokenTTL          int    `mapstructure:"ACCESS_TOKEN_TTL_MINUTES" validate:"gte=1"`
+	RefreshTokenTTL         int    `mapstructure:"REFRESH_TOKEN_TTL_DAYS"   validate:"gte=1"`
+	ApiKeyEncryptionKeys    string `mapstructure:"API_KEY_ENCRYPTION_KEYS"    validate:"required"`
+	ApiKeyEncryptionVersion string `mapstructure:"API_KEY_ENCRYPTION_VERSION"`
+	ApiKeyHashSecret        string `mapstructure:"API_KEY_HASH_SECRET"        validate:"required,min=32"`
+	Gw2ApiBaseURL           string `mapstructure:"GW2_API_BASE_URL"           validate:"required,url"`
+	Gw2ApiTimeout           int    `mapstructure:"GW2_API_TIMEOUT_SECONDS"    validate:"gte=1"`
+	Gw2ApiMaxRetries        int    `mapstructure:"GW2_API_MAX_RETRIES"        validate:"gte=0"`
+	ApiKeyRevalidationHours int    `mapstructure:"API_KEY_REVALIDATION_HOURS" validate:"gte=1"`
+	ApiKeyRevalidationBatch int    `mapstructure:"API_KEY_REVALIDATION_BATCH" validate:"gte=1"`
+	ServiceAuthKeys         string `mapstructure:"SERVICE_AUTH_KEYS"        validate:"required"`
+	ServiceAuthKeyID        string `mapstructure:"SERVICE_AUTH_KEY_ID"`
+	DiscordBotToken         string `mapstructure:"DISCORD_BOT_TOKEN"        validate:"required"`
+	DiscordWebhookURL       string `mapstructure:"DISCORD_WEBHOOK_URL"      validate:"required"`
+	DiscordModChannel       string `mapstructure:"DISCORD_MOD_CHANNEL_ID"   validate:"required"`
+	DiscordPublicWebhook    string `mapstructure:"DISCORD_PUBLIC_WEBHOOK_URL"`
+	ReportDailyLimit        int    `mapstructure:"REPORT_DAILY_LIMIT"       validate:"gte=1"`
+	ReportHideThreshold     int    `mapstructure:"REPORT_HIDE_THRESHOLD"    validate:"gte=1"`
+	DB                      DBConfig
 }
 
 var config *Config
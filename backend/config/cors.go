@@ -0,0 +1,40 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// ParseCorsOrigins parses a CORS_ALLOWED_ORIGINS value of the form
+// "https://gw2style.com,http://localhost:3000". Wildcards are rejected because
+// the API allows credentials, so any listed origin can act as a logged in user.
+func ParseCorsOrigins(value string) ([]string, error) {
+	var origins []string
+
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSuffix(strings.TrimSpace(entry), "/")
+		if entry == "" {
+			continue
+		}
+		if strings.Contains(entry, "*") {
+			return nil, fmt.Errorf("CORS_ALLOWED_ORIGINS does not accept wildcards, list each origin")
+		}
+
+		u, err := url.Parse(entry)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || u.Path != "" || u.RawQuery != "" {
+			return nil, fmt.Errorf("CORS_ALLOWED_ORIGINS entry %q must be a scheme and host, like https://gw2style.com", entry)
+		}
+
+		origins = append(origins, strings.ToLower(entry))
+	}
+
+	return origins, nil
+}
+
+// CorsOrigins returns the origins browsers may call the API from with
+// credentials. EnableCors allows no cross-origin request when the list is
+// empty, so only a frontend served from the API's own origin works.
+func (c *Config) CorsOrigins() ([]string, error) {
+	return ParseCorsOrigins(c.CorsAllowedOrigins)
+}
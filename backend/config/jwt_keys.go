@@ -0,0 +1,105 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// MinJWTSecretLength is the shortest secret accepted for HMAC signing
+const MinJWTSecretLength = 32
+
+// DefaultJWTKeyID is the key ID given to JWT_SECRET when JWT_KEYS is not set
+const DefaultJWTKeyID = "default"
+
+var jwtKeyIDPattern = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// JWTKey is one HMAC key tokens can be signed and verified with
+type JWTKey struct {
+	ID     string
+	Secret []byte
+}
+
+// ParseJWTKeys parses a JWT_KEYS value of the form "kid1:secret1,kid2:secret2"
+func ParseJWTKeys(value string) ([]JWTKey, error) {
+	var keys []JWTKey
+	seen := map[string]bool{}
+
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		id, secret, ok := strings.Cut(entry, ":")
+		if !ok {
+			return nil, fmt.Errorf("JWT_KEYS entries must be kid:secret")
+		}
+		if !jwtKeyIDPattern.MatchString(id) {
+			return nil, fmt.Errorf("JWT key ID %q may only contain letters, digits, _ and -", id)
+		}
+		if seen[id] {
+			return nil, fmt.Errorf("JWT key ID %q is listed twice", id)
+		}
+		if len(secret) < MinJWTSecretLength {
+			return nil, fmt.Errorf("JWT key %q must be at least %d characters", id, MinJWTSecretLength)
+		}
+
+		seen[id] = true
+		keys = append(keys, JWTKey{ID: id, Secret: []byte(secret)})
+	}
+
+	return keys, nil
+}
+
+// AccessTokenLifetime is how long an access token stays valid
+func (c *Config) AccessTokenLifetime() time.Duration {
+	return time.Duration(c.AccessTokenTTL) * time.Minute
+}
+
+// RefreshTokenLifetime is how long a session lasts without being refreshed
+func (c *Config) RefreshTokenLifetime() time.Duration {
+	return time.Duration(c.RefreshTokenTTL) * 24 * time.Hour
+}
+
+// JWTKeys returns every configured verification key. JWT_KEYS takes precedence;
+// otherwise JWT_SECRET is used as the single key with ID DefaultJWTKeyID.
+func (c *Config) JWTKeys() ([]JWTKey, error) {
+	if c.JwtKeys == "" {
+		if len(c.JwtSecret) < MinJWTSecretLength {
+			return nil, fmt.Errorf("JWT_SECRET must be at least %d characters", MinJWTSecretLength)
+		}
+		return []JWTKey{{ID: DefaultJWTKeyID, Secret: []byte(c.JwtSecret)}}, nil
+	}
+
+	keys, err := ParseJWTKeys(c.JwtKeys)
+	if err != nil {
+		return nil, err
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("JWT_KEYS does not contain any key")
+	}
+
+	return keys, nil
+}
+
+// JWTSigningKey returns the key new tokens are signed with: the one named by
+// JWT_SIGNING_KEY_ID, or the first configured key.
+func (c *Config) JWTSigningKey() (JWTKey, error) {
+	keys, err := c.JWTKeys()
+	if err != nil {
+		return JWTKey{}, err
+	}
+
+	if c.JwtSigningKeyID == "" {
+		return keys[0], nil
+	}
+	for _, key := range keys {
+		if key.ID == c.JwtSigningKeyID {
+			return key, nil
+		}
+	}
+
+	return JWTKey{}, fmt.Errorf("JWT_SIGNING_KEY_ID %q is not listed in JWT_KEYS", c.JwtSigningKeyID)
+}
@@ -22,17 +22,49 @@ func loadConfig() error {
 
 	viper.AutomaticEnv()
 
+	viper.SetDefault("REPORT_DAILY_LIMIT", 10)
+	viper.SetDefault("REPORT_HIDE_THRESHOLD", 5)
+	viper.SetDefault("JWT_ISSUER", "gw2style")
+	viper.SetDefault("JWT_AUDIENCE", "gw2style-web")
+	viper.SetDefault("ACCESS_TOKEN_TTL_MINUTES", 15)
+	viper.SetDefault("REFRESH_TOKEN_TTL_DAYS", 30)
+	viper.SetDefault("GW2_API_BASE_URL", "https://api.guildwars2.com")
+	viper.SetDefault("GW2_API_TIMEOUT_SECONDS", 10)
+	viper.SetDefault("GW2_API_MAX_RETRIES", 2)
+	viper.SetDefault("API_KEY_REVALIDATION_HOURS", 24)
+	viper.SetDefault("API_KEY_REVALIDATION_BATCH", 100)
+
 	config = &Config{
-		Version:              viper.GetString("VERSION"),
-		Mode:                 Mode(viper.GetString("MODE")),
-		ServiceName:          viper.GetString("SERVICE_NAME"),
-		HttpPort:             viper.GetInt("HTTP_PORT"),
-		MigrationSource:      viper.GetString("MIGRATION_SOURCE"),
-		JwtSecret:            viper.GetString("JWT_SECRET"),
-		DiscordBotToken:      viper.GetString("DISCORD_BOT_TOKEN"),
-		DiscordWebhookURL:    viper.GetString("DISCORD_WEBHOOK_URL"),
-		DiscordModChannel:    viper.GetString("DISCORD_MOD_CHANNEL_ID"),
-		DiscordPublicWebhook: viper.GetString("DISCORD_PUBLIC_WEBHOOK_URL"),
+		Version:                 viper.GetString("VERSION"),
+		Mode:                    Mode(viper.GetString("MODE")),
+		ServiceName:             viper.GetString("SERVICE_NAME"),
+		HttpPort:                viper.GetInt("HTTP_PORT"),
+		TrustProxyHeaders:       viper.GetBool("TRUST_PROXY_HEADERS"),
+		CorsAllowedOrigins:      viper.GetString("CORS_ALLOWED_ORIGINS"),
+		MigrationSource:         viper.GetString("MIGRATION_SOURCE"),
+		JwtSecret:               viper.GetString("JWT_SECRET"),
+		JwtKeys:                 viper.GetString("JWT_KEYS"),
+		JwtSigningKeyID:         viper.GetString("JWT_SIGNING_KEY_ID"),
+		JwtIssuer:               viper.GetString("JWT_ISSUER"),
+		JwtAudience:             viper.GetString("JWT_AUDIENCE"),
+		AccessTokenTTL:          viper.GetInt("ACCESS_TOKEN_TTL_MINUTES"),
+		RefreshTokenTTL:         viper.GetInt("REFRESH_TOKEN_TTL_DAYS"),
+		ApiKeyEncryptionKeys:    viper.GetString("API_KEY_ENCRYPTION_KEYS"),
+		ApiKeyEncryptionVersion: viper.GetString("API_KEY_ENCRYPTION_VERSION"),
+		ApiKeyHashSecret:        viper.GetString("API_KEY_HASH_SECRET"),
+		Gw2ApiBaseURL:           viper.GetString("GW2_API_BASE_URL"),
+		Gw2ApiTimeout:           viper.GetInt("GW2_API_TIMEOUT_SECONDS"),
+		Gw2ApiMaxRetries:        viper.GetInt("GW2_API_MAX_RETRIES"),
+		ApiKeyRevalidationHours: viper.GetInt("API_KEY_REVALIDATION_HOURS"),
+		ApiKeyRevalidationBatch: viper.GetInt("API_KEY_REVALIDATION_BATCH"),
+		ServiceAuthKeys:         viper.GetString("SERVICE_AUTH_KEYS"),
+		ServiceAuthKeyID:        viper.GetString("SERVICE_AUTH_KEY_ID"),
+		DiscordBotToken:         viper.GetString("DISCORD_BOT_TOKEN"),
+		DiscordWebhookURL:       viper.GetString("DISCORD_WEBHOOK_URL"),
+		DiscordModChannel:       viper.GetString("DISCORD_MOD_CHANNEL_ID"),
+		DiscordPublicWebhook:    viper.GetString("DISCORD_PUBLIC_WEBHOOK_URL"),
+		ReportDailyLimit:        viper.GetInt("REPORT_DAILY_LIMIT"),
+		ReportHideThreshold:     viper.GetInt("REPORT_HIDE_THRESHOLD"),
 		DB: &DB{
 			DbHost:                 viper.GetString("DB_HOST"),
 			DbPort:                 viper.GetInt("DB_PORT"),
@@ -51,5 +83,17 @@ func loadConfig() error {
 		exit(err)
 	}
 
+	if _, err = config.JWTSigningKey(); err != nil {
+		exit(err)
+	}
+
+	if _, err = config.CurrentEncryptionKey(); err != nil {
+		exit(err)
+	}
+
+	if _, err = config.CorsOrigins(); err != nil {
+		exit(err)
+	}
+
 	return nil
 }
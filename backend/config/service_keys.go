@@ -0,0 +1,80 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// MinServiceKeySecretLength is the shortest secret accepted for service request signing
+const MinServiceKeySecretLength = 32
+
+// ServiceKey is one HMAC key internal services (the Discord bot) sign API requests with
+type ServiceKey struct {
+	ID     string
+	Secret []byte
+}
+
+// ParseServiceKeys parses a SERVICE_AUTH_KEYS value of the form "kid1:secret1,kid2:secret2"
+func ParseServiceKeys(value string) ([]ServiceKey, error) {
+	var keys []ServiceKey
+	seen := map[string]bool{}
+
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		id, secret, ok := strings.Cut(entry, ":")
+		if !ok {
+			return nil, fmt.Errorf("SERVICE_AUTH_KEYS entries must be kid:secret")
+		}
+		if !jwtKeyIDPattern.MatchString(id) {
+			return nil, fmt.Errorf("service key ID %q may only contain letters, digits, _ and -", id)
+		}
+		if seen[id] {
+			return nil, fmt.Errorf("service key ID %q is listed twice", id)
+		}
+		if len(secret) < MinServiceKeySecretLength {
+			return nil, fmt.Errorf("service key %q must be at least %d characters", id, MinServiceKeySecretLength)
+		}
+
+		seen[id] = true
+		keys = append(keys, ServiceKey{ID: id, Secret: []byte(secret)})
+	}
+
+	return keys, nil
+}
+
+// ServiceKeys returns every key the API accepts signed service requests with
+func (c *Config) ServiceKeys() ([]ServiceKey, error) {
+	keys, err := ParseServiceKeys(c.ServiceAuthKeys)
+	if err != nil {
+		return nil, err
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("SERVICE_AUTH_KEYS does not contain any key")
+	}
+
+	return keys, nil
+}
+
+// ServiceSigningKey returns the key the bot signs requests with: the one named
+// by SERVICE_AUTH_KEY_ID, or the first configured key.
+func (c *Config) ServiceSigningKey() (ServiceKey, error) {
+	keys, err := c.ServiceKeys()
+	if err != nil {
+		return ServiceKey{}, err
+	}
+
+	if c.ServiceAuthKeyID == "" {
+		return keys[0], nil
+	}
+	for _, key := range keys {
+		if key.ID == c.ServiceAuthKeyID {
+			return key, nil
+		}
+	}
+
+	return ServiceKey{}, fmt.Errorf("SERVICE_AUTH_KEY_ID %q is not listed in SERVICE_AUTH_KEYS", c.ServiceAuthKeyID)
+}
@@ -0,0 +1,23 @@
+-- +migrate Up
+CREATE TABLE IF NOT EXISTS
+    post_revisions (
+        id SERIAL PRIMARY KEY,
+        post_id INTEGER NOT NULL REFERENCES posts(id) ON DELETE CASCADE,
+        revision INTEGER NOT NULL,
+        title VARCHAR(250) NOT NULL,
+        description TEXT,
+        thumbnail_url TEXT,
+        image1_url TEXT,
+        image2_url TEXT,
+        image3_url TEXT,
+        image4_url TEXT,
+        image5_url TEXT,
+        equipments JSON,
+        tags JSONB DEFAULT '[]'::jsonb,
+        edited_by VARCHAR(255) NOT NULL,
+        created_at TIMESTAMPTZ DEFAULT NOW(),
+        CONSTRAINT uq_post_revisions_post_revision UNIQUE (post_id, revision)
+    );
+
+-- Create index for listing a post's history
+CREATE INDEX IF NOT EXISTS idx_post_revisions_post_id ON post_revisions(post_id, revision DESC);
@@ -0,0 +1,33 @@
+-- +migrate Up
+CREATE TABLE IF NOT EXISTS
+    post_likes (
+        user_id VARCHAR NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+        post_id INTEGER NOT NULL REFERENCES posts(id) ON DELETE CASCADE,
+        created_at TIMESTAMPTZ DEFAULT NOW(),
+        CONSTRAINT uq_post_likes_user_post UNIQUE (user_id, post_id)
+    );
+
+-- Create indexes for "who liked this post" and "what did this user like" lookups
+CREATE INDEX IF NOT EXISTS idx_post_likes_post_id ON post_likes(post_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_post_likes_user_id ON post_likes(user_id, created_at DESC);
+
+-- Backfill from the users.liked_posts JSON arrays, skipping ids of posts that no longer exist
+INSERT INTO post_likes (user_id, post_id)
+SELECT DISTINCT liked.user_id, liked.post_id
+FROM (
+    SELECT
+        u.id AS user_id,
+        CASE WHEN elem.value ~ '^[0-9]{1,9}$' THEN elem.value::INTEGER END AS post_id
+    FROM users u
+    CROSS JOIN LATERAL json_array_elements_text(
+        CASE WHEN json_typeof(u.liked_posts) = 'array' THEN u.liked_posts ELSE '[]'::json END
+    ) AS elem(value)
+) liked
+JOIN posts p ON p.id = liked.post_id
+ON CONFLICT (user_id, post_id) DO NOTHING;
+
+-- Resync the denormalized counter with the new source of truth
+UPDATE posts
+SET likes_count = (SELECT COUNT(*) FROM post_likes pl WHERE pl.post_id = posts.id);
+
+ALTER TABLE users DROP COLUMN IF EXISTS liked_posts;
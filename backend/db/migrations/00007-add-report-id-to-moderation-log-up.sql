@@ -0,0 +1,6 @@
+-- +migrate Up
+ALTER TABLE moderation_log
+    ADD COLUMN IF NOT EXISTS report_id INTEGER REFERENCES reports(id) ON DELETE SET NULL;
+
+-- Create index for looking up the actions taken on a report
+CREATE INDEX IF NOT EXISTS idx_moderation_log_report_id ON moderation_log(report_id) WHERE report_id IS NOT NULL;
@@ -0,0 +1,12 @@
+-- +migrate Up
+-- Keep only the first report each user filed against a post
+DELETE FROM reports r
+USING reports earlier
+WHERE r.post_id = earlier.post_id
+  AND r.reporter_username = earlier.reporter_username
+  AND r.id > earlier.id;
+
+CREATE UNIQUE INDEX IF NOT EXISTS uq_reports_post_reporter ON reports(post_id, reporter_username);
+
+-- Create index for the per-user daily report cap
+CREATE INDEX IF NOT EXISTS idx_reports_reporter_created_at ON reports(reporter_username, created_at DESC);
@@ -0,0 +1,39 @@
+-- +migrate Up
+ALTER TABLE posts
+    ADD COLUMN IF NOT EXISTS status VARCHAR(20) NOT NULL DEFAULT 'pending'
+    CHECK (status IN ('draft', 'pending', 'published', 'rejected', 'hidden', 'deleted'));
+
+-- Derive the status of every post from the old boolean and its latest moderation action.
+-- An unpublished post edited after its last action was sent back to moderation by the edit.
+-- Otherwise an unpublished post whose last action was "published" can only have been deleted by its author.
+UPDATE posts p
+SET status = CASE
+    WHEN p.published THEN 'published'
+    WHEN last_revision.created_at > last_action.created_at THEN 'pending'
+    WHEN last_action.action IN ('rejected', 'unpublished') THEN 'rejected'
+    WHEN last_action.action = 'auto_hidden' THEN 'hidden'
+    WHEN last_action.action = 'published' THEN 'deleted'
+    ELSE 'pending'
+END
+FROM posts p2
+LEFT JOIN LATERAL (
+    SELECT ml.action, ml.created_at
+    FROM moderation_log ml
+    WHERE ml.post_id = p2.id
+      AND ml.action IN ('published', 'rejected', 'unpublished', 'auto_hidden')
+    ORDER BY ml.created_at DESC, ml.id DESC
+    LIMIT 1
+) last_action ON true
+LEFT JOIN LATERAL (
+    SELECT MAX(pr.created_at) AS created_at
+    FROM post_revisions pr
+    WHERE pr.post_id = p2.id
+) last_revision ON true
+WHERE p.id = p2.id;
+
+DROP INDEX IF EXISTS idx_posts_published_tags;
+ALTER TABLE posts DROP COLUMN IF EXISTS published;
+
+-- Create indexes for the status based listings
+CREATE INDEX IF NOT EXISTS idx_posts_status ON posts(status);
+CREATE INDEX IF NOT EXISTS idx_posts_published_tags ON posts USING GIN (tags) WHERE status = 'published';
@@ -0,0 +1,36 @@
+-- +migrate Up
+ALTER TABLE posts ADD COLUMN IF NOT EXISTS search_vector tsvector;
+
+-- Builds the full-text document of a post. Weights rank title matches above
+-- tags, tags above equipment strings and equipment above the description.
+-- Equipment documents only hold IDs and slot names; 00019 indexes the skin
+-- names they resolve to instead.
+-- +migrate StatementBegin
+CREATE OR REPLACE FUNCTION post_search_document(title TEXT, description TEXT, tags JSONB, equipments JSON)
+RETURNS tsvector AS $$
+    SELECT
+        setweight(to_tsvector('english', COALESCE(title, '')), 'A') ||
+        setweight(jsonb_to_tsvector('english', COALESCE(tags, '[]'::jsonb), '["string"]'), 'B') ||
+        setweight(jsonb_to_tsvector('english', COALESCE(equipments::jsonb, '{}'::jsonb), '["string"]'), 'C') ||
+        setweight(to_tsvector('english', COALESCE(description, '')), 'D')
+$$ LANGUAGE SQL IMMUTABLE;
+-- +migrate StatementEnd
+
+-- +migrate StatementBegin
+CREATE OR REPLACE FUNCTION posts_search_vector_update() RETURNS trigger AS $$
+BEGIN
+    NEW.search_vector := post_search_document(NEW.title, NEW.description, NEW.tags, NEW.equipments);
+    RETURN NEW;
+END
+$$ LANGUAGE plpgsql;
+-- +migrate StatementEnd
+
+DROP TRIGGER IF EXISTS trg_posts_search_vector ON posts;
+CREATE TRIGGER trg_posts_search_vector
+    BEFORE INSERT OR UPDATE OF title, description, tags, equipments ON posts
+    FOR EACH ROW EXECUTE FUNCTION posts_search_vector_update();
+
+-- Backfill existing posts
+UPDATE posts SET search_vector = post_search_document(title, description, tags, equipments);
+
+CREATE INDEX IF NOT EXISTS idx_posts_search_vector ON posts USING GIN (search_vector);
@@ -0,0 +1,54 @@
+-- +migrate Up
+CREATE TABLE IF NOT EXISTS
+    tags (
+        id SERIAL PRIMARY KEY,
+        slug VARCHAR(100) NOT NULL UNIQUE,
+        display_name VARCHAR(100) NOT NULL UNIQUE,
+        category VARCHAR(30) NOT NULL CHECK (category IN ('race', 'gender', 'armor_weight', 'class', 'dye_color', 'source', 'armor_skin')),
+        aliases TEXT[] NOT NULL DEFAULT '{}', -- Alternative spellings, stored as slugs
+        created_at TIMESTAMPTZ DEFAULT now()
+    );
+
+CREATE INDEX IF NOT EXISTS idx_tags_category ON tags(category);
+CREATE INDEX IF NOT EXISTS idx_tags_aliases ON tags USING GIN (aliases);
+
+-- Seed the vocabulary from db/queries/post/tags-reference.sql
+INSERT INTO tags (slug, display_name, category, aliases) VALUES
+    ('human', 'Human', 'race', '{}'),
+    ('asura', 'Asura', 'race', '{}'),
+    ('norn', 'Norn', 'race', '{}'),
+    ('charr', 'Charr', 'race', '{}'),
+    ('sylvari', 'Sylvari', 'race', '{}'),
+    ('male', 'Male', 'gender', '{}'),
+    ('female', 'Female', 'gender', '{}'),
+    ('light', 'Light', 'armor_weight', '{light-armor}'),
+    ('medium', 'Medium', 'armor_weight', '{medium-armor}'),
+    ('heavy', 'Heavy', 'armor_weight', '{heavy-armor}'),
+    ('guardian', 'Guardian', 'class', '{}'),
+    ('warrior', 'Warrior', 'class', '{}'),
+    ('engineer', 'Engineer', 'class', '{}'),
+    ('ranger', 'Ranger', 'class', '{}'),
+    ('thief', 'Thief', 'class', '{}'),
+    ('elementalist', 'Elementalist', 'class', '{ele}'),
+    ('mesmer', 'Mesmer', 'class', '{}'),
+    ('necromancer', 'Necromancer', 'class', '{necro}'),
+    ('revenant', 'Revenant', 'class', '{rev}'),
+    ('gray-dyes', 'Gray dyes', 'dye_color', '{grey-dyes,gray,grey}'),
+    ('brown-dyes', 'Brown dyes', 'dye_color', '{brown}'),
+    ('red-dyes', 'Red dyes', 'dye_color', '{red}'),
+    ('orange-dyes', 'Orange dyes', 'dye_color', '{orange}'),
+    ('yellow-dyes', 'Yellow dyes', 'dye_color', '{yellow}'),
+    ('green-dyes', 'Green dyes', 'dye_color', '{green}'),
+    ('blue-dyes', 'Blue dyes', 'dye_color', '{blue}'),
+    ('purple-dyes', 'Purple dyes', 'dye_color', '{purple}'),
+    ('lunar-new-year', 'Lunar New Year', 'source', '{}'),
+    ('super-adventure-box', 'Super Adventure Box', 'source', '{sab}'),
+    ('dragon-bash', 'Dragon Bash', 'source', '{}'),
+    ('four-winds', 'Four Winds', 'source', '{}'),
+    ('halloween', 'Halloween', 'source', '{}'),
+    ('loot', 'Loot', 'source', '{}'),
+    ('gems-store', 'Gems Store', 'source', '{gem-store,gemstore}'),
+    ('trading-post', 'Trading Post', 'source', '{tp}'),
+    ('carapace-armor', 'Carapace Armor', 'armor_skin', '{}'),
+    ('bladed-armor', 'Bladed Armor', 'armor_skin', '{}')
+ON CONFLICT (slug) DO NOTHING;
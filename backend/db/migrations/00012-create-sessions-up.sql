@@ -0,0 +1,29 @@
+-- +migrate Up
+CREATE TABLE IF NOT EXISTS
+    sessions (
+        id UUID PRIMARY KEY,
+        user_id VARCHAR NOT NULL,
+        user_agent TEXT NOT NULL DEFAULT '',
+        ip_address VARCHAR(64) NOT NULL DEFAULT '',
+        created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+        last_used_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+        expires_at TIMESTAMPTZ NOT NULL,
+        revoked_at TIMESTAMPTZ,
+        revoked_reason VARCHAR(50),
+        CONSTRAINT fk_session_user FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+    );
+
+CREATE INDEX IF NOT EXISTS idx_sessions_user_active ON sessions(user_id) WHERE revoked_at IS NULL;
+
+-- Every refresh token ever issued for a session. Only the newest one is unused;
+-- presenting a used one again means it was stolen and revokes the session.
+CREATE TABLE IF NOT EXISTS
+    refresh_tokens (
+        token_hash CHAR(64) PRIMARY KEY, -- hex SHA-256 of the token
+        session_id UUID NOT NULL,
+        created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+        used_at TIMESTAMPTZ,
+        CONSTRAINT fk_refresh_token_session FOREIGN KEY (session_id) REFERENCES sessions (id) ON DELETE CASCADE
+    );
+
+CREATE INDEX IF NOT EXISTS idx_refresh_tokens_session_id ON refresh_tokens(session_id);
@@ -0,0 +1,15 @@
+-- +migrate Up
+-- API keys are stored encrypted (api_key_encrypted, "version:ciphertext") and
+-- found at login through a keyed hash. The plaintext column is kept nullable
+-- until `gw2style api-keys encrypt` has moved every existing row over.
+ALTER TABLE users ALTER COLUMN api_key DROP NOT NULL;
+
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS api_key_encrypted TEXT,
+    ADD COLUMN IF NOT EXISTS api_key_hash CHAR(64), -- hex HMAC-SHA256 of the key
+    ADD COLUMN IF NOT EXISTS api_key_version VARCHAR(32),
+    ADD COLUMN IF NOT EXISTS api_key_permissions TEXT[] NOT NULL DEFAULT '{}',
+    ADD COLUMN IF NOT EXISTS api_key_validated_at TIMESTAMPTZ;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_api_key_hash ON users(api_key_hash);
+CREATE INDEX IF NOT EXISTS idx_users_api_key_version ON users(api_key_version);
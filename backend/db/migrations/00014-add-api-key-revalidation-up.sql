@@ -0,0 +1,12 @@
+-- +migrate Up
+-- Stored keys are rechecked against the GW2 API by the revalidation job.
+-- api_key_validated_at is the last successful check; a revoked key gets
+-- api_key_invalidated_at and its user has to log in again.
+ALTER TABLE users ADD COLUMN IF NOT EXISTS api_key_invalidated_at TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS idx_users_api_key_validated_at ON users(api_key_validated_at) WHERE api_key_invalidated_at IS NULL;
+
+-- Account renames are applied to users.username; carry them over to the posts
+ALTER TABLE posts DROP CONSTRAINT IF EXISTS fk_author;
+ALTER TABLE posts
+    ADD CONSTRAINT fk_author FOREIGN KEY (author_name) REFERENCES users (username) ON DELETE SET NULL ON UPDATE CASCADE;
@@ -0,0 +1,23 @@
+-- +migrate Up
+-- Roles rank user < trusted_creator < moderator < admin; a role grants
+-- everything the roles below it can do.
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS role VARCHAR(20) NOT NULL DEFAULT 'user'
+        CHECK (role IN ('user', 'trusted_creator', 'moderator', 'admin'));
+
+CREATE INDEX IF NOT EXISTS idx_users_role ON users(role) WHERE role <> 'user';
+
+-- Every role change, who made it and why
+CREATE TABLE IF NOT EXISTS
+    role_audit_log (
+        id SERIAL PRIMARY KEY,
+        user_id VARCHAR NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+        old_role VARCHAR(20) NOT NULL,
+        new_role VARCHAR(20) NOT NULL,
+        changed_by VARCHAR(255) NOT NULL, -- admin username, or "cli"
+        reason TEXT,
+        created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+    );
+
+CREATE INDEX IF NOT EXISTS idx_role_audit_log_user_id ON role_audit_log(user_id);
+CREATE INDEX IF NOT EXISTS idx_role_audit_log_created_at ON role_audit_log(created_at DESC);
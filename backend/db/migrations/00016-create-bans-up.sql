@@ -0,0 +1,35 @@
+-- +migrate Up
+-- Bans are keyed on the GW2 account ID, not on users.id, so they outlive the
+-- users row and also cover accounts that never logged in. expires_at is NULL
+-- for permanent bans.
+CREATE TABLE IF NOT EXISTS
+    bans (
+        id SERIAL PRIMARY KEY,
+        account_id VARCHAR NOT NULL,
+        reason TEXT NOT NULL,
+        expires_at TIMESTAMPTZ,
+        moderator_username VARCHAR(255) NOT NULL,
+        moderator_discord_id VARCHAR(255),
+        created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+        lifted_at TIMESTAMPTZ,
+        lifted_by VARCHAR(255)
+    );
+
+-- At most one ban per account is not lifted; an expired one is replaced on the next ban
+CREATE UNIQUE INDEX IF NOT EXISTS uq_bans_account_open ON bans(account_id) WHERE lifted_at IS NULL;
+
+-- Ban actions, kept next to moderation_log which only covers posts
+CREATE TABLE IF NOT EXISTS
+    ban_log (
+        id SERIAL PRIMARY KEY,
+        ban_id INTEGER NOT NULL REFERENCES bans(id) ON DELETE CASCADE,
+        account_id VARCHAR NOT NULL,
+        action VARCHAR(50) NOT NULL, -- banned, lifted
+        moderator_username VARCHAR(255) NOT NULL,
+        moderator_discord_id VARCHAR(255),
+        reason TEXT,
+        created_at TIMESTAMPTZ DEFAULT NOW()
+    );
+
+CREATE INDEX IF NOT EXISTS idx_ban_log_account_id ON ban_log(account_id);
+CREATE INDEX IF NOT EXISTS idx_ban_log_created_at ON ban_log(created_at DESC);
@@ -0,0 +1,71 @@
+-- +migrate Up
+-- Equipment used to be stored as whatever the client sent, in practice a GW2
+-- equipment tab (/v2/characters/:id/equipmenttabs). It is now the typed,
+-- versioned document of the equipment package. Posts whose equipment was
+-- changed keep the original in equipments_legacy.
+ALTER TABLE posts ADD COLUMN IF NOT EXISTS equipments_legacy JSON;
+
+-- Maps a GW2 equipment tab to a version 1 document: known slots only, with
+-- their item, skin, up to four dyes, upgrades and infusions. Documents that
+-- already have a version are returned as they are, anything else as NULL.
+-- +migrate StatementBegin
+CREATE OR REPLACE FUNCTION upgrade_equipment(doc JSONB) RETURNS JSONB AS $$
+    SELECT CASE
+        WHEN jsonb_typeof(doc) IS DISTINCT FROM 'object' THEN NULL
+        WHEN doc ? 'version' THEN doc
+        WHEN jsonb_typeof(doc->'equipment') IS DISTINCT FROM 'array' THEN NULL
+        ELSE jsonb_strip_nulls(jsonb_build_object(
+            'version', 1,
+            'name', NULLIF(doc->>'name', ''),
+            'equipment', COALESCE((
+                SELECT jsonb_agg(jsonb_strip_nulls(jsonb_build_object(
+                    'slot', piece->>'slot',
+                    'id', CASE WHEN jsonb_typeof(piece->'id') = 'number' THEN piece->'id' END,
+                    'skin', CASE WHEN jsonb_typeof(piece->'skin') = 'number' THEN piece->'skin' END,
+                    'dyes', CASE WHEN jsonb_typeof(piece->'dyes') = 'array' THEN (
+                        SELECT jsonb_agg(CASE WHEN jsonb_typeof(dye) = 'number' THEN dye ELSE 'null'::jsonb END ORDER BY channel)
+                        FROM jsonb_array_elements(piece->'dyes') WITH ORDINALITY AS dyes(dye, channel)
+                        WHERE channel <= 4
+                    ) END,
+                    'upgrades', CASE WHEN jsonb_typeof(piece->'upgrades') = 'array' THEN (
+                        SELECT jsonb_agg(upgrade) FROM jsonb_array_elements(piece->'upgrades') AS upgrades(upgrade)
+                        WHERE jsonb_typeof(upgrade) = 'number'
+                    ) END,
+                    'infusions', CASE WHEN jsonb_typeof(piece->'infusions') = 'array' THEN (
+                        SELECT jsonb_agg(infusion) FROM jsonb_array_elements(piece->'infusions') AS infusions(infusion)
+                        WHERE jsonb_typeof(infusion) = 'number'
+                    ) END
+                )) ORDER BY ord)
+                FROM jsonb_array_elements(doc->'equipment') WITH ORDINALITY AS pieces(piece, ord)
+                WHERE jsonb_typeof(piece) = 'object'
+                    AND piece->>'slot' IN (
+                        'Helm', 'Shoulders', 'Coat', 'Gloves', 'Leggings', 'Boots', 'HelmAquatic',
+                        'WeaponA1', 'WeaponA2', 'WeaponB1', 'WeaponB2', 'WeaponAquaticA', 'WeaponAquaticB',
+                        'Backpack', 'Amulet', 'Accessory1', 'Accessory2', 'Ring1', 'Ring2'
+                    )
+            ), '[]'::jsonb)
+        ))
+    END
+$$ LANGUAGE SQL IMMUTABLE;
+-- +migrate StatementEnd
+
+UPDATE posts
+SET equipments_legacy = equipments,
+    equipments = upgrade_equipment(equipments::jsonb)::json
+WHERE equipments IS NOT NULL
+    AND upgrade_equipment(equipments::jsonb) IS DISTINCT FROM equipments::jsonb;
+
+-- An upgraded tab without a single known slot shows nothing
+UPDATE posts SET equipments = NULL
+WHERE equipments IS NOT NULL
+    AND jsonb_typeof(equipments::jsonb) = 'object'
+    AND equipments::jsonb->'equipment' = '[]'::jsonb
+    AND NOT (equipments::jsonb ?| ARRAY['outfit', 'glider', 'mount']);
+
+-- Revisions are history, so ones that cannot be upgraded stay as recorded
+UPDATE post_revisions
+SET equipments = upgrade_equipment(equipments::jsonb)::json
+WHERE equipments IS NOT NULL
+    AND upgrade_equipment(equipments::jsonb) IS NOT NULL;
+
+DROP FUNCTION upgrade_equipment(JSONB);
@@ -0,0 +1,62 @@
+-- +migrate Up
+-- Local copy of the GW2 cosmetics, filled by `gw2style catalog sync`. IDs are
+-- GW2's own. synced_at is when a row was last fetched from the GW2 API.
+CREATE TABLE IF NOT EXISTS
+    catalog_skins (
+        id INTEGER PRIMARY KEY,
+        name TEXT NOT NULL DEFAULT '',
+        type VARCHAR(32) NOT NULL, -- Armor, Weapon, Back or Gathering
+        subtype VARCHAR(32), -- Armor slot or weapon type
+        weight_class VARCHAR(16), -- Armor only
+        rarity VARCHAR(16),
+        icon TEXT,
+        dye_channels BOOLEAN[] NOT NULL DEFAULT '{}', -- Which of the skin's channels can be dyed
+        synced_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+    );
+
+CREATE INDEX IF NOT EXISTS idx_catalog_skins_type ON catalog_skins(type, subtype);
+
+CREATE TABLE IF NOT EXISTS
+    catalog_colors (
+        id INTEGER PRIMARY KEY,
+        name TEXT NOT NULL DEFAULT '',
+        hue VARCHAR(16),
+        material VARCHAR(16),
+        rarity VARCHAR(16),
+        item_id INTEGER, -- Dye item that unlocks the color
+        base_rgb INTEGER[] NOT NULL DEFAULT '{}',
+        cloth_rgb INTEGER[] NOT NULL DEFAULT '{}',
+        leather_rgb INTEGER[] NOT NULL DEFAULT '{}',
+        metal_rgb INTEGER[] NOT NULL DEFAULT '{}',
+        fur_rgb INTEGER[] NOT NULL DEFAULT '{}',
+        synced_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+    );
+
+CREATE TABLE IF NOT EXISTS
+    catalog_outfits (
+        id INTEGER PRIMARY KEY,
+        name TEXT NOT NULL DEFAULT '',
+        icon TEXT,
+        synced_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+    );
+
+CREATE TABLE IF NOT EXISTS
+    catalog_gliders (
+        id INTEGER PRIMARY KEY,
+        name TEXT NOT NULL DEFAULT '',
+        icon TEXT,
+        default_dyes INTEGER[] NOT NULL DEFAULT '{}', -- One per dye channel
+        synced_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+    );
+
+CREATE TABLE IF NOT EXISTS
+    catalog_mount_skins (
+        id INTEGER PRIMARY KEY,
+        name TEXT NOT NULL DEFAULT '',
+        icon TEXT,
+        mount VARCHAR(32) NOT NULL,
+        dye_channels INTEGER NOT NULL DEFAULT 0,
+        synced_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+    );
+
+CREATE INDEX IF NOT EXISTS idx_catalog_mount_skins_mount ON catalog_mount_skins(mount);
@@ -0,0 +1,51 @@
+-- +migrate Up
+-- Equipment documents only hold GW2 IDs and slot names, so indexing their
+-- strings made every post match "coat" or "weapona1" and no skin name was ever
+-- searchable. Index the names of the skins, outfit, glider and mount skin the
+-- document uses instead, resolved from the local catalog.
+
+-- +migrate StatementBegin
+CREATE OR REPLACE FUNCTION post_equipment_names(equipments JSON)
+RETURNS TEXT AS $$
+    WITH doc AS (
+        SELECT equipments::jsonb AS d
+    ),
+    pieces AS (
+        SELECT piece
+        FROM doc, jsonb_array_elements(
+            CASE WHEN jsonb_typeof(d->'equipment') = 'array' THEN d->'equipment' ELSE '[]'::jsonb END
+        ) AS piece
+    )
+    SELECT string_agg(name, ' ')
+    FROM (
+        SELECT s.name FROM pieces
+        JOIN catalog_skins s ON piece->>'skin' ~ '^[0-9]+$' AND s.id = (piece->>'skin')::int
+        UNION ALL
+        SELECT o.name FROM doc
+        JOIN catalog_outfits o ON d->'outfit'->>'id' ~ '^[0-9]+$' AND o.id = (d->'outfit'->>'id')::int
+        UNION ALL
+        SELECT g.name FROM doc
+        JOIN catalog_gliders g ON d->'glider'->>'id' ~ '^[0-9]+$' AND g.id = (d->'glider'->>'id')::int
+        UNION ALL
+        SELECT m.name FROM doc
+        JOIN catalog_mount_skins m ON d->'mount'->>'skin' ~ '^[0-9]+$' AND m.id = (d->'mount'->>'skin')::int
+    ) names
+$$ LANGUAGE SQL STABLE;
+-- +migrate StatementEnd
+
+-- Builds the full-text document of a post. Weights rank title matches above
+-- tags, tags above the names of the worn skins and those above the description.
+-- STABLE rather than IMMUTABLE since skin names come from the catalog;
+-- `catalog sync` reindexes posts when it stores new cosmetics.
+-- +migrate StatementBegin
+CREATE OR REPLACE FUNCTION post_search_document(title TEXT, description TEXT, tags JSONB, equipments JSON)
+RETURNS tsvector AS $$
+    SELECT
+        setweight(to_tsvector('english', COALESCE(title, '')), 'A') ||
+        setweight(jsonb_to_tsvector('english', COALESCE(tags, '[]'::jsonb), '["string"]'), 'B') ||
+        setweight(to_tsvector('english', COALESCE(post_equipment_names(equipments), '')), 'C') ||
+        setweight(to_tsvector('english', COALESCE(description, '')), 'D')
+$$ LANGUAGE SQL STABLE;
+-- +migrate StatementEnd
+
+UPDATE posts SET search_vector = post_search_document(title, description, tags, equipments);
@@ -0,0 +1,11 @@
+-- +migrate Up
+-- api_key_checked_at is the last time the revalidation job tried a key, also
+-- when the check failed. Keys are picked by it rather than by the last
+-- successful check, so a key that keeps failing, or can no longer be
+-- decrypted, does not stay at the head of every batch.
+ALTER TABLE users ADD COLUMN IF NOT EXISTS api_key_checked_at TIMESTAMPTZ;
+
+UPDATE users SET api_key_checked_at = api_key_validated_at WHERE api_key_checked_at IS NULL;
+
+DROP INDEX IF EXISTS idx_users_api_key_validated_at;
+CREATE INDEX IF NOT EXISTS idx_users_api_key_checked_at ON users(api_key_checked_at) WHERE api_key_invalidated_at IS NULL;
@@ -1,5 +1,6 @@
 -- GW2Style Tags Reference
 -- This is a reference list of all valid tags organized by category
+-- The tags table (migration 00011) is the source of truth; posts only accept tags listed there
 -- Tags are stored as JSONB array in posts.tags column
 
 -- Example tag structure in posts table:
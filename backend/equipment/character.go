@@ -0,0 +1,77 @@
+package equipment
+
+import (
+	"github.com/NesoHQ/gw2style/gw2api"
+)
+
+// FromCharacter builds a document from a character's /equipment response.
+// Only worn items are kept, and items that were not transmuted show the
+// default skin of the item, looked up in defaultSkins by item ID.
+func FromCharacter(items []gw2api.EquippedItem, defaultSkins map[int]int) *Equipment {
+	e := &Equipment{Version: Version, Pieces: []Piece{}}
+
+	seen := map[Slot]bool{}
+	for _, item := range items {
+		slot := Slot(item.Slot)
+		if _, ok := slot.Kind(); !ok || seen[slot] || !worn(item) {
+			continue
+		}
+		seen[slot] = true
+
+		skin := item.Skin
+		if skin == 0 {
+			skin = defaultSkins[item.ID]
+		}
+
+		dyes := Dyes(item.Dyes)
+		if len(dyes) > MaxDyeChannels {
+			dyes = dyes[:MaxDyeChannels]
+		}
+
+		e.Pieces = append(e.Pieces, Piece{
+			Slot:      slot,
+			ItemID:    item.ID,
+			SkinID:    skin,
+			Dyes:      dyes,
+			Upgrades:  item.Upgrades,
+			Infusions: item.Infusions,
+		})
+	}
+
+	return e
+}
+
+// worn reports whether an item is on the character rather than kept in another equipment tab
+func worn(item gw2api.EquippedItem) bool {
+	switch item.Location {
+	case "", "Equipped", "EquippedFromLegendaryArmory":
+		return true
+	}
+	return false
+}
+
+// FitToSkins drops the skins that are unknown or cannot be worn in their slot
+// and the dyes on channels their skin does not have, so what GW2 reports
+// turns into a document that validates
+func (e *Equipment) FitToSkins(skins map[int]gw2api.Skin) {
+	for i := range e.Pieces {
+		piece := &e.Pieces[i]
+
+		skin, ok := skins[piece.SkinID]
+		if !ok || !skinFitsSlot(skin, piece.Slot) {
+			piece.SkinID = 0
+			piece.Dyes = nil
+			continue
+		}
+
+		channels := skin.DyeChannels()
+		for c := range piece.Dyes {
+			if c >= len(channels) || !channels[c] {
+				piece.Dyes[c] = nil
+			}
+		}
+		if len(piece.Dyes.IDs()) == 0 {
+			piece.Dyes = nil
+		}
+	}
+}
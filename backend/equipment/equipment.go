@@ -0,0 +1,174 @@
+// Package equipment is the typed model of the look a post shows off: the skin
+// and dyes of every armor piece, weapon and back item, plus an optional
+// outfit, glider and mount. It references GW2 skin, dye, outfit, glider and
+// mount skin IDs and validates them against a Catalog.
+package equipment
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// Version is the schema version stored with every equipment document
+const Version = 1
+
+// MaxDyeChannels is the most dye channels any GW2 cosmetic has
+const MaxDyeChannels = 4
+
+// OutfitDyeChannels is the number of dye channels every outfit has
+const OutfitDyeChannels = 4
+
+type SlotKind string
+
+const (
+	KindArmor         = SlotKind("armor")
+	KindWeapon        = SlotKind("weapon")
+	KindBack          = SlotKind("back")
+	KindTrinket       = SlotKind("trinket") // No skin or dyes, kept for the stats display
+	KindAquaticArmor  = SlotKind("aquatic_armor")
+	KindAquaticWeapon = SlotKind("aquatic_weapon")
+)
+
+// Slot is an equipment slot, named like the GW2 equipment tab API names them
+type Slot string
+
+const (
+	SlotHelm           = Slot("Helm")
+	SlotShoulders      = Slot("Shoulders")
+	SlotCoat           = Slot("Coat")
+	SlotGloves         = Slot("Gloves")
+	SlotLeggings       = Slot("Leggings")
+	SlotBoots          = Slot("Boots")
+	SlotHelmAquatic    = Slot("HelmAquatic")
+	SlotWeaponA1       = Slot("WeaponA1")
+	SlotWeaponA2       = Slot("WeaponA2")
+	SlotWeaponB1       = Slot("WeaponB1")
+	SlotWeaponB2       = Slot("WeaponB2")
+	SlotWeaponAquaticA = Slot("WeaponAquaticA")
+	SlotWeaponAquaticB = Slot("WeaponAquaticB")
+	SlotBackpack       = Slot("Backpack")
+	SlotAmulet         = Slot("Amulet")
+	SlotAccessory1     = Slot("Accessory1")
+	SlotAccessory2     = Slot("Accessory2")
+	SlotRing1          = Slot("Ring1")
+	SlotRing2          = Slot("Ring2")
+)
+
+var slotKinds = map[Slot]SlotKind{
+	SlotHelm:           KindArmor,
+	SlotShoulders:      KindArmor,
+	SlotCoat:           KindArmor,
+	SlotGloves:         KindArmor,
+	SlotLeggings:       KindArmor,
+	SlotBoots:          KindArmor,
+	SlotHelmAquatic:    KindAquaticArmor,
+	SlotWeaponA1:       KindWeapon,
+	SlotWeaponA2:       KindWeapon,
+	SlotWeaponB1:       KindWeapon,
+	SlotWeaponB2:       KindWeapon,
+	SlotWeaponAquaticA: KindAquaticWeapon,
+	SlotWeaponAquaticB: KindAquaticWeapon,
+	SlotBackpack:       KindBack,
+	SlotAmulet:         KindTrinket,
+	SlotAccessory1:     KindTrinket,
+	SlotAccessory2:     KindTrinket,
+	SlotRing1:          KindTrinket,
+	SlotRing2:          KindTrinket,
+}
+
+// Kind returns the kind of the slot, and false for unknown slots
+func (s Slot) Kind() (SlotKind, bool) {
+	kind, ok := slotKinds[s]
+	return kind, ok
+}
+
+// Weapon types, as GW2 names them in skin details
+var (
+	mainHandWeapons = []string{"Axe", "Dagger", "Mace", "Pistol", "Scepter", "Sword", "Spear"}
+	offHandWeapons  = []string{"Axe", "Dagger", "Mace", "Pistol", "Sword", "Focus", "Shield", "Torch", "Warhorn"}
+	twoHandWeapons  = []string{"Greatsword", "Hammer", "LongBow", "Rifle", "ShortBow", "Staff"}
+	aquaticWeapons  = []string{"Harpoon", "Speargun", "Trident"}
+)
+
+// Mount types, as GW2 names them in /v2/mounts/types
+var MountTypes = []string{"raptor", "springer", "skimmer", "jackal", "griffon", "roller_beetle", "warclaw", "skyscale", "siege_turtle"}
+
+// Equipment is the equipment document stored with a post
+type Equipment struct {
+	Version int     `json:"version"`
+	Name    string  `json:"name,omitempty"` // Name of the equipment tab it was taken from
+	Pieces  []Piece `json:"equipment"`
+	Outfit  *Outfit `json:"outfit,omitempty"`
+	Glider  *Glider `json:"glider,omitempty"`
+	Mount   *Mount  `json:"mount,omitempty"`
+}
+
+// Piece is the item worn in one slot
+type Piece struct {
+	Slot      Slot  `json:"slot"`
+	ItemID    int   `json:"id,omitempty"`   // GW2 item, used to show stats and rarity
+	SkinID    int   `json:"skin,omitempty"` // Transmuted skin; empty when the item shows its own
+	Dyes      Dyes  `json:"dyes,omitempty"`
+	Upgrades  []int `json:"upgrades,omitempty"`
+	Infusions []int `json:"infusions,omitempty"`
+}
+
+type Outfit struct {
+	ID   int  `json:"id"`
+	Dyes Dyes `json:"dyes,omitempty"`
+}
+
+type Glider struct {
+	ID   int  `json:"id"`
+	Dyes Dyes `json:"dyes,omitempty"`
+}
+
+type Mount struct {
+	Type   string `json:"type,omitempty"` // Filled in from the skin when left out
+	SkinID int    `json:"skin"`
+	Dyes   Dyes   `json:"dyes,omitempty"`
+}
+
+// Empty reports whether the document shows nothing at all
+func (e *Equipment) Empty() bool {
+	return len(e.Pieces) == 0 && e.Outfit == nil && e.Glider == nil && e.Mount == nil
+}
+
+// Dyes holds one dye ID per channel; nil leaves the channel at its default color
+type Dyes []*int
+
+// IDs returns the dye IDs that are set
+func (d Dyes) IDs() []int {
+	var ids []int
+	for _, id := range d {
+		if id != nil {
+			ids = append(ids, *id)
+		}
+	}
+	return ids
+}
+
+// Parse decodes an equipment document, rejecting unknown fields so typos are
+// reported instead of dropped. An empty body or JSON null returns nil.
+func Parse(data []byte) (*Equipment, error) {
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 || bytes.Equal(data, []byte("null")) {
+		return nil, nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+
+	var e Equipment
+	if err := decoder.Decode(&e); err != nil {
+		return nil, fmt.Errorf("invalid equipment: %w", err)
+	}
+	if e.Version == 0 {
+		e.Version = Version
+	}
+	if e.Pieces == nil {
+		e.Pieces = []Piece{}
+	}
+	return &e, nil
+}
@@ -0,0 +1,306 @@
+package equipment
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/NesoHQ/gw2style/gw2api"
+)
+
+// Catalog looks GW2 cosmetics up by ID. IDs it does not know are left out of
+// the returned maps. *gw2api.Client implements it, and so does
+// repo.CatalogRepository from the local copy of the cosmetics.
+type Catalog interface {
+	Skins(ctx context.Context, ids []int) (map[int]gw2api.Skin, error)
+	Colors(ctx context.Context, ids []int) (map[int]gw2api.Color, error)
+	Outfits(ctx context.Context, ids []int) (map[int]gw2api.Outfit, error)
+	Gliders(ctx context.Context, ids []int) (map[int]gw2api.Glider, error)
+	MountSkins(ctx context.Context, ids []int) (map[int]gw2api.MountSkin, error)
+}
+
+// FieldError is one problem with an equipment document, e.g.
+// {"field": "equipment[2].dyes[3]", "message": "skin \"Seraph Coat\" has no dye channel 4"}
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+type validator struct {
+	errors []FieldError
+}
+
+func (v *validator) add(field, format string, args ...any) {
+	v.errors = append(v.errors, FieldError{Field: field, Message: fmt.Sprintf(format, args...)})
+}
+
+// Validate checks the document's structure and that every skin, dye, outfit,
+// glider and mount skin exists in the catalog and fits where it is used. It
+// returns the problems found; the error is only set when the catalog failed.
+func (e *Equipment) Validate(ctx context.Context, catalog Catalog) ([]FieldError, error) {
+	v := &validator{}
+
+	if e.Version != Version {
+		v.add("version", "unsupported version %d, expected %d", e.Version, Version)
+		return v.errors, nil
+	}
+
+	e.validateStructure(v)
+	if len(v.errors) > 0 {
+		// IDs of a malformed document are not worth looking up
+		return v.errors, nil
+	}
+
+	if err := e.validateCatalog(ctx, catalog, v); err != nil {
+		return nil, err
+	}
+	return v.errors, nil
+}
+
+func (e *Equipment) validateStructure(v *validator) {
+	seen := map[Slot]bool{}
+	for i, piece := range e.Pieces {
+		field := fmt.Sprintf("equipment[%d]", i)
+
+		kind, ok := piece.Slot.Kind()
+		if !ok {
+			v.add(field+".slot", "unknown slot %q", piece.Slot)
+			continue
+		}
+		if seen[piece.Slot] {
+			v.add(field+".slot", "slot %s is listed twice", piece.Slot)
+		}
+		seen[piece.Slot] = true
+
+		if piece.ItemID < 0 {
+			v.add(field+".id", "must be a GW2 item ID")
+		}
+		if piece.SkinID < 0 {
+			v.add(field+".skin", "must be a GW2 skin ID")
+		}
+		if kind == KindTrinket {
+			if piece.SkinID != 0 {
+				v.add(field+".skin", "%s cannot have a skin", piece.Slot)
+			}
+			if len(piece.Dyes.IDs()) > 0 {
+				v.add(field+".dyes", "%s cannot be dyed", piece.Slot)
+			}
+		}
+		validateDyeList(v, field+".dyes", piece.Dyes)
+	}
+
+	if e.Outfit != nil {
+		if e.Outfit.ID <= 0 {
+			v.add("outfit.id", "must be a GW2 outfit ID")
+		}
+		validateDyeList(v, "outfit.dyes", e.Outfit.Dyes)
+	}
+	if e.Glider != nil {
+		if e.Glider.ID <= 0 {
+			v.add("glider.id", "must be a GW2 glider ID")
+		}
+		validateDyeList(v, "glider.dyes", e.Glider.Dyes)
+	}
+	if e.Mount != nil {
+		if e.Mount.SkinID <= 0 {
+			v.add("mount.skin", "must be a GW2 mount skin ID")
+		}
+		if e.Mount.Type != "" && !slices.Contains(MountTypes, e.Mount.Type) {
+			v.add("mount.type", "unknown mount type %q", e.Mount.Type)
+		}
+		validateDyeList(v, "mount.dyes", e.Mount.Dyes)
+	}
+}
+
+func validateDyeList(v *validator, field string, dyes Dyes) {
+	if len(dyes) > MaxDyeChannels {
+		v.add(field, "at most %d dye channels", MaxDyeChannels)
+	}
+	for i, id := range dyes {
+		if id != nil && *id <= 0 {
+			v.add(fmt.Sprintf("%s[%d]", field, i), "must be a GW2 dye ID")
+		}
+	}
+}
+
+func (e *Equipment) validateCatalog(ctx context.Context, catalog Catalog, v *validator) error {
+	var skinIDs, dyeIDs []int
+	for _, piece := range e.Pieces {
+		if piece.SkinID != 0 {
+			skinIDs = append(skinIDs, piece.SkinID)
+		}
+		dyeIDs = append(dyeIDs, piece.Dyes.IDs()...)
+	}
+
+	skins, err := catalog.Skins(ctx, skinIDs)
+	if err != nil {
+		return err
+	}
+
+	for i, piece := range e.Pieces {
+		if piece.SkinID == 0 {
+			continue
+		}
+		field := fmt.Sprintf("equipment[%d]", i)
+
+		skin, ok := skins[piece.SkinID]
+		if !ok {
+			v.add(field+".skin", "unknown skin %d", piece.SkinID)
+			continue
+		}
+		if !skinFitsSlot(skin, piece.Slot) {
+			v.add(field+".skin", "%s skin %q (%d) cannot be worn in %s", skinDescription(skin), skin.Name, skin.ID, piece.Slot)
+			continue
+		}
+		validateDyeChannels(v, field+".dyes", piece.Dyes, skin.DyeChannels(), fmt.Sprintf("skin %q", skin.Name))
+	}
+
+	// A two-handed weapon leaves no room for an off-hand in the same weapon set
+	for _, set := range [][2]Slot{{SlotWeaponA1, SlotWeaponA2}, {SlotWeaponB1, SlotWeaponB2}} {
+		main, off := e.piece(set[0]), e.piece(set[1])
+		if main < 0 || off < 0 {
+			continue
+		}
+		if skin, ok := skins[e.Pieces[main].SkinID]; ok && slices.Contains(twoHandWeapons, skin.Details.Type) {
+			v.add(fmt.Sprintf("equipment[%d].slot", off), "%s holds a two-handed %s", set[0], skin.Details.Type)
+		}
+	}
+
+	if e.Outfit != nil {
+		dyeIDs = append(dyeIDs, e.Outfit.Dyes.IDs()...)
+		outfits, err := catalog.Outfits(ctx, []int{e.Outfit.ID})
+		if err != nil {
+			return err
+		}
+		if outfit, ok := outfits[e.Outfit.ID]; !ok {
+			v.add("outfit.id", "unknown outfit %d", e.Outfit.ID)
+		} else {
+			validateDyeChannels(v, "outfit.dyes", e.Outfit.Dyes, allChannels(OutfitDyeChannels), fmt.Sprintf("outfit %q", outfit.Name))
+		}
+	}
+
+	if e.Glider != nil {
+		dyeIDs = append(dyeIDs, e.Glider.Dyes.IDs()...)
+		gliders, err := catalog.Gliders(ctx, []int{e.Glider.ID})
+		if err != nil {
+			return err
+		}
+		if glider, ok := gliders[e.Glider.ID]; !ok {
+			v.add("glider.id", "unknown glider %d", e.Glider.ID)
+		} else {
+			validateDyeChannels(v, "glider.dyes", e.Glider.Dyes, allChannels(len(glider.DefaultDyes)), fmt.Sprintf("glider %q", glider.Name))
+		}
+	}
+
+	if e.Mount != nil {
+		dyeIDs = append(dyeIDs, e.Mount.Dyes.IDs()...)
+		mountSkins, err := catalog.MountSkins(ctx, []int{e.Mount.SkinID})
+		if err != nil {
+			return err
+		}
+		if skin, ok := mountSkins[e.Mount.SkinID]; !ok {
+			v.add("mount.skin", "unknown mount skin %d", e.Mount.SkinID)
+		} else {
+			if e.Mount.Type == "" {
+				e.Mount.Type = skin.Mount
+			} else if e.Mount.Type != skin.Mount {
+				v.add("mount.type", "mount skin %q is for the %s, not the %s", skin.Name, skin.Mount, e.Mount.Type)
+			}
+			validateDyeChannels(v, "mount.dyes", e.Mount.Dyes, allChannels(len(skin.DyeSlots)), fmt.Sprintf("mount skin %q", skin.Name))
+		}
+	}
+
+	colors, err := catalog.Colors(ctx, dyeIDs)
+	if err != nil {
+		return err
+	}
+	e.forEachDye(func(field string, id int) {
+		if _, ok := colors[id]; !ok {
+			v.add(field, "unknown dye %d", id)
+		}
+	})
+
+	return nil
+}
+
+// validateDyeChannels reports dyes set on channels the cosmetic does not have
+// or that cannot be dyed. Skins without dye slots, like weapons, have none.
+func validateDyeChannels(v *validator, field string, dyes Dyes, channels []bool, what string) {
+	for i, id := range dyes {
+		if id == nil {
+			continue
+		}
+		if i >= len(channels) || !channels[i] {
+			v.add(fmt.Sprintf("%s[%d]", field, i), "%s has no dye channel %d", what, i+1)
+		}
+	}
+}
+
+func allChannels(n int) []bool {
+	channels := make([]bool, n)
+	for i := range channels {
+		channels[i] = true
+	}
+	return channels
+}
+
+// piece returns the index of the piece worn in slot, or -1
+func (e *Equipment) piece(slot Slot) int {
+	return slices.IndexFunc(e.Pieces, func(p Piece) bool { return p.Slot == slot })
+}
+
+// forEachDye calls fn with the field path of every dye that is set
+func (e *Equipment) forEachDye(fn func(field string, id int)) {
+	visit := func(prefix string, dyes Dyes) {
+		for i, id := range dyes {
+			if id != nil {
+				fn(fmt.Sprintf("%s[%d]", prefix, i), *id)
+			}
+		}
+	}
+
+	for i, piece := range e.Pieces {
+		visit(fmt.Sprintf("equipment[%d].dyes", i), piece.Dyes)
+	}
+	if e.Outfit != nil {
+		visit("outfit.dyes", e.Outfit.Dyes)
+	}
+	if e.Glider != nil {
+		visit("glider.dyes", e.Glider.Dyes)
+	}
+	if e.Mount != nil {
+		visit("mount.dyes", e.Mount.Dyes)
+	}
+}
+
+// skinFitsSlot reports whether a skin can be applied to the item in slot
+func skinFitsSlot(skin gw2api.Skin, slot Slot) bool {
+	kind, _ := slot.Kind()
+	switch kind {
+	case KindArmor:
+		return skin.Type == "Armor" && skin.Details.Type == string(slot)
+	case KindAquaticArmor:
+		return skin.Type == "Armor" && skin.Details.Type == "HelmAquatic"
+	case KindBack:
+		return skin.Type == "Back"
+	case KindAquaticWeapon:
+		return skin.Type == "Weapon" && slices.Contains(aquaticWeapons, skin.Details.Type)
+	case KindWeapon:
+		if skin.Type != "Weapon" {
+			return false
+		}
+		weapon := skin.Details.Type
+		if slot == SlotWeaponA2 || slot == SlotWeaponB2 {
+			return slices.Contains(offHandWeapons, weapon)
+		}
+		return slices.Contains(mainHandWeapons, weapon) || slices.Contains(twoHandWeapons, weapon)
+	}
+	return false
+}
+
+func skinDescription(skin gw2api.Skin) string {
+	if skin.Details.Type != "" {
+		return skin.Details.Type
+	}
+	return skin.Type
+}
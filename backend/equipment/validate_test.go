@@ -0,0 +1,186 @@
+package equipment
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/NesoHQ/gw2style/gw2api"
+)
+
+// fakeCatalog serves cosmetics from maps, leaving unknown IDs out like the GW2 API
+type fakeCatalog struct {
+	skins      map[int]gw2api.Skin
+	colors     map[int]gw2api.Color
+	outfits    map[int]gw2api.Outfit
+	gliders    map[int]gw2api.Glider
+	mountSkins map[int]gw2api.MountSkin
+	err        error
+}
+
+func lookup[T any](all map[int]T, ids []int, err error) (map[int]T, error) {
+	if err != nil {
+		return nil, err
+	}
+	found := make(map[int]T)
+	for _, id := range ids {
+		if v, ok := all[id]; ok {
+			found[id] = v
+		}
+	}
+	return found, nil
+}
+
+func (c *fakeCatalog) Skins(ctx context.Context, ids []int) (map[int]gw2api.Skin, error) {
+	return lookup(c.skins, ids, c.err)
+}
+
+func (c *fakeCatalog) Colors(ctx context.Context, ids []int) (map[int]gw2api.Color, error) {
+	return lookup(c.colors, ids, c.err)
+}
+
+func (c *fakeCatalog) Outfits(ctx context.Context, ids []int) (map[int]gw2api.Outfit, error) {
+	return lookup(c.outfits, ids, c.err)
+}
+
+func (c *fakeCatalog) Gliders(ctx context.Context, ids []int) (map[int]gw2api.Glider, error) {
+	return lookup(c.gliders, ids, c.err)
+}
+
+func (c *fakeCatalog) MountSkins(ctx context.Context, ids []int) (map[int]gw2api.MountSkin, error) {
+	return lookup(c.mountSkins, ids, c.err)
+}
+
+func mustSkin(t *testing.T, data string) gw2api.Skin {
+	t.Helper()
+	var skin gw2api.Skin
+	if err := json.Unmarshal([]byte(data), &skin); err != nil {
+		t.Fatalf("invalid skin: %v", err)
+	}
+	return skin
+}
+
+func newFakeCatalog(t *testing.T) *fakeCatalog {
+	return &fakeCatalog{
+		skins: map[int]gw2api.Skin{
+			// Three dye channels, the second one cannot be dyed
+			1: mustSkin(t, `{"id":1,"name":"Seraph Coat","type":"Armor","details":{"type":"Coat","weight_class":"Light","dye_slots":{"default":[{"color_id":1},null,{"color_id":1}]}}}`),
+			2: mustSkin(t, `{"id":2,"name":"Ascalonian Greatsword","type":"Weapon","details":{"type":"Greatsword"}}`),
+			3: mustSkin(t, `{"id":3,"name":"Krait Shield","type":"Weapon","details":{"type":"Shield"}}`),
+			4: mustSkin(t, `{"id":4,"name":"Seraph Helm","type":"Armor","details":{"type":"Helm","weight_class":"Light","dye_slots":{"default":[{"color_id":1}]}}}`),
+		},
+		colors: map[int]gw2api.Color{
+			10: {ID: 10, Name: "Abyss"},
+			11: {ID: 11, Name: "Celestial"},
+		},
+		outfits: map[int]gw2api.Outfit{20: {ID: 20, Name: "Shadow Assassin Outfit"}},
+		gliders: map[int]gw2api.Glider{30: {ID: 30, Name: "Basic Glider", DefaultDyes: []int{1, 1}}},
+		mountSkins: map[int]gw2api.MountSkin{
+			40: {ID: 40, Name: "Raptor", Mount: "raptor", DyeSlots: make([]gw2api.DyeSlot, 4)},
+		},
+	}
+}
+
+func validate(t *testing.T, catalog Catalog, document string) (*Equipment, []FieldError) {
+	t.Helper()
+	e, err := Parse([]byte(document))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	fieldErrors, err := e.Validate(context.Background(), catalog)
+	if err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+	return e, fieldErrors
+}
+
+func TestValidateAcceptsValidDocument(t *testing.T) {
+	e, fieldErrors := validate(t, newFakeCatalog(t), `{
+		"equipment": [
+			{"slot": "Coat", "skin": 1, "dyes": [10, null, 11]},
+			{"slot": "WeaponA1", "skin": 2},
+			{"slot": "WeaponB2", "skin": 3},
+			{"slot": "Amulet", "id": 79496}
+		],
+		"outfit": {"id": 20, "dyes": [10, 10, 11, 11]},
+		"glider": {"id": 30, "dyes": [10]},
+		"mount": {"skin": 40, "dyes": [11]}
+	}`)
+
+	if len(fieldErrors) > 0 {
+		t.Fatalf("unexpected errors: %+v", fieldErrors)
+	}
+	if e.Mount.Type != "raptor" {
+		t.Errorf("mount type = %q, want it filled in from the skin", e.Mount.Type)
+	}
+}
+
+func TestValidateReportsProblems(t *testing.T) {
+	tests := []struct {
+		name     string
+		document string
+		field    string
+	}{
+		{"unsupported version", `{"version": 2, "equipment": []}`, "version"},
+		{"unknown slot", `{"equipment": [{"slot": "Tail"}]}`, "equipment[0].slot"},
+		{"slot listed twice", `{"equipment": [{"slot": "Coat"}, {"slot": "Coat"}]}`, "equipment[1].slot"},
+		{"skinned trinket", `{"equipment": [{"slot": "Ring1", "skin": 1}]}`, "equipment[0].skin"},
+		{"too many dye channels", `{"equipment": [{"slot": "Coat", "dyes": [10, 10, 10, 10, 10]}]}`, "equipment[0].dyes"},
+		{"negative dye", `{"equipment": [{"slot": "Coat", "dyes": [-1]}]}`, "equipment[0].dyes[0]"},
+		{"unknown skin", `{"equipment": [{"slot": "Coat", "skin": 99}]}`, "equipment[0].skin"},
+		{"skin in the wrong slot", `{"equipment": [{"slot": "Helm", "skin": 1}]}`, "equipment[0].skin"},
+		{"two-handed weapon as off-hand", `{"equipment": [{"slot": "WeaponA2", "skin": 2}]}`, "equipment[0].skin"},
+		{"off-hand next to a two-handed weapon", `{"equipment": [{"slot": "WeaponA1", "skin": 2}, {"slot": "WeaponA2", "skin": 3}]}`, "equipment[1].slot"},
+		{"dye on a channel that cannot be dyed", `{"equipment": [{"slot": "Coat", "skin": 1, "dyes": [null, 10]}]}`, "equipment[0].dyes[1]"},
+		{"dye past the skin's channels", `{"equipment": [{"slot": "Helm", "skin": 4, "dyes": [10, 10]}]}`, "equipment[0].dyes[1]"},
+		{"dye on a weapon", `{"equipment": [{"slot": "WeaponA1", "skin": 2, "dyes": [10]}]}`, "equipment[0].dyes[0]"},
+		{"unknown dye", `{"equipment": [{"slot": "Coat", "skin": 1, "dyes": [12]}]}`, "equipment[0].dyes[0]"},
+		{"unknown outfit", `{"equipment": [], "outfit": {"id": 21}}`, "outfit.id"},
+		{"glider dye past its channels", `{"equipment": [], "glider": {"id": 30, "dyes": [10, 10, 10]}}`, "glider.dyes[2]"},
+		{"unknown mount type", `{"equipment": [], "mount": {"type": "dragon", "skin": 40}}`, "mount.type"},
+		{"mount skin of another mount", `{"equipment": [], "mount": {"type": "griffon", "skin": 40}}`, "mount.type"},
+		{"unknown mount skin", `{"equipment": [], "mount": {"skin": 41}}`, "mount.skin"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, fieldErrors := validate(t, newFakeCatalog(t), tt.document)
+			if len(fieldErrors) != 1 || fieldErrors[0].Field != tt.field {
+				t.Errorf("errors = %+v, want one on %s", fieldErrors, tt.field)
+			}
+		})
+	}
+}
+
+func TestValidateSkipsCatalogForMalformedDocument(t *testing.T) {
+	catalog := newFakeCatalog(t)
+	catalog.err = errors.New("catalog must not be called")
+
+	_, fieldErrors := validate(t, catalog, `{"equipment": [{"slot": "Tail", "skin": 1}]}`)
+	if len(fieldErrors) != 1 {
+		t.Errorf("errors = %+v, want one", fieldErrors)
+	}
+}
+
+func TestValidateReturnsCatalogErrors(t *testing.T) {
+	catalog := newFakeCatalog(t)
+	catalog.err = gw2api.ErrUpstreamDown
+
+	e, err := Parse([]byte(`{"equipment": [{"slot": "Coat", "skin": 1}]}`))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if _, err := e.Validate(context.Background(), catalog); !errors.Is(err, gw2api.ErrUpstreamDown) {
+		t.Errorf("err = %v, want ErrUpstreamDown", err)
+	}
+}
+
+func TestParseRejectsUnknownFields(t *testing.T) {
+	if _, err := Parse([]byte(`{"equipment": [{"slot": "Coat", "skinn": 1}]}`)); err == nil {
+		t.Error("Parse accepted an unknown field")
+	}
+	if e, err := Parse([]byte(" null ")); e != nil || err != nil {
+		t.Errorf("Parse(null) = %v, %v, want nil, nil", e, err)
+	}
+}
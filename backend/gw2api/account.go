@@ -0,0 +1,72 @@
+package gw2api
+
+import (
+	"context"
+	"time"
+)
+
+// RequiredPermissions are the API key permissions the site needs
+var RequiredPermissions = []string{"account", "characters", "builds"}
+
+const (
+	tokenInfoTTL = 5 * time.Minute
+	accountTTL   = 5 * time.Minute
+)
+
+// TokenInfo is the /v2/tokeninfo response
+type TokenInfo struct {
+	ID          string   `json:"id"`
+	Name        string   `json:"name"`
+	Permissions []string `json:"permissions"`
+	Type        string   `json:"type"` // "APIKey" or "Subtoken"
+}
+
+// Account is the /v2/account response
+type Account struct {
+	ID      string    `json:"id"`
+	Name    string    `json:"name"` // Account name, e.g. "Player.1234"
+	World   int       `json:"world"`
+	Created time.Time `json:"created"`
+}
+
+func (c *Client) TokenInfo(ctx context.Context, apiKey string) (*TokenInfo, error) {
+	var info TokenInfo
+	if err := c.get(ctx, "/v2/tokeninfo", apiKey, tokenInfoTTL, &info); err != nil {
+		return nil, err
+	}
+	return &info, nil
+}
+
+func (c *Client) Account(ctx context.Context, apiKey string) (*Account, error) {
+	var account Account
+	if err := c.get(ctx, "/v2/account", apiKey, accountTTL, &account); err != nil {
+		return nil, err
+	}
+	return &account, nil
+}
+
+// CheckPermissions returns the key's permissions, or a *MissingScopeError
+// listing every required permission it lacks
+func (c *Client) CheckPermissions(ctx context.Context, apiKey string, required ...string) ([]string, error) {
+	info, err := c.TokenInfo(ctx, apiKey)
+	if err != nil {
+		return nil, err
+	}
+
+	granted := make(map[string]bool, len(info.Permissions))
+	for _, p := range info.Permissions {
+		granted[p] = true
+	}
+
+	var missing []string
+	for _, p := range required {
+		if !granted[p] {
+			missing = append(missing, p)
+		}
+	}
+	if len(missing) > 0 {
+		return info.Permissions, &MissingScopeError{Scopes: missing}
+	}
+
+	return info.Permissions, nil
+}
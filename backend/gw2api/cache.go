@@ -0,0 +1,71 @@
+package gw2api
+
+import (
+	"sync"
+	"time"
+)
+
+// maxCacheEntries bounds the cache; expired entries are swept when it is reached
+const maxCacheEntries = 10000
+
+type cacheEntry struct {
+	body    []byte
+	expires time.Time
+}
+
+// cache keeps response bodies until their TTL passes
+type cache struct {
+	mu      sync.Mutex
+	entries map[string]cacheEntry
+}
+
+func newCache() *cache {
+	return &cache{entries: make(map[string]cacheEntry)}
+}
+
+func (c *cache) get(key string) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	if time.Now().After(entry.expires) {
+		delete(c.entries, key)
+		return nil, false
+	}
+	return entry.body, true
+}
+
+func (c *cache) set(key string, body []byte, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if len(c.entries) >= maxCacheEntries {
+		now := time.Now()
+		for k, entry := range c.entries {
+			if now.After(entry.expires) {
+				delete(c.entries, k)
+			}
+		}
+		// Still full of live entries: start over rather than grow without bound
+		if len(c.entries) >= maxCacheEntries {
+			clear(c.entries)
+		}
+	}
+
+	c.entries[key] = cacheEntry{body: body, expires: time.Now().Add(ttl)}
+}
+
+// invalidate drops every entry cached for an API key
+func (c *cache) invalidate(prefix string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for k := range c.entries {
+		if len(k) >= len(prefix) && k[:len(prefix)] == prefix {
+			delete(c.entries, k)
+		}
+	}
+}
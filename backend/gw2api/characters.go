@@ -0,0 +1,83 @@
+package gw2api
+
+import (
+	"context"
+	"net/url"
+	"time"
+)
+
+// ItemsPath is the bulk items endpoint
+const ItemsPath = "/v2/items"
+
+const (
+	charactersTTL = 5 * time.Minute
+	// equipmentTTL is short so a player who just changed their look can import it again
+	equipmentTTL = 30 * time.Second
+)
+
+// CharacterCore is the /v2/characters/:id/core response
+type CharacterCore struct {
+	Name       string    `json:"name"`
+	Race       string    `json:"race"`   // Asura, Charr, Human, Norn or Sylvari
+	Gender     string    `json:"gender"` // Male or Female
+	Profession string    `json:"profession"`
+	Level      int       `json:"level"`
+	Created    time.Time `json:"created"`
+}
+
+// EquippedItem is one entry of /v2/characters/:id/equipment
+type EquippedItem struct {
+	ID        int    `json:"id"`
+	Slot      string `json:"slot"`
+	Skin      int    `json:"skin"` // Set when the item was transmuted
+	Dyes      []*int `json:"dyes"`
+	Upgrades  []int  `json:"upgrades"`
+	Infusions []int  `json:"infusions"`
+	// Equipped, Armory, EquippedFromLegendaryArmory or LegendaryArmory
+	Location string `json:"location"`
+}
+
+// Item is a /v2/items entry, reduced to what is needed to show a look
+type Item struct {
+	ID          int    `json:"id"`
+	Name        string `json:"name"`
+	Type        string `json:"type"`
+	Rarity      string `json:"rarity"`
+	Icon        string `json:"icon"`
+	DefaultSkin int    `json:"default_skin"`
+}
+
+// Characters returns the names of the account's characters
+func (c *Client) Characters(ctx context.Context, apiKey string) ([]string, error) {
+	var names []string
+	if err := c.get(ctx, "/v2/characters", apiKey, charactersTTL, &names); err != nil {
+		return nil, err
+	}
+	return names, nil
+}
+
+// CharacterCore returns the race, gender, profession and level of a character
+func (c *Client) CharacterCore(ctx context.Context, apiKey, name string) (*CharacterCore, error) {
+	var core CharacterCore
+	if err := c.get(ctx, "/v2/characters/"+url.PathEscape(name)+"/core", apiKey, charactersTTL, &core); err != nil {
+		return nil, err
+	}
+	return &core, nil
+}
+
+// CharacterEquipment returns a character's equipment. Location tells the items
+// worn apart from ones only kept in other equipment tabs.
+func (c *Client) CharacterEquipment(ctx context.Context, apiKey, name string) ([]EquippedItem, error) {
+	var response struct {
+		Equipment []EquippedItem `json:"equipment"`
+	}
+	if err := c.get(ctx, "/v2/characters/"+url.PathEscape(name)+"/equipment", apiKey, equipmentTTL, &response); err != nil {
+		return nil, err
+	}
+	return response.Equipment, nil
+}
+
+// Items looks items up by ID. IDs GW2 does not know are left out of the result.
+func (c *Client) Items(ctx context.Context, ids []int) (map[int]Item, error) {
+	return getByIDs(ctx, c, ItemsPath, ids, func(i Item) int { return i.ID })
+}
@@ -0,0 +1,235 @@
+// Package gw2api is the client for the official Guild Wars 2 API. It rate
+// limits requests to stay inside GW2's limits, retries transient failures,
+// caches responses and maps GW2 error responses to typed errors.
+package gw2api
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/NesoHQ/gw2style/config"
+)
+
+const (
+	DefaultBaseURL      = "https://api.guildwars2.com"
+	DefaultTimeout      = 10 * time.Second
+	DefaultMaxRetries   = 2
+	DefaultRetryBackoff = 250 * time.Millisecond
+
+	// schemaVersion pins the response format so GW2 changes do not break parsing
+	schemaVersion = "2022-03-23T19:00:00.000Z"
+	// maxBodySize caps how much of a response is read
+	maxBodySize = 4 << 20
+)
+
+type Options struct {
+	BaseURL       string
+	Timeout       time.Duration // per attempt
+	MaxRetries    int           // negative disables retries
+	RetryBackoff  time.Duration // doubled on every retry
+	RateBurst     int
+	RatePerSecond float64
+}
+
+type Client struct {
+	baseURL    string
+	httpClient *http.Client
+	maxRetries int
+	backoff    time.Duration
+	limiter    *rateLimiter
+	cache      *cache
+}
+
+// New creates a client, using the GW2 defaults for every unset option
+func New(opts Options) *Client {
+	if opts.BaseURL == "" {
+		opts.BaseURL = DefaultBaseURL
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = DefaultTimeout
+	}
+	switch {
+	case opts.MaxRetries == 0:
+		opts.MaxRetries = DefaultMaxRetries
+	case opts.MaxRetries < 0:
+		opts.MaxRetries = 0
+	}
+	if opts.RetryBackoff <= 0 {
+		opts.RetryBackoff = DefaultRetryBackoff
+	}
+	if opts.RateBurst <= 0 {
+		opts.RateBurst = DefaultRateBurst
+	}
+	if opts.RatePerSecond <= 0 {
+		opts.RatePerSecond = DefaultRatePerSecond
+	}
+
+	return &Client{
+		baseURL:    strings.TrimRight(opts.BaseURL, "/"),
+		httpClient: &http.Client{Timeout: opts.Timeout},
+		maxRetries: opts.MaxRetries,
+		backoff:    opts.RetryBackoff,
+		limiter:    newRateLimiter(opts.RateBurst, opts.RatePerSecond),
+		cache:      newCache(),
+	}
+}
+
+// NewClient creates a client from the GW2_API_* settings
+func NewClient(cnf *config.Config) *Client {
+	retries := cnf.Gw2ApiMaxRetries
+	if retries == 0 {
+		retries = -1
+	}
+
+	return New(Options{
+		BaseURL:    cnf.Gw2ApiBaseURL,
+		Timeout:    time.Duration(cnf.Gw2ApiTimeout) * time.Second,
+		MaxRetries: retries,
+	})
+}
+
+// Invalidate drops every cached response fetched with apiKey, e.g. after it was replaced.
+// An empty key is ignored rather than dropping the public responses.
+func (c *Client) Invalidate(apiKey string) {
+	if apiKey == "" {
+		return
+	}
+	c.cache.invalidate(cachePrefix(apiKey))
+}
+
+// cachePrefix keys cached responses by a hash so API keys are not kept in memory as map keys
+func cachePrefix(apiKey string) string {
+	if apiKey == "" {
+		return "public|"
+	}
+	sum := sha256.Sum256([]byte(apiKey))
+	return hex.EncodeToString(sum[:8]) + "|"
+}
+
+// get fetches path and decodes the JSON response into out. Successful
+// responses are cached for ttl when ttl is positive.
+func (c *Client) get(ctx context.Context, path, apiKey string, ttl time.Duration, out any) error {
+	cacheKey := cachePrefix(apiKey) + path
+	if ttl > 0 {
+		if body, ok := c.cache.get(cacheKey); ok {
+			return json.Unmarshal(body, out)
+		}
+	}
+
+	body, err := c.fetch(ctx, path, apiKey)
+	if err != nil {
+		return err
+	}
+
+	if err := json.Unmarshal(body, out); err != nil {
+		return fmt.Errorf("failed to parse GW2 API response for %s: %w", path, err)
+	}
+	if ttl > 0 {
+		c.cache.set(cacheKey, body, ttl)
+	}
+	return nil
+}
+
+// fetch sends the request, retrying network errors, 429s and 5xx responses with backoff
+func (c *Client) fetch(ctx context.Context, path, apiKey string) ([]byte, error) {
+	var lastErr error
+
+	for attempt := 0; attempt <= c.maxRetries; attempt++ {
+		if attempt > 0 {
+			// Exponential backoff with up to 50% jitter
+			delay := c.backoff << (attempt - 1)
+			delay += time.Duration(rand.Int64N(int64(delay)/2 + 1))
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case <-time.After(delay):
+			}
+		}
+
+		if err := c.limiter.Wait(ctx); err != nil {
+			return nil, err
+		}
+
+		body, retry, err := c.do(ctx, path, apiKey)
+		if err == nil {
+			return body, nil
+		}
+		if !retry || ctx.Err() != nil {
+			return nil, err
+		}
+		lastErr = err
+	}
+
+	return nil, fmt.Errorf("%w: %w", ErrUpstreamDown, lastErr)
+}
+
+// do performs a single attempt and reports whether a failure is worth retrying
+func (c *Client) do(ctx context.Context, path, apiKey string) ([]byte, bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+path, nil)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("X-Schema-Version", schemaVersion)
+	if apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+apiKey)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, true, fmt.Errorf("failed to make request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxBodySize))
+	if err != nil {
+		return nil, true, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	if resp.StatusCode == http.StatusOK || resp.StatusCode == http.StatusPartialContent {
+		return body, false, nil
+	}
+
+	apiErr := newAPIError(resp.StatusCode, body)
+	retry := resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+	return nil, retry, apiErr
+}
+
+// newAPIError classifies an error response. GW2 answers 401/403 for bad keys
+// and 403 "requires scope X" when the key lacks a permission.
+func newAPIError(status int, body []byte) *APIError {
+	var payload struct {
+		Text string `json:"text"`
+	}
+	json.Unmarshal(body, &payload)
+
+	apiErr := &APIError{StatusCode: status, Text: payload.Text}
+	text := strings.ToLower(payload.Text)
+
+	switch {
+	case strings.HasPrefix(text, "requires scope "):
+		apiErr.kind = &MissingScopeError{Scopes: []string{strings.TrimPrefix(text, "requires scope ")}}
+	case status == http.StatusUnauthorized, status == http.StatusForbidden,
+		strings.Contains(text, "invalid access token"), strings.Contains(text, "invalid key"):
+		apiErr.kind = ErrInvalidKey
+	case status == http.StatusNotFound:
+		apiErr.kind = ErrNotFound
+	case status == http.StatusTooManyRequests, status >= 500:
+		apiErr.kind = ErrUpstreamDown
+	}
+	return apiErr
+}
+
+// IsUpstreamError reports whether err was caused by GW2 being unavailable
+// rather than by the key or the request
+func IsUpstreamError(err error) bool {
+	return errors.Is(err, ErrUpstreamDown) || errors.Is(err, context.DeadlineExceeded)
+}
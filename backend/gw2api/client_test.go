@@ -0,0 +1,145 @@
+package gw2api_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/NesoHQ/gw2style/gw2api"
+	"github.com/NesoHQ/gw2style/gw2api/gw2fake"
+)
+
+const testKey = "ABCD-1234"
+
+// newTestClient starts a fake with one fully permitted key and a client pointed at it
+func newTestClient(t *testing.T, opts gw2api.Options) (*gw2api.Client, *gw2fake.Server) {
+	t.Helper()
+
+	fake := gw2fake.New()
+	fake.AddKey(testKey, gw2fake.Key{
+		Name:        "gw2style",
+		Permissions: gw2api.RequiredPermissions,
+		Account:     gw2api.Account{ID: "acc-1", Name: "Player.1234"},
+	})
+	srv := fake.Start()
+	t.Cleanup(srv.Close)
+
+	opts.BaseURL = srv.URL
+	if opts.RetryBackoff == 0 {
+		opts.RetryBackoff = time.Millisecond
+	}
+	return gw2api.New(opts), fake
+}
+
+func TestCheckPermissionsInvalidKey(t *testing.T) {
+	client, _ := newTestClient(t, gw2api.Options{})
+
+	_, err := client.CheckPermissions(context.Background(), "unknown-key", gw2api.RequiredPermissions...)
+	if !errors.Is(err, gw2api.ErrInvalidKey) {
+		t.Fatalf("err = %v, want ErrInvalidKey", err)
+	}
+}
+
+func TestCheckPermissionsMissingScope(t *testing.T) {
+	client, fake := newTestClient(t, gw2api.Options{})
+	fake.AddKey("limited-key", gw2fake.Key{Permissions: []string{"account"}})
+
+	permissions, err := client.CheckPermissions(context.Background(), "limited-key", gw2api.RequiredPermissions...)
+	var missing *gw2api.MissingScopeError
+	if !errors.As(err, &missing) {
+		t.Fatalf("err = %v, want *MissingScopeError", err)
+	}
+	if !errors.Is(err, gw2api.ErrMissingScope) {
+		t.Errorf("err does not match ErrMissingScope")
+	}
+	if want := []string{"characters", "builds"}; !slices.Equal(missing.Scopes, want) {
+		t.Errorf("Scopes = %v, want %v", missing.Scopes, want)
+	}
+	if !slices.Equal(permissions, []string{"account"}) {
+		t.Errorf("permissions = %v, want [account]", permissions)
+	}
+}
+
+func TestAccountMissingScope(t *testing.T) {
+	client, fake := newTestClient(t, gw2api.Options{})
+	fake.AddKey("no-account-key", gw2fake.Key{Permissions: []string{"characters"}})
+
+	_, err := client.Account(context.Background(), "no-account-key")
+	var missing *gw2api.MissingScopeError
+	if !errors.As(err, &missing) {
+		t.Fatalf("err = %v, want *MissingScopeError", err)
+	}
+	if !slices.Equal(missing.Scopes, []string{"account"}) {
+		t.Errorf("Scopes = %v, want [account]", missing.Scopes)
+	}
+}
+
+func TestUpstreamDownRetries(t *testing.T) {
+	const retries = 2
+	client, fake := newTestClient(t, gw2api.Options{MaxRetries: retries})
+	fake.SetDown(true)
+
+	_, err := client.Account(context.Background(), testKey)
+	if !errors.Is(err, gw2api.ErrUpstreamDown) {
+		t.Fatalf("err = %v, want ErrUpstreamDown", err)
+	}
+	if !gw2api.IsUpstreamError(err) {
+		t.Errorf("IsUpstreamError(%v) = false", err)
+	}
+	if got := fake.Requests(); got != 1+retries {
+		t.Errorf("Requests() = %d, want %d", got, 1+retries)
+	}
+}
+
+func TestUpstreamRecoversOnRetry(t *testing.T) {
+	client, fake := newTestClient(t, gw2api.Options{MaxRetries: -1})
+	fake.SetDown(true)
+
+	if _, err := client.Account(context.Background(), testKey); !errors.Is(err, gw2api.ErrUpstreamDown) {
+		t.Fatalf("err = %v, want ErrUpstreamDown", err)
+	}
+	if got := fake.Requests(); got != 1 {
+		t.Errorf("Requests() = %d with retries disabled, want 1", got)
+	}
+
+	// Failures are not cached, the next call reaches GW2 again
+	fake.SetDown(false)
+	account, err := client.Account(context.Background(), testKey)
+	if err != nil {
+		t.Fatalf("Account: %v", err)
+	}
+	if account.Name != "Player.1234" {
+		t.Errorf("Name = %q, want Player.1234", account.Name)
+	}
+}
+
+func TestResponsesAreCached(t *testing.T) {
+	client, fake := newTestClient(t, gw2api.Options{})
+	ctx := context.Background()
+
+	if _, err := client.Account(ctx, testKey); err != nil {
+		t.Fatalf("Account: %v", err)
+	}
+	if _, err := client.Account(ctx, testKey); err != nil {
+		t.Fatalf("Account: %v", err)
+	}
+	if got := fake.Requests(); got != 1 {
+		t.Errorf("Requests() = %d after a cached call, want 1", got)
+	}
+
+	// A rename is only seen once the key's responses are invalidated
+	fake.RenameAccount("acc-1", "Renamed.5678")
+	client.Invalidate(testKey)
+	account, err := client.Account(ctx, testKey)
+	if err != nil {
+		t.Fatalf("Account: %v", err)
+	}
+	if account.Name != "Renamed.5678" {
+		t.Errorf("Name = %q, want Renamed.5678", account.Name)
+	}
+	if got := fake.Requests(); got != 2 {
+		t.Errorf("Requests() = %d after Invalidate, want 2", got)
+	}
+}
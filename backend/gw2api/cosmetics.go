@@ -0,0 +1,175 @@
+package gw2api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// cosmeticsTTL is long because skins, dyes and outfits never change once released
+	cosmeticsTTL = 24 * time.Hour
+	// MaxIDsPerRequest is the most IDs GW2 accepts in one ?ids= request
+	MaxIDsPerRequest = 200
+)
+
+// DyeSlot is one dye channel of a skin; GW2 sends null for channels that cannot be dyed
+type DyeSlot struct {
+	ColorID  int    `json:"color_id"`
+	Material string `json:"material"`
+}
+
+// SkinDyeSlots are the dye channels of an armor skin. GW2 also lists race and
+// gender specific overrides, which do not change which channels exist.
+type SkinDyeSlots struct {
+	Default []*DyeSlot `json:"default"`
+}
+
+// Skin is a /v2/skins entry
+type Skin struct {
+	ID      int    `json:"id"`
+	Name    string `json:"name"`
+	Type    string `json:"type"` // Armor, Weapon, Back or Gathering
+	Rarity  string `json:"rarity"`
+	Icon    string `json:"icon"`
+	Details struct {
+		Type        string        `json:"type"`         // Armor slot (Coat, Helm, ...) or weapon type (Axe, Staff, ...)
+		WeightClass string        `json:"weight_class"` // Armor only: Clothing, Light, Medium or Heavy
+		DyeSlots    *SkinDyeSlots `json:"dye_slots"`
+	} `json:"details"`
+}
+
+// DyeChannels returns how many channels the skin has and which of them can be dyed
+func (s Skin) DyeChannels() []bool {
+	if s.Details.DyeSlots == nil {
+		return nil
+	}
+	channels := make([]bool, len(s.Details.DyeSlots.Default))
+	for i, slot := range s.Details.DyeSlots.Default {
+		channels[i] = slot != nil
+	}
+	return channels
+}
+
+// ColorMaterial is how a dye looks on one material
+type ColorMaterial struct {
+	RGB []int `json:"rgb"`
+}
+
+// Color is a /v2/colors entry, i.e. a dye
+type Color struct {
+	ID         int           `json:"id"`
+	Name       string        `json:"name"`
+	BaseRGB    []int         `json:"base_rgb"`
+	Cloth      ColorMaterial `json:"cloth"`
+	Leather    ColorMaterial `json:"leather"`
+	Metal      ColorMaterial `json:"metal"`
+	Fur        ColorMaterial `json:"fur"`
+	Item       int           `json:"item"`
+	Categories []string      `json:"categories"` // Hue, material and rarity
+}
+
+// Outfit is a /v2/outfits entry. Outfits always have four dye channels.
+type Outfit struct {
+	ID          int    `json:"id"`
+	Name        string `json:"name"`
+	Icon        string `json:"icon"`
+	UnlockItems []int  `json:"unlock_items"`
+}
+
+// Glider is a /v2/gliders entry
+type Glider struct {
+	ID          int    `json:"id"`
+	Name        string `json:"name"`
+	Icon        string `json:"icon"`
+	DefaultDyes []int  `json:"default_dyes"` // One per dye channel
+	UnlockItems []int  `json:"unlock_items"`
+}
+
+// MountSkin is a /v2/mounts/skins entry
+type MountSkin struct {
+	ID       int       `json:"id"`
+	Name     string    `json:"name"`
+	Icon     string    `json:"icon"`
+	Mount    string    `json:"mount"` // Mount type, e.g. raptor or skyscale
+	DyeSlots []DyeSlot `json:"dye_slots"`
+}
+
+// Bulk cosmetics endpoints, as passed to ListIDs
+const (
+	SkinsPath      = "/v2/skins"
+	ColorsPath     = "/v2/colors"
+	OutfitsPath    = "/v2/outfits"
+	GlidersPath    = "/v2/gliders"
+	MountSkinsPath = "/v2/mounts/skins"
+)
+
+// ListIDs returns every ID a bulk endpoint knows, e.g. ListIDs(ctx, SkinsPath).
+// The list grows with every release, so it is not cached.
+func (c *Client) ListIDs(ctx context.Context, path string) ([]int, error) {
+	var ids []int
+	if err := c.get(ctx, path, "", 0, &ids); err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+// Skins looks skins up by ID. IDs GW2 does not know are left out of the result.
+func (c *Client) Skins(ctx context.Context, ids []int) (map[int]Skin, error) {
+	return getByIDs(ctx, c, SkinsPath, ids, func(s Skin) int { return s.ID })
+}
+
+// Colors looks dyes up by ID. IDs GW2 does not know are left out of the result.
+func (c *Client) Colors(ctx context.Context, ids []int) (map[int]Color, error) {
+	return getByIDs(ctx, c, ColorsPath, ids, func(d Color) int { return d.ID })
+}
+
+// Outfits looks outfits up by ID. IDs GW2 does not know are left out of the result.
+func (c *Client) Outfits(ctx context.Context, ids []int) (map[int]Outfit, error) {
+	return getByIDs(ctx, c, OutfitsPath, ids, func(o Outfit) int { return o.ID })
+}
+
+// Gliders looks glider skins up by ID. IDs GW2 does not know are left out of the result.
+func (c *Client) Gliders(ctx context.Context, ids []int) (map[int]Glider, error) {
+	return getByIDs(ctx, c, GlidersPath, ids, func(g Glider) int { return g.ID })
+}
+
+// MountSkins looks mount skins up by ID. IDs GW2 does not know are left out of the result.
+func (c *Client) MountSkins(ctx context.Context, ids []int) (map[int]MountSkin, error) {
+	return getByIDs(ctx, c, MountSkinsPath, ids, func(m MountSkin) int { return m.ID })
+}
+
+// getByIDs fetches a bulk endpoint in batches. GW2 answers 206 with the known
+// entries when some IDs are unknown, and 404 when none are.
+func getByIDs[T any](ctx context.Context, c *Client, path string, ids []int, id func(T) int) (map[int]T, error) {
+	ids = slices.Clone(ids)
+	slices.Sort(ids)
+	ids = slices.Compact(ids)
+
+	result := make(map[int]T, len(ids))
+	for batch := range slices.Chunk(ids, MaxIDsPerRequest) {
+		parts := make([]string, len(batch))
+		for i, v := range batch {
+			parts[i] = strconv.Itoa(v)
+		}
+
+		var entries []T
+		err := c.get(ctx, fmt.Sprintf("%s?ids=%s", path, strings.Join(parts, ",")), "", cosmeticsTTL, &entries)
+		if errors.Is(err, ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		for _, entry := range entries {
+			result[id(entry)] = entry
+		}
+	}
+
+	return result, nil
+}
@@ -0,0 +1,50 @@
+package gw2api
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	// ErrInvalidKey means GW2 rejected the API key: it is malformed, deleted or revoked
+	ErrInvalidKey = errors.New("invalid GW2 API key")
+	// ErrMissingScope means the key is valid but lacks a permission the request needs
+	ErrMissingScope = errors.New("GW2 API key is missing a required permission")
+	// ErrNotFound means the requested resource does not exist, e.g. an unknown character
+	ErrNotFound = errors.New("GW2 API resource not found")
+	// ErrUpstreamDown means the GW2 API could not be reached or kept failing after retries
+	ErrUpstreamDown = errors.New("GW2 API unavailable")
+)
+
+// MissingScopeError lists the permissions a key lacks. It matches ErrMissingScope with errors.Is.
+type MissingScopeError struct {
+	Scopes []string
+}
+
+func (e *MissingScopeError) Error() string {
+	return "missing required permission: " + strings.Join(e.Scopes, ", ")
+}
+
+func (e *MissingScopeError) Is(target error) bool {
+	return target == ErrMissingScope
+}
+
+// APIError is a response GW2 answered with an unexpected status. It wraps one
+// of the sentinel errors above when the status maps to one.
+type APIError struct {
+	StatusCode int
+	Text       string // "text" field of the GW2 error body
+	kind       error
+}
+
+func (e *APIError) Error() string {
+	if e.Text != "" {
+		return fmt.Sprintf("gw2 api returned status %d: %s", e.StatusCode, e.Text)
+	}
+	return fmt.Sprintf("gw2 api returned status %d", e.StatusCode)
+}
+
+func (e *APIError) Unwrap() error {
+	return e.kind
+}
@@ -0,0 +1,94 @@
+package gw2fake
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/NesoHQ/gw2style/gw2api"
+)
+
+// Character is a character of an account known to the fake
+type Character struct {
+	Core      gw2api.CharacterCore
+	Equipment []gw2api.EquippedItem
+}
+
+// AddCharacter gives the account a character, replacing any with the same name
+func (s *Server) AddCharacter(accountID string, character Character) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	characters := s.characters[accountID]
+	for i, c := range characters {
+		if c.Core.Name == character.Core.Name {
+			characters[i] = character
+			return
+		}
+	}
+	s.characters[accountID] = append(characters, character)
+}
+
+// AddItem makes the item available on /v2/items
+func (s *Server) AddItem(item gw2api.Item) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.items[item.ID] = item
+}
+
+// serveCharacters answers /v2/characters and /v2/characters/:name/{core,equipment}
+// and reports whether path was one of them
+func (s *Server) serveCharacters(w http.ResponseWriter, r *http.Request, apiKey string) bool {
+	rest, ok := strings.CutPrefix(r.URL.EscapedPath(), "/v2/characters")
+	if !ok {
+		return false
+	}
+
+	key, ok := s.authenticate(w, apiKey, "characters")
+	if !ok {
+		return true
+	}
+	characters := s.characters[key.Account.ID]
+
+	if rest == "" {
+		names := make([]string, 0, len(characters))
+		for _, c := range characters {
+			names = append(names, c.Core.Name)
+		}
+		writeJSON(w, names)
+		return true
+	}
+
+	escaped, endpoint, _ := strings.Cut(strings.TrimPrefix(rest, "/"), "/")
+	name, err := url.PathUnescape(escaped)
+	if err != nil {
+		writeError(w, http.StatusNotFound, "no such character")
+		return true
+	}
+	var character *Character
+	for i := range characters {
+		if characters[i].Core.Name == name {
+			character = &characters[i]
+		}
+	}
+	if character == nil {
+		writeError(w, http.StatusNotFound, "no such character")
+		return true
+	}
+
+	switch endpoint {
+	case "core":
+		writeJSON(w, character.Core)
+	case "equipment":
+		if _, ok := s.authenticate(w, apiKey, "builds"); !ok {
+			return true
+		}
+		equipment := character.Equipment
+		if equipment == nil {
+			equipment = []gw2api.EquippedItem{}
+		}
+		writeJSON(w, map[string]any{"equipment": equipment})
+	default:
+		writeError(w, http.StatusNotFound, "no such endpoint")
+	}
+	return true
+}
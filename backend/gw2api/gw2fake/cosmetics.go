@@ -0,0 +1,123 @@
+package gw2fake
+
+import (
+	"maps"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/NesoHQ/gw2style/gw2api"
+)
+
+type cosmetics struct {
+	skins      map[int]gw2api.Skin
+	colors     map[int]gw2api.Color
+	outfits    map[int]gw2api.Outfit
+	gliders    map[int]gw2api.Glider
+	mountSkins map[int]gw2api.MountSkin
+}
+
+func newCosmetics() cosmetics {
+	return cosmetics{
+		skins:      make(map[int]gw2api.Skin),
+		colors:     make(map[int]gw2api.Color),
+		outfits:    make(map[int]gw2api.Outfit),
+		gliders:    make(map[int]gw2api.Glider),
+		mountSkins: make(map[int]gw2api.MountSkin),
+	}
+}
+
+// AddSkin makes the skin available on /v2/skins
+func (s *Server) AddSkin(skin gw2api.Skin) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.cosmetics.skins[skin.ID] = skin
+}
+
+// AddColor makes the dye available on /v2/colors
+func (s *Server) AddColor(color gw2api.Color) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.cosmetics.colors[color.ID] = color
+}
+
+// AddOutfit makes the outfit available on /v2/outfits
+func (s *Server) AddOutfit(outfit gw2api.Outfit) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.cosmetics.outfits[outfit.ID] = outfit
+}
+
+// AddGlider makes the glider available on /v2/gliders
+func (s *Server) AddGlider(glider gw2api.Glider) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.cosmetics.gliders[glider.ID] = glider
+}
+
+// AddMountSkin makes the mount skin available on /v2/mounts/skins
+func (s *Server) AddMountSkin(skin gw2api.MountSkin) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.cosmetics.mountSkins[skin.ID] = skin
+}
+
+// serveCosmetics answers the bulk cosmetics and items endpoints and reports whether path was one of them
+func (s *Server) serveCosmetics(w http.ResponseWriter, r *http.Request) bool {
+	switch r.URL.Path {
+	case gw2api.SkinsPath:
+		serveByIDs(w, r, s.cosmetics.skins)
+	case gw2api.ColorsPath:
+		serveByIDs(w, r, s.cosmetics.colors)
+	case gw2api.OutfitsPath:
+		serveByIDs(w, r, s.cosmetics.outfits)
+	case gw2api.GlidersPath:
+		serveByIDs(w, r, s.cosmetics.gliders)
+	case gw2api.MountSkinsPath:
+		serveByIDs(w, r, s.cosmetics.mountSkins)
+	case gw2api.ItemsPath:
+		serveByIDs(w, r, s.items)
+	default:
+		return false
+	}
+	return true
+}
+
+// serveByIDs mimics GW2's bulk endpoints: the list of IDs without ?ids=, and
+// with it 206 when only some IDs are known and 404 when none are
+func serveByIDs[T any](w http.ResponseWriter, r *http.Request, entries map[int]T) {
+	if !r.URL.Query().Has("ids") {
+		ids := slices.Sorted(maps.Keys(entries))
+		if ids == nil {
+			ids = []int{}
+		}
+		writeJSON(w, ids)
+		return
+	}
+
+	var found []T
+	requested := 0
+	for _, part := range strings.Split(r.URL.Query().Get("ids"), ",") {
+		id, err := strconv.Atoi(part)
+		if err != nil {
+			continue
+		}
+		requested++
+		if entry, ok := entries[id]; ok {
+			found = append(found, entry)
+		}
+	}
+
+	if len(found) == 0 {
+		writeError(w, http.StatusNotFound, "all ids provided are invalid")
+		return
+	}
+	if len(found) < requested {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusPartialContent)
+		writeJSON(w, found)
+		return
+	}
+	writeJSON(w, found)
+}
@@ -0,0 +1,163 @@
+// Package gw2fake is an in-process stand-in for the GW2 API. It answers the
+// endpoints the gw2api client uses from keys, characters and cosmetics registered on it,
+// so login and other GW2-dependent features can run without network access.
+package gw2fake
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/NesoHQ/gw2style/gw2api"
+)
+
+// Key is an API key known to the fake
+type Key struct {
+	Name        string
+	Permissions []string
+	Account     gw2api.Account
+}
+
+type Server struct {
+	mu        sync.RWMutex
+	keys      map[string]Key
+	cosmetics cosmetics
+	// Characters by account ID
+	characters map[string][]Character
+	items      map[int]gw2api.Item
+	down       bool
+	requests   atomic.Int64
+}
+
+func New() *Server {
+	return &Server{
+		keys:       make(map[string]Key),
+		cosmetics:  newCosmetics(),
+		characters: make(map[string][]Character),
+		items:      make(map[int]gw2api.Item),
+	}
+}
+
+// Start serves the fake on a local port; point the client's BaseURL at URL and Close it when done
+func (s *Server) Start() *httptest.Server {
+	return httptest.NewServer(s)
+}
+
+// AddKey registers an API key, replacing any key with the same value
+func (s *Server) AddKey(apiKey string, key Key) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.keys[apiKey] = key
+}
+
+// RevokeKey makes the fake reject apiKey like GW2 does for deleted keys
+func (s *Server) RevokeKey(apiKey string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.keys, apiKey)
+}
+
+// RenameAccount changes the account name returned for every key of the account
+func (s *Server) RenameAccount(accountID, name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for apiKey, key := range s.keys {
+		if key.Account.ID == accountID {
+			key.Account.Name = name
+			s.keys[apiKey] = key
+		}
+	}
+}
+
+// SetDown makes every request fail with 503 until it is called with false
+func (s *Server) SetDown(down bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.down = down
+}
+
+// Requests returns how many requests the fake has received, to check caching
+func (s *Server) Requests() int64 {
+	return s.requests.Load()
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.requests.Add(1)
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.down {
+		writeError(w, http.StatusServiceUnavailable, "API not active")
+		return
+	}
+
+	apiKey := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if apiKey == "" {
+		apiKey = r.URL.Query().Get("access_token")
+	}
+
+	switch r.URL.Path {
+	case "/v2/tokeninfo":
+		key, ok := s.authenticate(w, apiKey, "")
+		if !ok {
+			return
+		}
+		writeJSON(w, gw2api.TokenInfo{
+			ID:          keyID(apiKey),
+			Name:        key.Name,
+			Permissions: key.Permissions,
+			Type:        "APIKey",
+		})
+	case "/v2/account":
+		key, ok := s.authenticate(w, apiKey, "account")
+		if !ok {
+			return
+		}
+		writeJSON(w, key.Account)
+	default:
+		if !s.serveCosmetics(w, r) && !s.serveCharacters(w, r, apiKey) {
+			writeError(w, http.StatusNotFound, "no such endpoint")
+		}
+	}
+}
+
+// authenticate looks up the key and checks it has scope, writing GW2's error response if not
+func (s *Server) authenticate(w http.ResponseWriter, apiKey, scope string) (Key, bool) {
+	key, ok := s.keys[apiKey]
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "Invalid access token")
+		return Key{}, false
+	}
+
+	if scope != "" {
+		for _, p := range key.Permissions {
+			if p == scope {
+				return key, true
+			}
+		}
+		writeError(w, http.StatusForbidden, "requires scope "+scope)
+		return Key{}, false
+	}
+	return key, true
+}
+
+// keyID mimics GW2 returning the first part of the key as its ID
+func keyID(apiKey string) string {
+	id, _, _ := strings.Cut(apiKey, "-")
+	return id
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, text string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{"text": text})
+}
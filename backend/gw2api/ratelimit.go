@@ -0,0 +1,57 @@
+package gw2api
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// GW2 allows bursts of 300 requests per IP, refilled at 5 requests per second
+const (
+	DefaultRateBurst     = 300
+	DefaultRatePerSecond = 5
+)
+
+// rateLimiter is a token bucket that blocks callers until a request may be sent
+type rateLimiter struct {
+	mu       sync.Mutex
+	tokens   float64
+	burst    float64
+	perSec   float64
+	lastFill time.Time
+}
+
+func newRateLimiter(burst int, perSecond float64) *rateLimiter {
+	return &rateLimiter{
+		tokens:   float64(burst),
+		burst:    float64(burst),
+		perSec:   perSecond,
+		lastFill: time.Now(),
+	}
+}
+
+// Wait takes a token, sleeping until one is available or ctx is done
+func (l *rateLimiter) Wait(ctx context.Context) error {
+	for {
+		l.mu.Lock()
+		now := time.Now()
+		l.tokens = min(l.burst, l.tokens+now.Sub(l.lastFill).Seconds()*l.perSec)
+		l.lastFill = now
+
+		if l.tokens >= 1 {
+			l.tokens--
+			l.mu.Unlock()
+			return nil
+		}
+		wait := time.Duration((1 - l.tokens) / l.perSec * float64(time.Second))
+		l.mu.Unlock()
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
@@ -0,0 +1,138 @@
+// Package jobs holds the background work the server runs next to the HTTP API
+package jobs
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/NesoHQ/gw2style/config"
+	"github.com/NesoHQ/gw2style/gw2api"
+	"github.com/NesoHQ/gw2style/repo"
+)
+
+// revalidationTick is how often the job looks for keys that are due
+const revalidationTick = 10 * time.Minute
+
+// SessionRevoker ends every session of a user, see repo.SessionRepository
+type SessionRevoker interface {
+	RevokeUserSessions(ctx context.Context, userID, reason string) (int64, error)
+}
+
+// APIKeyRevalidator rechecks stored API keys against /v2/tokeninfo and
+// /v2/account. Revoked keys are marked invalid and their sessions revoked;
+// account renames are written to the user, their posts, reports and revisions.
+type APIKeyRevalidator struct {
+	users     repo.UserRepo
+	sessions  SessionRevoker
+	gw2       *gw2api.Client
+	interval  time.Duration
+	batchSize int
+}
+
+func NewAPIKeyRevalidator(cnf *config.Config, users repo.UserRepo, sessions SessionRevoker, gw2 *gw2api.Client) *APIKeyRevalidator {
+	return &APIKeyRevalidator{
+		users:     users,
+		sessions:  sessions,
+		gw2:       gw2,
+		interval:  cnf.ApiKeyRevalidationInterval(),
+		batchSize: cnf.ApiKeyRevalidationBatch,
+	}
+}
+
+// Run revalidates due keys every revalidationTick until ctx is done
+func (j *APIKeyRevalidator) Run(ctx context.Context) {
+	ticker := time.NewTicker(revalidationTick)
+	defer ticker.Stop()
+
+	for {
+		if _, err := j.RevalidateDue(ctx); err != nil && ctx.Err() == nil {
+			slog.Error("API key revalidation failed", "error", err.Error())
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RevalidateDue checks one batch of keys not checked within the interval
+// and returns how many were checked. It stops early when the GW2 API is down
+// so keys are not marked invalid because of an outage.
+func (j *APIKeyRevalidator) RevalidateDue(ctx context.Context) (int, error) {
+	users, err := j.users.FindUsersDueForRevalidation(time.Now().Add(-j.interval), j.batchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	checked := 0
+	for _, user := range users {
+		if err := j.revalidate(ctx, user); err != nil {
+			if gw2api.IsUpstreamError(err) || ctx.Err() != nil {
+				return checked, err
+			}
+			slog.Warn("Failed to revalidate API key", "user_id", user.ID, "error", err.Error())
+			// Otherwise the key stays first in line and is retried by every run
+			if err := j.users.MarkAPIKeyChecked(user.ID); err != nil {
+				return checked, err
+			}
+		}
+		checked++
+	}
+
+	if checked > 0 {
+		slog.Info("Revalidated API keys", "checked", checked)
+	}
+	return checked, nil
+}
+
+func (j *APIKeyRevalidator) revalidate(ctx context.Context, user repo.User) error {
+	permissions, err := j.gw2.CheckPermissions(ctx, user.ApiKey, gw2api.RequiredPermissions...)
+	if isRevoked(err) {
+		return j.invalidate(ctx, user)
+	}
+	if err != nil {
+		return err
+	}
+
+	account, err := j.gw2.Account(ctx, user.ApiKey)
+	if isRevoked(err) {
+		return j.invalidate(ctx, user)
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := j.users.MarkAPIKeyValid(user.ID, account.Name, permissions); err != nil {
+		return err
+	}
+	if account.Name != user.Name {
+		slog.Info("GW2 account renamed", "user_id", user.ID, "old_name", user.Name, "new_name", account.Name)
+	}
+	return nil
+}
+
+// invalidate marks the key invalid and logs the user out everywhere
+func (j *APIKeyRevalidator) invalidate(ctx context.Context, user repo.User) error {
+	if err := j.users.MarkAPIKeyInvalid(user.ID); err != nil {
+		return err
+	}
+	j.gw2.Invalidate(user.ApiKey)
+
+	revoked, err := j.sessions.RevokeUserSessions(ctx, user.ID, repo.SessionRevokedKeyInvalid)
+	if err != nil {
+		return err
+	}
+
+	slog.Info("API key revoked on GW2, sessions ended", "user_id", user.ID, "sessions", revoked)
+	return nil
+}
+
+// isRevoked reports whether GW2 no longer accepts the key for the site:
+// it was deleted or lost a permission the site needs
+func isRevoked(err error) bool {
+	return errors.Is(err, gw2api.ErrInvalidKey) || errors.Is(err, gw2api.ErrMissingScope)
+}
@@ -0,0 +1,174 @@
+package jobs
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/NesoHQ/gw2style/config"
+	"github.com/NesoHQ/gw2style/gw2api"
+	"github.com/NesoHQ/gw2style/gw2api/gw2fake"
+	"github.com/NesoHQ/gw2style/repo"
+)
+
+// fakeUsers serves due users from a slice and records what the job wrote back
+type fakeUsers struct {
+	repo.UserRepo
+	due      []repo.User
+	valid    map[string]string // user ID to account name
+	invalid  []string
+	checked  []string
+	dueLimit int
+}
+
+func (u *fakeUsers) FindUsersDueForRevalidation(checkedBefore time.Time, limit int) ([]repo.User, error) {
+	u.dueLimit = limit
+	return u.due, nil
+}
+
+func (u *fakeUsers) MarkAPIKeyValid(ID, name string, permissions []string) error {
+	u.valid[ID] = name
+	return nil
+}
+
+func (u *fakeUsers) MarkAPIKeyInvalid(ID string) error {
+	u.invalid = append(u.invalid, ID)
+	return nil
+}
+
+func (u *fakeUsers) MarkAPIKeyChecked(ID string) error {
+	u.checked = append(u.checked, ID)
+	return nil
+}
+
+// fakeSessions records the users whose sessions were revoked
+type fakeSessions struct {
+	revoked map[string]string // user ID to reason
+}
+
+func (s *fakeSessions) RevokeUserSessions(ctx context.Context, userID, reason string) (int64, error) {
+	s.revoked[userID] = reason
+	return 1, nil
+}
+
+// newTestRevalidator returns a job checking users against the fake
+func newTestRevalidator(t *testing.T, users ...repo.User) (*APIKeyRevalidator, *gw2fake.Server, *fakeUsers, *fakeSessions) {
+	t.Helper()
+
+	fake := gw2fake.New()
+	srv := fake.Start()
+	t.Cleanup(srv.Close)
+
+	userRepo := &fakeUsers{due: users, valid: map[string]string{}}
+	sessions := &fakeSessions{revoked: map[string]string{}}
+	cnf := &config.Config{ApiKeyRevalidationHours: 24, ApiKeyRevalidationBatch: 50}
+	client := gw2api.New(gw2api.Options{BaseURL: srv.URL, RetryBackoff: time.Millisecond})
+
+	return NewAPIKeyRevalidator(cnf, userRepo, sessions, client), fake, userRepo, sessions
+}
+
+func addKey(fake *gw2fake.Server, user repo.User, permissions []string) {
+	fake.AddKey(user.ApiKey, gw2fake.Key{
+		Permissions: permissions,
+		Account:     gw2api.Account{ID: user.ID, Name: user.Name},
+	})
+}
+
+var (
+	player = repo.User{ID: "acc-1", Name: "Player.1234", ApiKey: "key-1"}
+	other  = repo.User{ID: "acc-2", Name: "Other.5678", ApiKey: "key-2"}
+)
+
+func TestRevalidateDueKeepsValidKeys(t *testing.T) {
+	job, fake, users, sessions := newTestRevalidator(t, player)
+	addKey(fake, player, gw2api.RequiredPermissions)
+
+	checked, err := job.RevalidateDue(context.Background())
+	if err != nil {
+		t.Fatalf("RevalidateDue: %v", err)
+	}
+	if checked != 1 {
+		t.Errorf("checked = %d, want 1", checked)
+	}
+	if users.valid[player.ID] != player.Name {
+		t.Errorf("MarkAPIKeyValid name = %q, want %q", users.valid[player.ID], player.Name)
+	}
+	if users.dueLimit != 50 {
+		t.Errorf("batch size = %d, want 50", users.dueLimit)
+	}
+	if len(users.invalid) > 0 || len(sessions.revoked) > 0 {
+		t.Errorf("valid key invalidated: %v, sessions revoked: %v", users.invalid, sessions.revoked)
+	}
+}
+
+func TestRevalidateDueRevokedKeys(t *testing.T) {
+	tests := []struct {
+		name        string
+		permissions []string // nil leaves the key unknown to GW2
+	}{
+		{"deleted key", nil},
+		{"key lost a permission", []string{"account"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			job, fake, users, sessions := newTestRevalidator(t, player)
+			if tt.permissions != nil {
+				addKey(fake, player, tt.permissions)
+			}
+
+			if _, err := job.RevalidateDue(context.Background()); err != nil {
+				t.Fatalf("RevalidateDue: %v", err)
+			}
+			if !slices.Equal(users.invalid, []string{player.ID}) {
+				t.Errorf("invalidated = %v, want [%s]", users.invalid, player.ID)
+			}
+			if sessions.revoked[player.ID] != repo.SessionRevokedKeyInvalid {
+				t.Errorf("sessions revoked with %q, want %q", sessions.revoked[player.ID], repo.SessionRevokedKeyInvalid)
+			}
+			if _, ok := users.valid[player.ID]; ok {
+				t.Error("revoked key marked valid")
+			}
+		})
+	}
+}
+
+func TestRevalidateDueAppliesRenames(t *testing.T) {
+	job, fake, users, _ := newTestRevalidator(t, player)
+	addKey(fake, player, gw2api.RequiredPermissions)
+	fake.RenameAccount(player.ID, "Renamed.4321")
+
+	if _, err := job.RevalidateDue(context.Background()); err != nil {
+		t.Fatalf("RevalidateDue: %v", err)
+	}
+	if users.valid[player.ID] != "Renamed.4321" {
+		t.Errorf("MarkAPIKeyValid name = %q, want the new account name", users.valid[player.ID])
+	}
+}
+
+func TestRevalidateDueStopsWhenUpstreamIsDown(t *testing.T) {
+	job, fake, users, sessions := newTestRevalidator(t, player, other)
+	addKey(fake, player, gw2api.RequiredPermissions)
+	addKey(fake, other, gw2api.RequiredPermissions)
+	fake.SetDown(true)
+
+	checked, err := job.RevalidateDue(context.Background())
+	if !gw2api.IsUpstreamError(err) {
+		t.Fatalf("err = %v, want an upstream error", err)
+	}
+	if checked != 0 {
+		t.Errorf("checked = %d, want 0", checked)
+	}
+	// An outage must not count as a check of any key
+	if len(users.valid) > 0 || len(users.invalid) > 0 || len(users.checked) > 0 || len(sessions.revoked) > 0 {
+		t.Errorf("keys updated during an outage: valid %v, invalid %v, checked %v, revoked %v",
+			users.valid, users.invalid, users.checked, sessions.revoked)
+	}
+
+	// Once GW2 is back the same keys are checked
+	fake.SetDown(false)
+	if checked, err := job.RevalidateDue(context.Background()); err != nil || checked != 2 {
+		t.Errorf("RevalidateDue after the outage = %d, %v, want 2, nil", checked, err)
+	}
+}
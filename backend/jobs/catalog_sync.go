@@ -0,0 +1,170 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+
+	"github.com/NesoHQ/gw2style/gw2api"
+	"github.com/NesoHQ/gw2style/repo"
+)
+
+var catalogPaths = map[repo.CatalogKind]string{
+	repo.CatalogSkins:      gw2api.SkinsPath,
+	repo.CatalogColors:     gw2api.ColorsPath,
+	repo.CatalogOutfits:    gw2api.OutfitsPath,
+	repo.CatalogGliders:    gw2api.GlidersPath,
+	repo.CatalogMountSkins: gw2api.MountSkinsPath,
+}
+
+// CatalogSync copies the GW2 skins, dyes, outfits, gliders and mount skins
+// into the local catalog. Cosmetics do not change once released, so later
+// runs only fetch the IDs the catalog does not have yet unless asked for a
+// full refresh.
+type CatalogSync struct {
+	catalog *repo.CatalogRepository
+	gw2     *gw2api.Client
+}
+
+// CatalogSyncResult is what one catalog sync did
+type CatalogSyncResult struct {
+	Kind    repo.CatalogKind `json:"kind"`
+	Total   int              `json:"total"`   // IDs GW2 knows
+	Fetched int              `json:"fetched"` // Entries fetched and stored by this run
+}
+
+func NewCatalogSync(catalog *repo.CatalogRepository, gw2 *gw2api.Client) *CatalogSync {
+	return &CatalogSync{
+		catalog: catalog,
+		gw2:     gw2,
+	}
+}
+
+// SyncKind syncs one catalog in batches of 200 IDs. Every batch is stored as
+// soon as it is fetched, so an interrupted run is picked up by the next one.
+func (j *CatalogSync) SyncKind(ctx context.Context, kind repo.CatalogKind, full bool) (CatalogSyncResult, error) {
+	result := CatalogSyncResult{Kind: kind}
+
+	ids, err := j.gw2.ListIDs(ctx, catalogPaths[kind])
+	if err != nil {
+		return result, fmt.Errorf("failed to list %s: %w", kind, err)
+	}
+	result.Total = len(ids)
+
+	if !full {
+		known, err := j.catalog.KnownIDs(ctx, kind)
+		if err != nil {
+			return result, err
+		}
+		stored := make(map[int]bool, len(known))
+		for _, id := range known {
+			stored[id] = true
+		}
+		ids = slices.DeleteFunc(ids, func(id int) bool { return stored[id] })
+	}
+
+	for batch := range slices.Chunk(ids, gw2api.MaxIDsPerRequest) {
+		fetched, err := j.syncBatch(ctx, kind, batch)
+		if err != nil {
+			return result, fmt.Errorf("failed to sync %s: %w", kind, err)
+		}
+		result.Fetched += fetched
+	}
+
+	slog.Info("Synced catalog", "kind", kind, "total", result.Total, "fetched", result.Fetched)
+	return result, nil
+}
+
+// syncBatch fetches one batch of IDs and stores it, returning how many GW2 knew
+func (j *CatalogSync) syncBatch(ctx context.Context, kind repo.CatalogKind, ids []int) (int, error) {
+	switch kind {
+	case repo.CatalogSkins:
+		skins, err := j.gw2.Skins(ctx, ids)
+		if err != nil {
+			return 0, err
+		}
+		entries := make([]repo.CatalogSkin, 0, len(skins))
+		for _, s := range skins {
+			entries = append(entries, repo.CatalogSkin{
+				ID:          s.ID,
+				Name:        s.Name,
+				Type:        s.Type,
+				Subtype:     s.Details.Type,
+				WeightClass: s.Details.WeightClass,
+				Rarity:      s.Rarity,
+				Icon:        s.Icon,
+				DyeChannels: s.DyeChannels(),
+			})
+		}
+		return len(entries), j.catalog.UpsertSkins(ctx, entries)
+
+	case repo.CatalogColors:
+		colors, err := j.gw2.Colors(ctx, ids)
+		if err != nil {
+			return 0, err
+		}
+		entries := make([]repo.CatalogColor, 0, len(colors))
+		for _, c := range colors {
+			entry := repo.CatalogColor{
+				ID:         c.ID,
+				Name:       c.Name,
+				ItemID:     c.Item,
+				BaseRGB:    int64s(c.BaseRGB),
+				ClothRGB:   int64s(c.Cloth.RGB),
+				LeatherRGB: int64s(c.Leather.RGB),
+				MetalRGB:   int64s(c.Metal.RGB),
+				FurRGB:     int64s(c.Fur.RGB),
+			}
+			// GW2 lists the hue, material and rarity, in that order
+			if len(c.Categories) == 3 {
+				entry.Hue, entry.Material, entry.Rarity = c.Categories[0], c.Categories[1], c.Categories[2]
+			}
+			entries = append(entries, entry)
+		}
+		return len(entries), j.catalog.UpsertColors(ctx, entries)
+
+	case repo.CatalogOutfits:
+		outfits, err := j.gw2.Outfits(ctx, ids)
+		if err != nil {
+			return 0, err
+		}
+		entries := make([]repo.CatalogOutfit, 0, len(outfits))
+		for _, o := range outfits {
+			entries = append(entries, repo.CatalogOutfit{ID: o.ID, Name: o.Name, Icon: o.Icon})
+		}
+		return len(entries), j.catalog.UpsertOutfits(ctx, entries)
+
+	case repo.CatalogGliders:
+		gliders, err := j.gw2.Gliders(ctx, ids)
+		if err != nil {
+			return 0, err
+		}
+		entries := make([]repo.CatalogGlider, 0, len(gliders))
+		for _, g := range gliders {
+			entries = append(entries, repo.CatalogGlider{ID: g.ID, Name: g.Name, Icon: g.Icon, DefaultDyes: int64s(g.DefaultDyes)})
+		}
+		return len(entries), j.catalog.UpsertGliders(ctx, entries)
+
+	case repo.CatalogMountSkins:
+		skins, err := j.gw2.MountSkins(ctx, ids)
+		if err != nil {
+			return 0, err
+		}
+		entries := make([]repo.CatalogMountSkin, 0, len(skins))
+		for _, m := range skins {
+			entries = append(entries, repo.CatalogMountSkin{ID: m.ID, Name: m.Name, Icon: m.Icon, Mount: m.Mount, DyeChannels: len(m.DyeSlots)})
+		}
+		return len(entries), j.catalog.UpsertMountSkins(ctx, entries)
+	}
+
+	return 0, fmt.Errorf("unknown catalog %q", kind)
+}
+
+func int64s(values []int) []int64 {
+	out := make([]int64, len(values))
+	for i, v := range values {
+		out[i] = int64(v)
+	}
+	return out
+}
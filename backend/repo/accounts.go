@@ -0,0 +1,371 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// What happens to a user's posts when they delete their account
+const (
+	DeletedPostsAnonymize = "anonymize"
+	DeletedPostsRemove    = "delete"
+)
+
+// DeletedUserName replaces a deleted user's name on post revisions they edited
+const DeletedUserName = "[deleted]"
+
+var ErrInvalidPostDisposition = errors.New(`posts must be "anonymize" or "delete"`)
+
+type AccountProfile struct {
+	ID                  string     `json:"id"`
+	Username            string     `json:"username"`
+	Role                string     `json:"role"`
+	CreatedAt           *time.Time `json:"created_at"`
+	ApiKeyPermissions   []string   `json:"api_key_permissions"`
+	ApiKeyValidatedAt   *time.Time `json:"api_key_validated_at"`
+	ApiKeyInvalidatedAt *time.Time `json:"api_key_invalidated_at"`
+}
+
+type ExportedPost struct {
+	ID          int             `json:"id"`
+	Title       string          `json:"title"`
+	Description string          `json:"description"`
+	Thumbnail   string          `json:"thumbnail"`
+	Images      []string        `json:"images"`
+	Equipments  json.RawMessage `json:"equipments"`
+	Tags        json.RawMessage `json:"tags"`
+	Status      PostStatus      `json:"status"`
+	LikesCount  int             `json:"likes_count"`
+	CreatedAt   string          `json:"created_at"`
+	UpdatedAt   string          `json:"updated_at,omitempty"`
+}
+
+type ExportedLike struct {
+	PostID    int    `json:"post_id"`
+	PostTitle string `json:"post_title"`
+	LikedAt   string `json:"liked_at"`
+}
+
+// ExportedModerationAction is a moderation action on one of the user's posts.
+// Like BanLogEntry and the exported reports, it leaves out the moderator.
+type ExportedModerationAction struct {
+	ID        int    `json:"id"`
+	PostID    int    `json:"post_id"`
+	ReportID  int    `json:"report_id,omitempty"`
+	Action    string `json:"action"`
+	Reason    string `json:"reason"`
+	CreatedAt string `json:"created_at"`
+}
+
+type BanLogEntry struct {
+	BanID     int    `json:"ban_id"`
+	Action    string `json:"action"`
+	Reason    string `json:"reason"`
+	ExpiresAt string `json:"expires_at,omitempty"`
+	CreatedAt string `json:"created_at"`
+}
+
+// AccountExport is everything stored about a user. The API key itself is left
+// out so the archive is safe to keep around.
+type AccountExport struct {
+	ExportedAt  time.Time                  `json:"exported_at"`
+	Profile     AccountProfile             `json:"profile"`
+	Posts       []ExportedPost             `json:"posts"`
+	Likes       []ExportedLike             `json:"likes"`
+	Reports     []Report                   `json:"reports"`    // Reports the user filed, with their outcome
+	Moderation  []ExportedModerationAction `json:"moderation"` // Moderation actions taken on the user's posts
+	Bans        []BanLogEntry              `json:"bans"`
+	RoleChanges []RoleChange               `json:"role_changes"`
+}
+
+// AccountDeletion summarizes what DeleteAccount removed
+type AccountDeletion struct {
+	PostsAnonymized int `json:"posts_anonymized"`
+	PostsDeleted    int `json:"posts_deleted"`
+	LikesRemoved    int `json:"likes_removed"`
+}
+
+type AccountRepository struct {
+	db *sql.DB
+}
+
+func NewAccountRepository(db *sql.DB) *AccountRepository {
+	return &AccountRepository{db: db}
+}
+
+// ExportAccount collects the user's profile, posts, likes, filed reports and
+// the moderation outcomes that concern them
+func (r *AccountRepository) ExportAccount(ctx context.Context, userID string) (*AccountExport, error) {
+	export := AccountExport{ExportedAt: time.Now().UTC()}
+
+	p := &export.Profile
+	err := r.db.QueryRowContext(ctx, `
+		SELECT id, username, role, created_at, api_key_permissions, api_key_validated_at, api_key_invalidated_at
+		FROM users WHERE id = $1`, userID).
+		Scan(&p.ID, &p.Username, &p.Role, &p.CreatedAt, pq.Array(&p.ApiKeyPermissions), &p.ApiKeyValidatedAt, &p.ApiKeyInvalidatedAt)
+	if err == sql.ErrNoRows {
+		return nil, ErrUserNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error getting profile: %w", err)
+	}
+
+	if export.Posts, err = r.exportPosts(ctx, p.Username); err != nil {
+		return nil, err
+	}
+	if export.Likes, err = r.exportLikes(ctx, userID); err != nil {
+		return nil, err
+	}
+	if export.Reports, err = r.exportReports(ctx, p.Username); err != nil {
+		return nil, err
+	}
+	if export.Moderation, err = r.exportModeration(ctx, p.Username); err != nil {
+		return nil, err
+	}
+	if export.Bans, err = r.exportBans(ctx, userID); err != nil {
+		return nil, err
+	}
+	if export.RoleChanges, err = NewRoleRepository(r.db).GetRoleChanges(ctx, userID, 1000, 0); err != nil {
+		return nil, err
+	}
+
+	return &export, nil
+}
+
+func (r *AccountRepository) exportPosts(ctx context.Context, username string) ([]ExportedPost, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, COALESCE(title, ''), COALESCE(description, ''), COALESCE(thumbnail_url, ''),
+			COALESCE(image1_url, ''), COALESCE(image2_url, ''), COALESCE(image3_url, ''),
+			COALESCE(image4_url, ''), COALESCE(image5_url, ''),
+			COALESCE(equipments::jsonb, 'null'::jsonb), COALESCE(tags, '[]'::jsonb), status,
+			COALESCE(likes_count, 0),
+			to_char(created_at, 'YYYY-MM-DD"T"HH24:MI:SS"Z"'),
+			COALESCE(to_char(updated_at, 'YYYY-MM-DD"T"HH24:MI:SS"Z"'), '')
+		FROM posts
+		WHERE author_name = $1
+		ORDER BY created_at, id`, username)
+	if err != nil {
+		return nil, fmt.Errorf("error exporting posts: %w", err)
+	}
+	defer rows.Close()
+
+	posts := []ExportedPost{}
+	for rows.Next() {
+		var post ExportedPost
+		images := make([]string, 5)
+		var equipments, tags []byte
+		err := rows.Scan(&post.ID, &post.Title, &post.Description, &post.Thumbnail,
+			&images[0], &images[1], &images[2], &images[3], &images[4],
+			&equipments, &tags, &post.Status, &post.LikesCount, &post.CreatedAt, &post.UpdatedAt)
+		if err != nil {
+			return nil, err
+		}
+
+		post.Equipments = json.RawMessage(equipments)
+		post.Tags = json.RawMessage(tags)
+		post.Images = []string{}
+		for _, image := range images {
+			if image != "" {
+				post.Images = append(post.Images, image)
+			}
+		}
+		posts = append(posts, post)
+	}
+
+	return posts, rows.Err()
+}
+
+func (r *AccountRepository) exportLikes(ctx context.Context, userID string) ([]ExportedLike, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT pl.post_id, COALESCE(p.title, ''), to_char(pl.created_at, 'YYYY-MM-DD"T"HH24:MI:SS"Z"')
+		FROM post_likes pl
+		JOIN posts p ON p.id = pl.post_id
+		WHERE pl.user_id = $1
+		ORDER BY pl.created_at`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("error exporting likes: %w", err)
+	}
+	defer rows.Close()
+
+	likes := []ExportedLike{}
+	for rows.Next() {
+		var like ExportedLike
+		if err := rows.Scan(&like.PostID, &like.PostTitle, &like.LikedAt); err != nil {
+			return nil, err
+		}
+		likes = append(likes, like)
+	}
+
+	return likes, rows.Err()
+}
+
+func (r *AccountRepository) exportReports(ctx context.Context, username string) ([]Report, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT r.id, r.post_id, COALESCE(p.title, ''), r.reporter_username, r.reason,
+			COALESCE(r.description, ''), r.status,
+			to_char(r.created_at, 'YYYY-MM-DD"T"HH24:MI:SS"Z"'),
+			COALESCE(to_char(r.resolved_at, 'YYYY-MM-DD"T"HH24:MI:SS"Z"'), '')
+		FROM reports r
+		JOIN posts p ON p.id = r.post_id
+		WHERE r.reporter_username = $1
+		ORDER BY r.created_at, r.id`, username)
+	if err != nil {
+		return nil, fmt.Errorf("error exporting reports: %w", err)
+	}
+	defer rows.Close()
+
+	reports := []Report{}
+	for rows.Next() {
+		var rep Report
+		err := rows.Scan(&rep.ID, &rep.PostID, &rep.PostTitle, &rep.ReporterUsername, &rep.Reason,
+			&rep.Description, &rep.Status, &rep.CreatedAt, &rep.ResolvedAt)
+		if err != nil {
+			return nil, err
+		}
+		reports = append(reports, rep)
+	}
+
+	return reports, rows.Err()
+}
+
+func (r *AccountRepository) exportModeration(ctx context.Context, username string) ([]ExportedModerationAction, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT ml.id, ml.post_id, COALESCE(ml.report_id, 0), ml.action, COALESCE(ml.reason, ''),
+			to_char(ml.created_at, 'YYYY-MM-DD"T"HH24:MI:SS"Z"')
+		FROM moderation_log ml
+		JOIN posts p ON p.id = ml.post_id
+		WHERE p.author_name = $1
+		ORDER BY ml.created_at, ml.id`, username)
+	if err != nil {
+		return nil, fmt.Errorf("error exporting moderation log: %w", err)
+	}
+	defer rows.Close()
+
+	logs := []ExportedModerationAction{}
+	for rows.Next() {
+		var l ExportedModerationAction
+		err := rows.Scan(&l.ID, &l.PostID, &l.ReportID, &l.Action, &l.Reason, &l.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		logs = append(logs, l)
+	}
+
+	return logs, rows.Err()
+}
+
+func (r *AccountRepository) exportBans(ctx context.Context, accountID string) ([]BanLogEntry, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT l.ban_id, l.action, COALESCE(l.reason, ''),
+			COALESCE(to_char(b.expires_at, 'YYYY-MM-DD"T"HH24:MI:SS"Z"'), ''),
+			to_char(l.created_at, 'YYYY-MM-DD"T"HH24:MI:SS"Z"')
+		FROM ban_log l
+		JOIN bans b ON b.id = l.ban_id
+		WHERE l.account_id = $1
+		ORDER BY l.created_at, l.id`, accountID)
+	if err != nil {
+		return nil, fmt.Errorf("error exporting bans: %w", err)
+	}
+	defer rows.Close()
+
+	bans := []BanLogEntry{}
+	for rows.Next() {
+		var b BanLogEntry
+		if err := rows.Scan(&b.BanID, &b.Action, &b.Reason, &b.ExpiresAt, &b.CreatedAt); err != nil {
+			return nil, err
+		}
+		bans = append(bans, b)
+	}
+
+	return bans, rows.Err()
+}
+
+// DeleteAccount erases the user and their stored API key in one transaction.
+// Their likes are removed and the like counts of those posts lowered; their
+// posts are either kept without an author or removed, as chosen by posts.
+// Reports they filed stay for moderators but no longer name them. Bans are
+// keyed by account ID and survive, so deleting an account does not lift one.
+func (r *AccountRepository) DeleteAccount(ctx context.Context, userID, posts string) (*AccountDeletion, error) {
+	if posts != DeletedPostsAnonymize && posts != DeletedPostsRemove {
+		return nil, ErrInvalidPostDisposition
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var username string
+	err = tx.QueryRowContext(ctx, `SELECT username FROM users WHERE id = $1 FOR UPDATE`, userID).Scan(&username)
+	if err == sql.ErrNoRows {
+		return nil, ErrUserNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error getting user: %w", err)
+	}
+
+	var deletion AccountDeletion
+
+	// Lower the counts before the likes go; ON DELETE CASCADE would leave them stale
+	result, err := tx.ExecContext(ctx, `
+		UPDATE posts p
+		SET likes_count = GREATEST(COALESCE(p.likes_count, 0) - 1, 0)
+		FROM post_likes pl
+		WHERE pl.post_id = p.id AND pl.user_id = $1`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("error updating like counts: %w", err)
+	}
+	if _, err = tx.ExecContext(ctx, `DELETE FROM post_likes WHERE user_id = $1`, userID); err != nil {
+		return nil, fmt.Errorf("error deleting likes: %w", err)
+	}
+	deletion.LikesRemoved = rowsAffected(result)
+
+	if posts == DeletedPostsRemove {
+		// Reports, revisions, moderation entries and other users' likes cascade
+		result, err = tx.ExecContext(ctx, `DELETE FROM posts WHERE author_name = $1`, username)
+		if err != nil {
+			return nil, fmt.Errorf("error deleting posts: %w", err)
+		}
+		deletion.PostsDeleted = rowsAffected(result)
+	} else {
+		result, err = tx.ExecContext(ctx, `UPDATE posts SET author_name = NULL WHERE author_name = $1`, username)
+		if err != nil {
+			return nil, fmt.Errorf("error anonymizing posts: %w", err)
+		}
+		deletion.PostsAnonymized = rowsAffected(result)
+	}
+
+	_, err = tx.ExecContext(ctx, `UPDATE post_revisions SET edited_by = $2 WHERE edited_by = $1`, username, DeletedUserName)
+	if err != nil {
+		return nil, fmt.Errorf("error anonymizing revisions: %w", err)
+	}
+
+	// Each report gets its own placeholder to keep (post_id, reporter_username) unique
+	_, err = tx.ExecContext(ctx, `UPDATE reports SET reporter_username = 'deleted-user-' || id WHERE reporter_username = $1`, username)
+	if err != nil {
+		return nil, fmt.Errorf("error anonymizing reports: %w", err)
+	}
+
+	// Sessions, refresh tokens and role history cascade
+	if _, err = tx.ExecContext(ctx, `DELETE FROM users WHERE id = $1`, userID); err != nil {
+		return nil, fmt.Errorf("error deleting user: %w", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("error committing transaction: %w", err)
+	}
+
+	return &deletion, nil
+}
+
+func rowsAffected(result sql.Result) int {
+	n, _ := result.RowsAffected()
+	return int(n)
+}
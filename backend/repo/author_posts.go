@@ -0,0 +1,122 @@
+package repo
+
+import (
+	"context"
+	"fmt"
+)
+
+type AuthorPost struct {
+	ID               string     `json:"id"`
+	Title            string     `json:"title"`
+	Thumbnail        string     `json:"thumbnail"`
+	Status           PostStatus `json:"status"`
+	LikesCount       int        `json:"likes_count"`
+	CreatedAt        string     `json:"created_at"`
+	UpdatedAt        string     `json:"updated_at,omitempty"`
+	LastAction       string     `json:"last_action,omitempty"`
+	LastActionReason string     `json:"last_action_reason,omitempty"`
+	LastActionAt     string     `json:"last_action_at,omitempty"`
+}
+
+type AuthorPostStats struct {
+	ByStatus   map[PostStatus]int `json:"by_status"`
+	TotalPosts int                `json:"total_posts"`
+	TotalLikes int                `json:"total_likes"`
+}
+
+// GetAuthorPosts returns an author's posts in every status, newest first, with
+// the latest moderation action taken on each. An empty status returns all posts.
+func (r *PostRepository) GetAuthorPosts(ctx context.Context, authorName string, status PostStatus, limit, offset int) ([]AuthorPost, int, error) {
+	var totalCount int
+	countQuery := `SELECT COUNT(*) FROM posts WHERE author_name = $1 AND ($2 = '' OR status = $2)`
+	err := r.db.QueryRowContext(ctx, countQuery, authorName, status).Scan(&totalCount)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error getting total count: %w", err)
+	}
+
+	query := `
+		SELECT
+			CAST(p.id AS TEXT),
+			COALESCE(p.title, '') as title,
+			COALESCE(p.thumbnail_url, '') as thumbnail,
+			p.status,
+			COALESCE(p.likes_count, 0) as likes_count,
+			to_char(COALESCE(p.created_at, NOW()), 'YYYY-MM-DD"T"HH24:MI:SS"Z"') as created_at,
+			COALESCE(to_char(p.updated_at, 'YYYY-MM-DD"T"HH24:MI:SS"Z"'), '') as updated_at,
+			COALESCE(ml.action, '') as last_action,
+			COALESCE(ml.reason, '') as last_action_reason,
+			COALESCE(to_char(ml.created_at, 'YYYY-MM-DD"T"HH24:MI:SS"Z"'), '') as last_action_at
+		FROM posts p
+		LEFT JOIN LATERAL (
+			SELECT action, reason, created_at
+			FROM moderation_log
+			WHERE post_id = p.id
+			ORDER BY created_at DESC, id DESC
+			LIMIT 1
+		) ml ON true
+		WHERE p.author_name = $1 AND ($2 = '' OR p.status = $2)
+		ORDER BY p.created_at DESC, p.id DESC
+		LIMIT $3 OFFSET $4`
+
+	rows, err := r.db.QueryContext(ctx, query, authorName, status, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	posts := []AuthorPost{}
+	for rows.Next() {
+		var post AuthorPost
+		err := rows.Scan(
+			&post.ID,
+			&post.Title,
+			&post.Thumbnail,
+			&post.Status,
+			&post.LikesCount,
+			&post.CreatedAt,
+			&post.UpdatedAt,
+			&post.LastAction,
+			&post.LastActionReason,
+			&post.LastActionAt,
+		)
+		if err != nil {
+			return nil, 0, err
+		}
+		posts = append(posts, post)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	return posts, totalCount, nil
+}
+
+// GetAuthorPostStats counts an author's posts per status and sums their likes
+func (r *PostRepository) GetAuthorPostStats(ctx context.Context, authorName string) (*AuthorPostStats, error) {
+	query := `
+		SELECT status, COUNT(*), COALESCE(SUM(likes_count), 0)
+		FROM posts
+		WHERE author_name = $1
+		GROUP BY status`
+
+	rows, err := r.db.QueryContext(ctx, query, authorName)
+	if err != nil {
+		return nil, fmt.Errorf("error getting author stats: %w", err)
+	}
+	defer rows.Close()
+
+	stats := &AuthorPostStats{ByStatus: map[PostStatus]int{}}
+	for rows.Next() {
+		var status PostStatus
+		var count, likes int
+		if err := rows.Scan(&status, &count, &likes); err != nil {
+			return nil, err
+		}
+		stats.ByStatus[status] = count
+		stats.TotalPosts += count
+		stats.TotalLikes += likes
+	}
+
+	return stats, rows.Err()
+}
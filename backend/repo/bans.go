@@ -0,0 +1,219 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrBanNotFound = errors.New("no active ban for this account")
+	// ErrUnknownAccount means an account is neither a registered account name nor a GW2 account ID
+	ErrUnknownAccount = errors.New("unknown account")
+)
+
+type Ban struct {
+	ID                 int        `json:"id"`
+	AccountID          string     `json:"account_id"`
+	Username           string     `json:"username,omitempty"`
+	Reason             string     `json:"reason"`
+	ExpiresAt          *time.Time `json:"expires_at"` // nil for permanent bans
+	ModeratorUsername  string     `json:"moderator_username"`
+	ModeratorDiscordID string     `json:"moderator_discord_id,omitempty"`
+	CreatedAt          time.Time  `json:"created_at"`
+}
+
+// Permanent reports whether the ban never expires
+func (b *Ban) Permanent() bool {
+	return b.ExpiresAt == nil
+}
+
+// BanNotice is the part of a ban shown to the banned user. It leaves out the
+// moderator so they are not exposed to the person they banned.
+type BanNotice struct {
+	Reason    string     `json:"reason"`
+	ExpiresAt *time.Time `json:"expires_at"` // nil for permanent bans
+}
+
+// Notice returns the ban as sent to the banned user
+func (b *Ban) Notice() BanNotice {
+	return BanNotice{Reason: b.Reason, ExpiresAt: b.ExpiresAt}
+}
+
+// Message is the error shown to the banned user
+func (b *Ban) Message() string {
+	if b.Permanent() {
+		return "your account is banned: " + b.Reason
+	}
+	return fmt.Sprintf("your account is suspended until %s: %s", b.ExpiresAt.UTC().Format(time.RFC3339), b.Reason)
+}
+
+type BanRepository struct {
+	db *sql.DB
+}
+
+func NewBanRepository(db *sql.DB) *BanRepository {
+	return &BanRepository{db: db}
+}
+
+const banColumns = `b.id, b.account_id, COALESCE(u.username, ''), b.reason, b.expires_at,
+	b.moderator_username, COALESCE(b.moderator_discord_id, ''), b.created_at`
+
+// activeBan matches bans that are neither lifted nor expired
+const activeBan = `b.lifted_at IS NULL AND (b.expires_at IS NULL OR b.expires_at > NOW())`
+
+func scanBan(row interface{ Scan(...any) error }) (*Ban, error) {
+	var b Ban
+	err := row.Scan(&b.ID, &b.AccountID, &b.Username, &b.Reason, &b.ExpiresAt, &b.ModeratorUsername, &b.ModeratorDiscordID, &b.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &b, nil
+}
+
+// GetActiveBan returns the account's active ban, or nil when it is not banned
+func (r *BanRepository) GetActiveBan(ctx context.Context, accountID string) (*Ban, error) {
+	query := `
+		SELECT ` + banColumns + `
+		FROM bans b
+		LEFT JOIN users u ON u.id = b.account_id
+		WHERE b.account_id = $1 AND ` + activeBan
+	ban, err := scanBan(r.db.QueryRowContext(ctx, query, accountID))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error getting ban: %w", err)
+	}
+	return ban, nil
+}
+
+// GetActiveBans lists active bans, newest first
+func (r *BanRepository) GetActiveBans(ctx context.Context, limit, offset int) ([]Ban, error) {
+	query := `
+		SELECT ` + banColumns + `
+		FROM bans b
+		LEFT JOIN users u ON u.id = b.account_id
+		WHERE ` + activeBan + `
+		ORDER BY b.created_at DESC, b.id DESC
+		LIMIT $1 OFFSET $2`
+
+	rows, err := r.db.QueryContext(ctx, query, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("error getting bans: %w", err)
+	}
+	defer rows.Close()
+
+	bans := []Ban{}
+	for rows.Next() {
+		ban, err := scanBan(rows)
+		if err != nil {
+			return nil, err
+		}
+		bans = append(bans, *ban)
+	}
+
+	return bans, rows.Err()
+}
+
+// CreateBan bans an account, replacing any ban it already has, and logs the
+// action. A nil expiresAt bans permanently.
+func (r *BanRepository) CreateBan(ctx context.Context, ban Ban) (*Ban, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Close the previous ban, active or expired, so the new one takes its place
+	_, err = tx.ExecContext(ctx,
+		`UPDATE bans SET lifted_at = NOW(), lifted_by = $2 WHERE account_id = $1 AND lifted_at IS NULL`,
+		ban.AccountID, ban.ModeratorUsername)
+	if err != nil {
+		return nil, fmt.Errorf("error replacing ban: %w", err)
+	}
+
+	query := `
+		INSERT INTO bans (account_id, reason, expires_at, moderator_username, moderator_discord_id)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at`
+	err = tx.QueryRowContext(ctx, query, ban.AccountID, ban.Reason, ban.ExpiresAt, ban.ModeratorUsername, ban.ModeratorDiscordID).
+		Scan(&ban.ID, &ban.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("error creating ban: %w", err)
+	}
+
+	if err = logBanAction(ctx, tx, ban.ID, ban.AccountID, "banned", ban.ModeratorUsername, ban.ModeratorDiscordID, ban.Reason); err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("error committing transaction: %w", err)
+	}
+
+	return &ban, nil
+}
+
+// LiftBan ends the account's active ban early and logs the action
+func (r *BanRepository) LiftBan(ctx context.Context, accountID, moderatorUsername, moderatorDiscordID, reason string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var banID int
+	query := `
+		UPDATE bans b SET lifted_at = NOW(), lifted_by = $2
+		WHERE b.account_id = $1 AND ` + activeBan + `
+		RETURNING b.id`
+	err = tx.QueryRowContext(ctx, query, accountID, moderatorUsername).Scan(&banID)
+	if err == sql.ErrNoRows {
+		return ErrBanNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("error lifting ban: %w", err)
+	}
+
+	if err = logBanAction(ctx, tx, banID, accountID, "lifted", moderatorUsername, moderatorDiscordID, reason); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// ResolveAccountID maps a GW2 account name ("Player.1234") of a registered
+// user to its account ID. A GW2 account ID is returned as is, so accounts can
+// be banned before they register. Anything else is ErrUnknownAccount.
+func (r *BanRepository) ResolveAccountID(ctx context.Context, account string) (string, error) {
+	var id string
+	err := r.db.QueryRowContext(ctx, `SELECT id FROM users WHERE username = $1`, account).Scan(&id)
+	if err == nil {
+		return id, nil
+	}
+	if err != sql.ErrNoRows {
+		return "", fmt.Errorf("error resolving account: %w", err)
+	}
+
+	// GW2 account IDs are UUIDs written in upper case
+	if _, err := uuid.Parse(account); err == nil && len(account) == len(uuid.Nil.String()) {
+		return strings.ToUpper(account), nil
+	}
+	return "", ErrUnknownAccount
+}
+
+func logBanAction(ctx context.Context, tx *sql.Tx, banID int, accountID, action, moderatorUsername, moderatorDiscordID, reason string) error {
+	_, err := tx.ExecContext(ctx,
+		`INSERT INTO ban_log (ban_id, account_id, action, moderator_username, moderator_discord_id, reason)
+		 VALUES ($1, $2, $3, $4, $5, $6)`,
+		banID, accountID, action, moderatorUsername, moderatorDiscordID, reason)
+	if err != nil {
+		return fmt.Errorf("error logging ban action: %w", err)
+	}
+	return nil
+}
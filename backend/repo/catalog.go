@@ -0,0 +1,372 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/lib/pq"
+
+	"github.com/NesoHQ/gw2style/equipment"
+)
+
+// CatalogKind names one of the GW2 cosmetics catalogs
+type CatalogKind string
+
+const (
+	CatalogSkins      CatalogKind = "skins"
+	CatalogColors     CatalogKind = "colors"
+	CatalogOutfits    CatalogKind = "outfits"
+	CatalogGliders    CatalogKind = "gliders"
+	CatalogMountSkins CatalogKind = "mount_skins"
+)
+
+// CatalogKinds lists every catalog in the order they are synced
+var CatalogKinds = []CatalogKind{CatalogSkins, CatalogColors, CatalogOutfits, CatalogGliders, CatalogMountSkins}
+
+var catalogTables = map[CatalogKind]string{
+	CatalogSkins:      "catalog_skins",
+	CatalogColors:     "catalog_colors",
+	CatalogOutfits:    "catalog_outfits",
+	CatalogGliders:    "catalog_gliders",
+	CatalogMountSkins: "catalog_mount_skins",
+}
+
+// catalogFilters are the exact-match filters of each catalog, keyed by query parameter
+var catalogFilters = map[CatalogKind]map[string]string{
+	CatalogSkins:      {"type": "type", "subtype": "subtype", "weight_class": "weight_class", "rarity": "rarity"},
+	CatalogColors:     {"hue": "hue", "material": "material", "rarity": "rarity"},
+	CatalogMountSkins: {"mount": "mount"},
+}
+
+// CatalogFilters returns the filter parameters a catalog can be narrowed by
+func CatalogFilters(kind CatalogKind) []string {
+	var params []string
+	for param := range catalogFilters[kind] {
+		params = append(params, param)
+	}
+	return params
+}
+
+type CatalogSkin struct {
+	ID          int    `json:"id"`
+	Name        string `json:"name"`
+	Type        string `json:"type"`
+	Subtype     string `json:"subtype,omitempty"`
+	WeightClass string `json:"weight_class,omitempty"`
+	Rarity      string `json:"rarity,omitempty"`
+	Icon        string `json:"icon,omitempty"`
+	DyeChannels []bool `json:"dye_channels"`
+}
+
+type CatalogColor struct {
+	ID         int     `json:"id"`
+	Name       string  `json:"name"`
+	Hue        string  `json:"hue,omitempty"`
+	Material   string  `json:"material,omitempty"`
+	Rarity     string  `json:"rarity,omitempty"`
+	ItemID     int     `json:"item_id,omitempty"`
+	BaseRGB    []int64 `json:"base_rgb"`
+	ClothRGB   []int64 `json:"cloth_rgb"`
+	LeatherRGB []int64 `json:"leather_rgb"`
+	MetalRGB   []int64 `json:"metal_rgb"`
+	FurRGB     []int64 `json:"fur_rgb"`
+}
+
+type CatalogOutfit struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+	Icon string `json:"icon,omitempty"`
+}
+
+type CatalogGlider struct {
+	ID          int     `json:"id"`
+	Name        string  `json:"name"`
+	Icon        string  `json:"icon,omitempty"`
+	DefaultDyes []int64 `json:"default_dyes"`
+}
+
+type CatalogMountSkin struct {
+	ID          int    `json:"id"`
+	Name        string `json:"name"`
+	Icon        string `json:"icon,omitempty"`
+	Mount       string `json:"mount"`
+	DyeChannels int    `json:"dye_channels"`
+}
+
+// CatalogQuery selects one page of a catalog listing
+type CatalogQuery struct {
+	Name    string            // Matches names containing it, ignoring case
+	IDs     []int             // Only these entries when set
+	Filters map[string]string // Exact matches ignoring case, see CatalogFilters
+	Limit   int
+	Offset  int
+}
+
+type CatalogRepository struct {
+	db *sql.DB
+	// Looked up for IDs missing from the catalog, see WithFallback
+	fallback equipment.Catalog
+}
+
+func NewCatalogRepository(db *sql.DB) *CatalogRepository {
+	return &CatalogRepository{
+		db: db,
+	}
+}
+
+// KnownIDs returns the IDs already stored in a catalog
+func (r *CatalogRepository) KnownIDs(ctx context.Context, kind CatalogKind) ([]int, error) {
+	rows, err := r.db.QueryContext(ctx, "SELECT id FROM "+catalogTables[kind])
+	if err != nil {
+		return nil, fmt.Errorf("error getting %s IDs: %w", kind, err)
+	}
+	defer rows.Close()
+
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// UpsertSkins inserts skins, replacing the stored copy of known ones
+func (r *CatalogRepository) UpsertSkins(ctx context.Context, skins []CatalogSkin) error {
+	return r.upsert(ctx, `
+		INSERT INTO catalog_skins (id, name, type, subtype, weight_class, rarity, icon, dye_channels, synced_at)
+		VALUES ($1, $2, $3, NULLIF($4, ''), NULLIF($5, ''), NULLIF($6, ''), NULLIF($7, ''), $8, NOW())
+		ON CONFLICT (id) DO UPDATE SET
+			name = EXCLUDED.name, type = EXCLUDED.type, subtype = EXCLUDED.subtype,
+			weight_class = EXCLUDED.weight_class, rarity = EXCLUDED.rarity, icon = EXCLUDED.icon,
+			dye_channels = EXCLUDED.dye_channels, synced_at = NOW()`,
+		len(skins), func(i int) []interface{} {
+			s := skins[i]
+			return []interface{}{s.ID, s.Name, s.Type, s.Subtype, s.WeightClass, s.Rarity, s.Icon, pq.Array(emptyIfNil(s.DyeChannels))}
+		})
+}
+
+// UpsertColors inserts dyes, replacing the stored copy of known ones
+func (r *CatalogRepository) UpsertColors(ctx context.Context, colors []CatalogColor) error {
+	return r.upsert(ctx, `
+		INSERT INTO catalog_colors (id, name, hue, material, rarity, item_id, base_rgb, cloth_rgb, leather_rgb, metal_rgb, fur_rgb, synced_at)
+		VALUES ($1, $2, NULLIF($3, ''), NULLIF($4, ''), NULLIF($5, ''), NULLIF($6, 0), $7, $8, $9, $10, $11, NOW())
+		ON CONFLICT (id) DO UPDATE SET
+			name = EXCLUDED.name, hue = EXCLUDED.hue, material = EXCLUDED.material, rarity = EXCLUDED.rarity,
+			item_id = EXCLUDED.item_id, base_rgb = EXCLUDED.base_rgb, cloth_rgb = EXCLUDED.cloth_rgb,
+			leather_rgb = EXCLUDED.leather_rgb, metal_rgb = EXCLUDED.metal_rgb, fur_rgb = EXCLUDED.fur_rgb,
+			synced_at = NOW()`,
+		len(colors), func(i int) []interface{} {
+			c := colors[i]
+			return []interface{}{c.ID, c.Name, c.Hue, c.Material, c.Rarity, c.ItemID,
+				pq.Array(emptyIfNil(c.BaseRGB)), pq.Array(emptyIfNil(c.ClothRGB)), pq.Array(emptyIfNil(c.LeatherRGB)), pq.Array(emptyIfNil(c.MetalRGB)), pq.Array(emptyIfNil(c.FurRGB))}
+		})
+}
+
+// UpsertOutfits inserts outfits, replacing the stored copy of known ones
+func (r *CatalogRepository) UpsertOutfits(ctx context.Context, outfits []CatalogOutfit) error {
+	return r.upsert(ctx, `
+		INSERT INTO catalog_outfits (id, name, icon, synced_at)
+		VALUES ($1, $2, NULLIF($3, ''), NOW())
+		ON CONFLICT (id) DO UPDATE SET name = EXCLUDED.name, icon = EXCLUDED.icon, synced_at = NOW()`,
+		len(outfits), func(i int) []interface{} {
+			o := outfits[i]
+			return []interface{}{o.ID, o.Name, o.Icon}
+		})
+}
+
+// UpsertGliders inserts gliders, replacing the stored copy of known ones
+func (r *CatalogRepository) UpsertGliders(ctx context.Context, gliders []CatalogGlider) error {
+	return r.upsert(ctx, `
+		INSERT INTO catalog_gliders (id, name, icon, default_dyes, synced_at)
+		VALUES ($1, $2, NULLIF($3, ''), $4, NOW())
+		ON CONFLICT (id) DO UPDATE SET
+			name = EXCLUDED.name, icon = EXCLUDED.icon, default_dyes = EXCLUDED.default_dyes, synced_at = NOW()`,
+		len(gliders), func(i int) []interface{} {
+			g := gliders[i]
+			return []interface{}{g.ID, g.Name, g.Icon, pq.Array(emptyIfNil(g.DefaultDyes))}
+		})
+}
+
+// UpsertMountSkins inserts mount skins, replacing the stored copy of known ones
+func (r *CatalogRepository) UpsertMountSkins(ctx context.Context, skins []CatalogMountSkin) error {
+	return r.upsert(ctx, `
+		INSERT INTO catalog_mount_skins (id, name, icon, mount, dye_channels, synced_at)
+		VALUES ($1, $2, NULLIF($3, ''), $4, $5, NOW())
+		ON CONFLICT (id) DO UPDATE SET
+			name = EXCLUDED.name, icon = EXCLUDED.icon, mount = EXCLUDED.mount,
+			dye_channels = EXCLUDED.dye_channels, synced_at = NOW()`,
+		len(skins), func(i int) []interface{} {
+			m := skins[i]
+			return []interface{}{m.ID, m.Name, m.Icon, m.Mount, m.DyeChannels}
+		})
+}
+
+// upsert runs query once per row in a single transaction
+func (r *CatalogRepository) upsert(ctx context.Context, query string, n int, args func(i int) []interface{}) error {
+	if n == 0 {
+		return nil
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.PrepareContext(ctx, query)
+	if err != nil {
+		return fmt.Errorf("error preparing upsert: %w", err)
+	}
+	defer stmt.Close()
+
+	for i := 0; i < n; i++ {
+		if _, err := stmt.ExecContext(ctx, args(i)...); err != nil {
+			return fmt.Errorf("error upserting catalog entry: %w", err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// ListSkins returns one page of skins and how many match in total
+func (r *CatalogRepository) ListSkins(ctx context.Context, q CatalogQuery) ([]CatalogSkin, int, error) {
+	skins := []CatalogSkin{}
+	total, err := r.list(ctx, CatalogSkins, `id, name, type, COALESCE(subtype, ''), COALESCE(weight_class, ''),
+		COALESCE(rarity, ''), COALESCE(icon, ''), dye_channels`, q, func(rows *sql.Rows) error {
+		var s CatalogSkin
+		if err := rows.Scan(&s.ID, &s.Name, &s.Type, &s.Subtype, &s.WeightClass, &s.Rarity, &s.Icon, pq.Array(&s.DyeChannels)); err != nil {
+			return err
+		}
+		skins = append(skins, s)
+		return nil
+	})
+	return skins, total, err
+}
+
+// ListColors returns one page of dyes and how many match in total
+func (r *CatalogRepository) ListColors(ctx context.Context, q CatalogQuery) ([]CatalogColor, int, error) {
+	colors := []CatalogColor{}
+	total, err := r.list(ctx, CatalogColors, `id, name, COALESCE(hue, ''), COALESCE(material, ''), COALESCE(rarity, ''),
+		COALESCE(item_id, 0), base_rgb, cloth_rgb, leather_rgb, metal_rgb, fur_rgb`, q, func(rows *sql.Rows) error {
+		var c CatalogColor
+		if err := rows.Scan(&c.ID, &c.Name, &c.Hue, &c.Material, &c.Rarity, &c.ItemID, pq.Array(&c.BaseRGB),
+			pq.Array(&c.ClothRGB), pq.Array(&c.LeatherRGB), pq.Array(&c.MetalRGB), pq.Array(&c.FurRGB)); err != nil {
+			return err
+		}
+		colors = append(colors, c)
+		return nil
+	})
+	return colors, total, err
+}
+
+// ListOutfits returns one page of outfits and how many match in total
+func (r *CatalogRepository) ListOutfits(ctx context.Context, q CatalogQuery) ([]CatalogOutfit, int, error) {
+	outfits := []CatalogOutfit{}
+	total, err := r.list(ctx, CatalogOutfits, `id, name, COALESCE(icon, '')`, q, func(rows *sql.Rows) error {
+		var o CatalogOutfit
+		if err := rows.Scan(&o.ID, &o.Name, &o.Icon); err != nil {
+			return err
+		}
+		outfits = append(outfits, o)
+		return nil
+	})
+	return outfits, total, err
+}
+
+// ListGliders returns one page of gliders and how many match in total
+func (r *CatalogRepository) ListGliders(ctx context.Context, q CatalogQuery) ([]CatalogGlider, int, error) {
+	gliders := []CatalogGlider{}
+	total, err := r.list(ctx, CatalogGliders, `id, name, COALESCE(icon, ''), default_dyes`, q, func(rows *sql.Rows) error {
+		var g CatalogGlider
+		if err := rows.Scan(&g.ID, &g.Name, &g.Icon, pq.Array(&g.DefaultDyes)); err != nil {
+			return err
+		}
+		gliders = append(gliders, g)
+		return nil
+	})
+	return gliders, total, err
+}
+
+// ListMountSkins returns one page of mount skins and how many match in total
+func (r *CatalogRepository) ListMountSkins(ctx context.Context, q CatalogQuery) ([]CatalogMountSkin, int, error) {
+	skins := []CatalogMountSkin{}
+	total, err := r.list(ctx, CatalogMountSkins, `id, name, COALESCE(icon, ''), mount, dye_channels`, q, func(rows *sql.Rows) error {
+		var m CatalogMountSkin
+		if err := rows.Scan(&m.ID, &m.Name, &m.Icon, &m.Mount, &m.DyeChannels); err != nil {
+			return err
+		}
+		skins = append(skins, m)
+		return nil
+	})
+	return skins, total, err
+}
+
+// list runs a catalog listing, calling scan for every row. Entries without a
+// name are left out; with a name query, names starting with it come first.
+func (r *CatalogRepository) list(ctx context.Context, kind CatalogKind, columns string, q CatalogQuery, scan func(*sql.Rows) error) (int, error) {
+	table := catalogTables[kind]
+	conditions := []string{"name <> ''"}
+	var args []interface{}
+
+	if q.Name != "" {
+		args = append(args, escapeLike(q.Name))
+		conditions = append(conditions, fmt.Sprintf("name ILIKE '%%' || $%d || '%%'", len(args)))
+	}
+	if len(q.IDs) > 0 {
+		args = append(args, pq.Array(q.IDs))
+		conditions = append(conditions, fmt.Sprintf("id = ANY($%d)", len(args)))
+	}
+	for param, value := range q.Filters {
+		column, ok := catalogFilters[kind][param]
+		if !ok || value == "" {
+			continue
+		}
+		args = append(args, value)
+		conditions = append(conditions, fmt.Sprintf("lower(%s) = lower($%d)", column, len(args)))
+	}
+	where := " WHERE " + strings.Join(conditions, " AND ")
+
+	var total int
+	if err := r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM "+table+where, args...).Scan(&total); err != nil {
+		return 0, fmt.Errorf("error counting %s: %w", kind, err)
+	}
+
+	order := " ORDER BY name, id"
+	if q.Name != "" {
+		order = " ORDER BY name ILIKE $1 || '%' DESC, name, id"
+	}
+
+	args = append(args, q.Limit, q.Offset)
+	query := fmt.Sprintf("SELECT %s FROM %s%s%s LIMIT $%d OFFSET $%d", columns, table, where, order, len(args)-1, len(args))
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return 0, fmt.Errorf("error listing %s: %w", kind, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		if err := scan(rows); err != nil {
+			return 0, err
+		}
+	}
+	return total, rows.Err()
+}
+
+// emptyIfNil stores nil slices as empty arrays, which pq would send as NULL
+func emptyIfNil[T any](s []T) []T {
+	if s == nil {
+		return []T{}
+	}
+	return s
+}
+
+// escapeLike makes s match itself literally inside a LIKE pattern
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
@@ -0,0 +1,193 @@
+package repo
+
+import (
+	"context"
+
+	"github.com/NesoHQ/gw2style/equipment"
+	"github.com/NesoHQ/gw2style/gw2api"
+)
+
+// The lookups below make CatalogRepository an equipment.Catalog, so posts are
+// validated and characters imported from the local catalog. IDs the catalog
+// lacks, e.g. cosmetics released since the last `catalog sync`, are looked up
+// in the fallback catalog when one is set.
+var _ equipment.Catalog = (*CatalogRepository)(nil)
+
+// WithFallback returns a copy of the repository that looks IDs missing from
+// the catalog up in fallback, usually the GW2 API client
+func (r *CatalogRepository) WithFallback(fallback equipment.Catalog) *CatalogRepository {
+	withFallback := *r
+	withFallback.fallback = fallback
+	return &withFallback
+}
+
+func (r *CatalogRepository) Skins(ctx context.Context, ids []int) (map[int]gw2api.Skin, error) {
+	return lookupCatalog(ctx, ids, r.storedSkins, r.fallback, equipment.Catalog.Skins)
+}
+
+func (r *CatalogRepository) Colors(ctx context.Context, ids []int) (map[int]gw2api.Color, error) {
+	return lookupCatalog(ctx, ids, r.storedColors, r.fallback, equipment.Catalog.Colors)
+}
+
+func (r *CatalogRepository) Outfits(ctx context.Context, ids []int) (map[int]gw2api.Outfit, error) {
+	return lookupCatalog(ctx, ids, r.storedOutfits, r.fallback, equipment.Catalog.Outfits)
+}
+
+func (r *CatalogRepository) Gliders(ctx context.Context, ids []int) (map[int]gw2api.Glider, error) {
+	return lookupCatalog(ctx, ids, r.storedGliders, r.fallback, equipment.Catalog.Gliders)
+}
+
+func (r *CatalogRepository) MountSkins(ctx context.Context, ids []int) (map[int]gw2api.MountSkin, error) {
+	return lookupCatalog(ctx, ids, r.storedMountSkins, r.fallback, equipment.Catalog.MountSkins)
+}
+
+// lookupCatalog looks ids up with stored, then the IDs it did not find in fallback
+func lookupCatalog[T any](
+	ctx context.Context,
+	ids []int,
+	stored func(context.Context, []int) (map[int]T, error),
+	fallback equipment.Catalog,
+	fetch func(equipment.Catalog, context.Context, []int) (map[int]T, error),
+) (map[int]T, error) {
+	ids = uniqueIDs(ids)
+	if len(ids) == 0 {
+		return map[int]T{}, nil
+	}
+
+	found, err := stored(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	var missing []int
+	for _, id := range ids {
+		if _, ok := found[id]; !ok {
+			missing = append(missing, id)
+		}
+	}
+	if len(missing) == 0 || fallback == nil {
+		return found, nil
+	}
+
+	fetched, err := fetch(fallback, ctx, missing)
+	if err != nil {
+		return nil, err
+	}
+	for id, entry := range fetched {
+		found[id] = entry
+	}
+	return found, nil
+}
+
+func (r *CatalogRepository) storedSkins(ctx context.Context, ids []int) (map[int]gw2api.Skin, error) {
+	entries, _, err := r.ListSkins(ctx, CatalogQuery{IDs: ids, Limit: len(ids)})
+	if err != nil {
+		return nil, err
+	}
+
+	found := make(map[int]gw2api.Skin, len(entries))
+	for _, entry := range entries {
+		skin := gw2api.Skin{ID: entry.ID, Name: entry.Name, Type: entry.Type, Rarity: entry.Rarity, Icon: entry.Icon}
+		skin.Details.Type = entry.Subtype
+		skin.Details.WeightClass = entry.WeightClass
+		if len(entry.DyeChannels) > 0 {
+			// The catalog only keeps which channels exist and can be dyed
+			slots := &gw2api.SkinDyeSlots{Default: make([]*gw2api.DyeSlot, len(entry.DyeChannels))}
+			for i, dyeable := range entry.DyeChannels {
+				if dyeable {
+					slots.Default[i] = &gw2api.DyeSlot{}
+				}
+			}
+			skin.Details.DyeSlots = slots
+		}
+		found[entry.ID] = skin
+	}
+	return found, nil
+}
+
+func (r *CatalogRepository) storedColors(ctx context.Context, ids []int) (map[int]gw2api.Color, error) {
+	entries, _, err := r.ListColors(ctx, CatalogQuery{IDs: ids, Limit: len(ids)})
+	if err != nil {
+		return nil, err
+	}
+
+	found := make(map[int]gw2api.Color, len(entries))
+	for _, entry := range entries {
+		color := gw2api.Color{
+			ID:      entry.ID,
+			Name:    entry.Name,
+			BaseRGB: ints(entry.BaseRGB),
+			Cloth:   gw2api.ColorMaterial{RGB: ints(entry.ClothRGB)},
+			Leather: gw2api.ColorMaterial{RGB: ints(entry.LeatherRGB)},
+			Metal:   gw2api.ColorMaterial{RGB: ints(entry.MetalRGB)},
+			Fur:     gw2api.ColorMaterial{RGB: ints(entry.FurRGB)},
+			Item:    entry.ItemID,
+		}
+		// GW2 lists the hue, material and rarity, in that order
+		if entry.Hue != "" {
+			color.Categories = []string{entry.Hue, entry.Material, entry.Rarity}
+		}
+		found[entry.ID] = color
+	}
+	return found, nil
+}
+
+func (r *CatalogRepository) storedOutfits(ctx context.Context, ids []int) (map[int]gw2api.Outfit, error) {
+	entries, _, err := r.ListOutfits(ctx, CatalogQuery{IDs: ids, Limit: len(ids)})
+	if err != nil {
+		return nil, err
+	}
+
+	found := make(map[int]gw2api.Outfit, len(entries))
+	for _, entry := range entries {
+		found[entry.ID] = gw2api.Outfit{ID: entry.ID, Name: entry.Name, Icon: entry.Icon}
+	}
+	return found, nil
+}
+
+func (r *CatalogRepository) storedGliders(ctx context.Context, ids []int) (map[int]gw2api.Glider, error) {
+	entries, _, err := r.ListGliders(ctx, CatalogQuery{IDs: ids, Limit: len(ids)})
+	if err != nil {
+		return nil, err
+	}
+
+	found := make(map[int]gw2api.Glider, len(entries))
+	for _, entry := range entries {
+		found[entry.ID] = gw2api.Glider{ID: entry.ID, Name: entry.Name, Icon: entry.Icon, DefaultDyes: ints(entry.DefaultDyes)}
+	}
+	return found, nil
+}
+
+func (r *CatalogRepository) storedMountSkins(ctx context.Context, ids []int) (map[int]gw2api.MountSkin, error) {
+	entries, _, err := r.ListMountSkins(ctx, CatalogQuery{IDs: ids, Limit: len(ids)})
+	if err != nil {
+		return nil, err
+	}
+
+	found := make(map[int]gw2api.MountSkin, len(entries))
+	for _, entry := range entries {
+		// The catalog only keeps the number of dye channels
+		found[entry.ID] = gw2api.MountSkin{ID: entry.ID, Name: entry.Name, Icon: entry.Icon, Mount: entry.Mount, DyeSlots: make([]gw2api.DyeSlot, entry.DyeChannels)}
+	}
+	return found, nil
+}
+
+func uniqueIDs(ids []int) []int {
+	seen := make(map[int]bool, len(ids))
+	unique := make([]int, 0, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+	return unique
+}
+
+func ints(values []int64) []int {
+	out := make([]int, len(values))
+	for i, v := range values {
+		out[i] = int(v)
+	}
+	return out
+}
@@ -0,0 +1,80 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/NesoHQ/gw2style/equipment"
+	"github.com/NesoHQ/gw2style/gw2api"
+)
+
+// outfitSource is an equipment.Catalog that only knows outfits and records the IDs it was asked for
+type outfitSource struct {
+	equipment.Catalog
+	outfits   map[int]gw2api.Outfit
+	requested []int
+	err       error
+}
+
+func (s *outfitSource) Outfits(ctx context.Context, ids []int) (map[int]gw2api.Outfit, error) {
+	s.requested = append(s.requested, ids...)
+	if s.err != nil {
+		return nil, s.err
+	}
+	found := map[int]gw2api.Outfit{}
+	for _, id := range ids {
+		if outfit, ok := s.outfits[id]; ok {
+			found[id] = outfit
+		}
+	}
+	return found, nil
+}
+
+func TestLookupCatalogFallsBackForMissingIDs(t *testing.T) {
+	stored := &outfitSource{outfits: map[int]gw2api.Outfit{1: {ID: 1, Name: "Stored"}}}
+	fallback := &outfitSource{outfits: map[int]gw2api.Outfit{2: {ID: 2, Name: "New"}}}
+
+	found, err := lookupCatalog(context.Background(), []int{1, 2, 2, 3}, stored.Outfits, fallback, equipment.Catalog.Outfits)
+	if err != nil {
+		t.Fatalf("lookupCatalog: %v", err)
+	}
+
+	if !slices.Equal(stored.requested, []int{1, 2, 3}) {
+		t.Errorf("catalog asked for %v, want [1 2 3]", stored.requested)
+	}
+	if !slices.Equal(fallback.requested, []int{2, 3}) {
+		t.Errorf("fallback asked for %v, want only the missing [2 3]", fallback.requested)
+	}
+	if len(found) != 2 || found[1].Name != "Stored" || found[2].Name != "New" {
+		t.Errorf("found = %v", found)
+	}
+}
+
+func TestLookupCatalogSkipsFallback(t *testing.T) {
+	stored := &outfitSource{outfits: map[int]gw2api.Outfit{1: {ID: 1}}}
+	fallback := &outfitSource{err: errors.New("fallback must not be called")}
+
+	if _, err := lookupCatalog(context.Background(), []int{1}, stored.Outfits, fallback, equipment.Catalog.Outfits); err != nil {
+		t.Errorf("err = %v with every ID stored", err)
+	}
+	if found, err := lookupCatalog(context.Background(), nil, stored.Outfits, fallback, equipment.Catalog.Outfits); err != nil || len(found) != 0 {
+		t.Errorf("lookupCatalog(nil) = %v, %v", found, err)
+	}
+
+	// Without a fallback, missing IDs are left out
+	found, err := lookupCatalog(context.Background(), []int{1, 2}, stored.Outfits, nil, equipment.Catalog.Outfits)
+	if err != nil || len(found) != 1 {
+		t.Errorf("lookupCatalog without fallback = %v, %v", found, err)
+	}
+}
+
+func TestLookupCatalogReturnsFallbackErrors(t *testing.T) {
+	stored := &outfitSource{}
+	fallback := &outfitSource{err: gw2api.ErrUpstreamDown}
+
+	if _, err := lookupCatalog(context.Background(), []int{1}, stored.Outfits, fallback, equipment.Catalog.Outfits); !errors.Is(err, gw2api.ErrUpstreamDown) {
+		t.Errorf("err = %v, want ErrUpstreamDown", err)
+	}
+}
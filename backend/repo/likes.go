@@ -3,9 +3,22 @@ package repo
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 )
 
+var (
+	ErrAlreadyLiked = errors.New("user already liked this post")
+	ErrLikeNotFound = errors.New("like not found")
+)
+
+type PostLike struct {
+	UserID   string `json:"-"`
+	Username string `json:"username"`
+	PostID   string `json:"post_id"`
+	LikedAt  string `json:"liked_at"`
+}
+
 type LikeRepository struct {
 	db *sql.DB
 }
@@ -17,7 +30,8 @@ func NewLikeRepository(db *sql.DB) *LikeRepository {
 }
 
 // LikePost adds a like to a post by a user
-// Updates posts.likes_count and users.liked_posts in a single transaction
+// Inserts into post_likes and updates posts.likes_count in a single transaction.
+// The unique (user_id, post_id) constraint makes concurrent likes count only once.
 func (r *LikeRepository) LikePost(ctx context.Context, postID, userID string) error {
 	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
@@ -25,42 +39,25 @@ func (r *LikeRepository) LikePost(ctx context.Context, postID, userID string) er
 	}
 	defer tx.Rollback()
 
-	// Check if user already liked this post by checking their liked_posts array
-	var likedPosts []byte
-	checkQuery := `SELECT COALESCE(liked_posts, '[]'::json) FROM users WHERE id = $1`
-	err = tx.QueryRowContext(ctx, checkQuery, userID).Scan(&likedPosts)
+	insertQuery := `
+		INSERT INTO post_likes (user_id, post_id)
+		VALUES ($1, $2)
+		ON CONFLICT (user_id, post_id) DO NOTHING`
+	result, err := tx.ExecContext(ctx, insertQuery, userID, postID)
 	if err != nil {
-		return fmt.Errorf("error checking user's liked posts: %w", err)
+		return fmt.Errorf("error inserting like: %w", err)
 	}
 
-	// Check if post ID already exists in the array
-	checkExistsQuery := `SELECT EXISTS(
-		SELECT 1 FROM users 
-		WHERE id = $1 AND liked_posts::jsonb @> $2::jsonb
-	)`
-	var alreadyLiked bool
-	err = tx.QueryRowContext(ctx, checkExistsQuery, userID, fmt.Sprintf(`["%s"]`, postID)).Scan(&alreadyLiked)
+	inserted, err := result.RowsAffected()
 	if err != nil {
-		return fmt.Errorf("error checking if already liked: %w", err)
+		return fmt.Errorf("error checking rows affected: %w", err)
 	}
-
-	if alreadyLiked {
-		return fmt.Errorf("user already liked this post")
-	}
-
-	// Add post ID to user's liked_posts array
-	updateUserQuery := `
-		UPDATE users 
-		SET liked_posts = COALESCE(liked_posts, '[]'::json)::jsonb || $1::jsonb
-		WHERE id = $2
-	`
-	_, err = tx.ExecContext(ctx, updateUserQuery, fmt.Sprintf(`["%s"]`, postID), userID)
-	if err != nil {
-		return fmt.Errorf("error updating user's liked posts: %w", err)
+	if inserted == 0 {
+		return ErrAlreadyLiked
 	}
 
 	// Increment likes_count in posts table
-	updatePostQuery := `UPDATE posts SET likes_count = likes_count + 1 WHERE id = $1`
+	updatePostQuery := `UPDATE posts SET likes_count = COALESCE(likes_count, 0) + 1 WHERE id = $1`
 	_, err = tx.ExecContext(ctx, updatePostQuery, postID)
 	if err != nil {
 		return fmt.Errorf("error updating post likes count: %w", err)
@@ -74,7 +71,7 @@ func (r *LikeRepository) LikePost(ctx context.Context, postID, userID string) er
 }
 
 // UnlikePost removes a like from a post by a user
-// Updates posts.likes_count and users.liked_posts in a single transaction
+// Deletes from post_likes and updates posts.likes_count in a single transaction
 func (r *LikeRepository) UnlikePost(ctx context.Context, postID, userID string) error {
 	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
@@ -82,38 +79,22 @@ func (r *LikeRepository) UnlikePost(ctx context.Context, postID, userID string)
 	}
 	defer tx.Rollback()
 
-	// Check if user has liked this post
-	checkExistsQuery := `SELECT EXISTS(
-		SELECT 1 FROM users 
-		WHERE id = $1 AND liked_posts::jsonb @> $2::jsonb
-	)`
-	var hasLiked bool
-	err = tx.QueryRowContext(ctx, checkExistsQuery, userID, fmt.Sprintf(`["%s"]`, postID)).Scan(&hasLiked)
+	deleteQuery := `DELETE FROM post_likes WHERE user_id = $1 AND post_id = $2`
+	result, err := tx.ExecContext(ctx, deleteQuery, userID, postID)
 	if err != nil {
-		return fmt.Errorf("error checking if liked: %w", err)
-	}
-
-	if !hasLiked {
-		return fmt.Errorf("like not found")
+		return fmt.Errorf("error deleting like: %w", err)
 	}
 
-	// Remove post ID from user's liked_posts array
-	updateUserQuery := `
-		UPDATE users 
-		SET liked_posts = (
-			SELECT jsonb_agg(elem)
-			FROM jsonb_array_elements(COALESCE(liked_posts, '[]'::json)::jsonb) elem
-			WHERE elem::text != $1
-		)
-		WHERE id = $2
-	`
-	_, err = tx.ExecContext(ctx, updateUserQuery, fmt.Sprintf(`"%s"`, postID), userID)
+	deleted, err := result.RowsAffected()
 	if err != nil {
-		return fmt.Errorf("error updating user's liked posts: %w", err)
+		return fmt.Errorf("error checking rows affected: %w", err)
+	}
+	if deleted == 0 {
+		return ErrLikeNotFound
 	}
 
 	// Decrement likes_count in posts table (never go below 0)
-	updatePostQuery := `UPDATE posts SET likes_count = GREATEST(likes_count - 1, 0) WHERE id = $1`
+	updatePostQuery := `UPDATE posts SET likes_count = GREATEST(COALESCE(likes_count, 0) - 1, 0) WHERE id = $1`
 	_, err = tx.ExecContext(ctx, updatePostQuery, postID)
 	if err != nil {
 		return fmt.Errorf("error updating post likes count: %w", err)
@@ -127,16 +108,10 @@ func (r *LikeRepository) UnlikePost(ctx context.Context, postID, userID string)
 }
 
 // HasUserLikedPost checks if a user has liked a specific post
-// Reads from users.liked_posts array
 func (r *LikeRepository) HasUserLikedPost(ctx context.Context, postID, userID string) (bool, error) {
 	var hasLiked bool
-	query := `
-		SELECT EXISTS(
-			SELECT 1 FROM users 
-			WHERE id = $1 AND liked_posts::jsonb @> $2::jsonb
-		)
-	`
-	err := r.db.QueryRowContext(ctx, query, userID, fmt.Sprintf(`["%s"]`, postID)).Scan(&hasLiked)
+	query := `SELECT EXISTS(SELECT 1 FROM post_likes WHERE user_id = $1 AND post_id = $2)`
+	err := r.db.QueryRowContext(ctx, query, userID, postID).Scan(&hasLiked)
 	if err != nil {
 		return false, fmt.Errorf("error checking like status: %w", err)
 	}
@@ -144,7 +119,7 @@ func (r *LikeRepository) HasUserLikedPost(ctx context.Context, postID, userID st
 }
 
 // GetPostLikesCount returns the number of likes for a post
-// Reads directly from posts.likes_count (source of truth)
+// Reads directly from posts.likes_count (kept in sync with post_likes)
 func (r *LikeRepository) GetPostLikesCount(ctx context.Context, postID string) (int, error) {
 	var count int
 	query := `SELECT COALESCE(likes_count, 0) FROM posts WHERE id = $1`
@@ -155,17 +130,61 @@ func (r *LikeRepository) GetPostLikesCount(ctx context.Context, postID string) (
 	return count, nil
 }
 
-// GetUserLikedPosts returns all post IDs that a user has liked
+// GetUserLikedPosts returns all post IDs that a user has liked, most recent first
 // Used for frontend localStorage sync
 func (r *LikeRepository) GetUserLikedPosts(ctx context.Context, userID string) ([]string, error) {
-	var likedPostsJSON []byte
-	query := `SELECT COALESCE(liked_posts, '[]'::json) FROM users WHERE id = $1`
-	err := r.db.QueryRowContext(ctx, query, userID).Scan(&likedPostsJSON)
+	query := `
+		SELECT CAST(post_id AS TEXT)
+		FROM post_likes
+		WHERE user_id = $1
+		ORDER BY created_at DESC`
+
+	rows, err := r.db.QueryContext(ctx, query, userID)
 	if err != nil {
 		return nil, fmt.Errorf("error getting user's liked posts: %w", err)
 	}
+	defer rows.Close()
+
+	postIDs := []string{}
+	for rows.Next() {
+		var postID string
+		if err := rows.Scan(&postID); err != nil {
+			return nil, err
+		}
+		postIDs = append(postIDs, postID)
+	}
+
+	return postIDs, rows.Err()
+}
+
+// GetPostLikes returns who liked a post and when, most recent first
+func (r *LikeRepository) GetPostLikes(ctx context.Context, postID string, limit, offset int) ([]PostLike, error) {
+	query := `
+		SELECT
+			pl.user_id,
+			COALESCE(u.username, '') as username,
+			CAST(pl.post_id AS TEXT),
+			to_char(pl.created_at, 'YYYY-MM-DD"T"HH24:MI:SS"Z"') as liked_at
+		FROM post_likes pl
+		LEFT JOIN users u ON u.id = pl.user_id
+		WHERE pl.post_id = $1
+		ORDER BY pl.created_at DESC
+		LIMIT $2 OFFSET $3`
+
+	rows, err := r.db.QueryContext(ctx, query, postID, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("error getting post likes: %w", err)
+	}
+	defer rows.Close()
+
+	likes := []PostLike{}
+	for rows.Next() {
+		var like PostLike
+		if err := rows.Scan(&like.UserID, &like.Username, &like.PostID, &like.LikedAt); err != nil {
+			return nil, err
+		}
+		likes = append(likes, like)
+	}
 
-	// Parse JSON array to string slice
-	// This will be handled by the handler layer
-	return nil, nil // Handler will parse the JSON
+	return likes, rows.Err()
 }
@@ -3,12 +3,30 @@ package repo
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"time"
+)
+
+const (
+	ReportStatusPending   = "pending"
+	ReportStatusResolved  = "resolved"
+	ReportStatusDismissed = "dismissed"
+)
+
+// SystemModeratorUsername identifies actions taken automatically rather than by a moderator
+const SystemModeratorUsername = "system"
+
+var (
+	ErrReportNotFound   = errors.New("report not found")
+	ErrReportNotPending = errors.New("report has already been handled")
+	ErrDuplicateReport  = errors.New("user already reported this post")
 )
 
 type Report struct {
 	ID               int    `json:"id"`
 	PostID           int    `json:"post_id"`
+	PostTitle        string `json:"post_title,omitempty"`
 	ReporterUsername string `json:"reporter_username"`
 	Reason           string `json:"reason"`
 	Description      string `json:"description"`
@@ -21,6 +39,7 @@ type Report struct {
 type ModerationLog struct {
 	ID                 int    `json:"id"`
 	PostID             int    `json:"post_id"`
+	ReportID           int    `json:"report_id,omitempty"`
 	Action             string `json:"action"`
 	ModeratorUsername  string `json:"moderator_username"`
 	ModeratorDiscordID string `json:"moderator_discord_id"`
@@ -36,7 +55,7 @@ func NewModerationRepository(db *sql.DB) *ModerationRepository {
 	return &ModerationRepository{db: db}
 }
 
-// PublishPost sets a post as published and logs the action
+// PublishPost publishes a pending or hidden post and logs the action
 func (r *ModerationRepository) PublishPost(ctx context.Context, postID int, moderatorUsername, moderatorDiscordID string) error {
 	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
@@ -45,9 +64,8 @@ func (r *ModerationRepository) PublishPost(ctx context.Context, postID int, mode
 	defer tx.Rollback()
 
 	// Update post to published
-	_, err = tx.ExecContext(ctx, "UPDATE posts SET published = true WHERE id = $1", postID)
-	if err != nil {
-		return fmt.Errorf("error publishing post: %w", err)
+	if _, err = transitionPost(ctx, tx, postID, PostStatusPublished); err != nil {
+		return err
 	}
 
 	// Log the action
@@ -62,7 +80,7 @@ func (r *ModerationRepository) PublishPost(ctx context.Context, postID int, mode
 	return tx.Commit()
 }
 
-// RejectPost sets a post as unpublished and logs the action
+// RejectPost rejects a post and logs the action with the reason shown to the author
 func (r *ModerationRepository) RejectPost(ctx context.Context, postID int, moderatorUsername, moderatorDiscordID, reason string) error {
 	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
@@ -70,10 +88,9 @@ func (r *ModerationRepository) RejectPost(ctx context.Context, postID int, moder
 	}
 	defer tx.Rollback()
 
-	// Update post to unpublished
-	_, err = tx.ExecContext(ctx, "UPDATE posts SET published = false WHERE id = $1", postID)
-	if err != nil {
-		return fmt.Errorf("error rejecting post: %w", err)
+	// Update post to rejected
+	if _, err = transitionPost(ctx, tx, postID, PostStatusRejected); err != nil {
+		return err
 	}
 
 	// Log the action
@@ -88,47 +105,132 @@ func (r *ModerationRepository) RejectPost(ctx context.Context, postID int, moder
 	return tx.Commit()
 }
 
-// CreateReport creates a new user report
-func (r *ModerationRepository) CreateReport(ctx context.Context, report Report) error {
+// CreateReport creates a new user report and returns it with its ID
+// Returns ErrDuplicateReport if the user already reported this post
+func (r *ModerationRepository) CreateReport(ctx context.Context, report Report) (*Report, error) {
 	query := `
 		INSERT INTO reports (post_id, reporter_username, reason, description)
-		VALUES ($1, $2, $3, $4)`
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (post_id, reporter_username) DO NOTHING
+		RETURNING id, status, to_char(created_at, 'YYYY-MM-DD"T"HH24:MI:SS"Z"')`
 
-	_, err := r.db.ExecContext(ctx, query, report.PostID, report.ReporterUsername, report.Reason, report.Description)
+	err := r.db.QueryRowContext(ctx, query, report.PostID, report.ReporterUsername, report.Reason, report.Description).
+		Scan(&report.ID, &report.Status, &report.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, ErrDuplicateReport
+	}
 	if err != nil {
-		return fmt.Errorf("error creating report: %w", err)
+		return nil, fmt.Errorf("error creating report: %w", err)
 	}
 
-	return nil
+	return &report, nil
+}
+
+// CountReportsSince returns how many reports a user has filed since the given time
+func (r *ModerationRepository) CountReportsSince(ctx context.Context, reporterUsername string, since time.Time) (int, error) {
+	var count int
+	query := `SELECT COUNT(*) FROM reports WHERE reporter_username = $1 AND created_at >= $2`
+	err := r.db.QueryRowContext(ctx, query, reporterUsername, since).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("error counting reports: %w", err)
+	}
+	return count, nil
+}
+
+// HideReportedPost hides a published post once at least threshold distinct users
+// have open reports against it. The action is logged under the system moderator.
+// Returns whether the post was hidden and the number of distinct reporters.
+func (r *ModerationRepository) HideReportedPost(ctx context.Context, postID, threshold int) (bool, int, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, 0, fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Lock the post so concurrent reports cannot hide it twice
+	status, err := lockPostStatus(ctx, tx, postID)
+	if err != nil {
+		return false, 0, err
+	}
+
+	var reporters int
+	err = tx.QueryRowContext(ctx,
+		"SELECT COUNT(DISTINCT reporter_username) FROM reports WHERE post_id = $1 AND status = $2",
+		postID, ReportStatusPending).Scan(&reporters)
+	if err != nil {
+		return false, 0, fmt.Errorf("error counting reports: %w", err)
+	}
+
+	if status != PostStatusPublished || reporters < threshold {
+		return false, reporters, nil
+	}
+
+	if _, err = transitionPost(ctx, tx, postID, PostStatusHidden); err != nil {
+		return false, 0, err
+	}
+
+	_, err = tx.ExecContext(ctx,
+		`INSERT INTO moderation_log (post_id, action, moderator_username, reason) 
+		 VALUES ($1, $2, $3, $4)`,
+		postID, "auto_hidden", SystemModeratorUsername, fmt.Sprintf("Hidden for review after reports from %d users", reporters))
+	if err != nil {
+		return false, 0, fmt.Errorf("error logging moderation action: %w", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return false, 0, fmt.Errorf("error committing transaction: %w", err)
+	}
+
+	return true, reporters, nil
 }
 
 // GetPendingReports retrieves all pending reports
 func (r *ModerationRepository) GetPendingReports(ctx context.Context) ([]Report, error) {
+	return r.GetReports(ctx, ReportStatusPending, 0, 0)
+}
+
+// GetReports retrieves reports with the given status, oldest first so moderators
+// work through the queue in order. An empty status returns every report.
+// A limit of 0 returns all matching reports.
+func (r *ModerationRepository) GetReports(ctx context.Context, status string, limit, offset int) ([]Report, error) {
 	query := `
-		SELECT id, post_id, reporter_username, reason, 
-		       COALESCE(description, '') as description, status,
-		       to_char(created_at, 'YYYY-MM-DD"T"HH24:MI:SS"Z"') as created_at
-		FROM reports
-		WHERE status = 'pending'
-		ORDER BY created_at DESC`
+		SELECT r.id, r.post_id, COALESCE(p.title, '') as post_title,
+		       r.reporter_username, r.reason,
+		       COALESCE(r.description, '') as description, r.status,
+		       to_char(r.created_at, 'YYYY-MM-DD"T"HH24:MI:SS"Z"') as created_at,
+		       COALESCE(to_char(r.resolved_at, 'YYYY-MM-DD"T"HH24:MI:SS"Z"'), '') as resolved_at,
+		       COALESCE(r.resolved_by, '') as resolved_by
+		FROM reports r
+		LEFT JOIN posts p ON p.id = r.post_id
+		WHERE ($1 = '' OR r.status = $1)
+		ORDER BY r.created_at ASC, r.id ASC`
 
-	rows, err := r.db.QueryContext(ctx, query)
+	args := []interface{}{status}
+	if limit > 0 {
+		query += " LIMIT $2 OFFSET $3"
+		args = append(args, limit, offset)
+	}
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	var reports []Report
+	reports := []Report{}
 	for rows.Next() {
 		var report Report
 		err := rows.Scan(
 			&report.ID,
 			&report.PostID,
+			&report.PostTitle,
 			&report.ReporterUsername,
 			&report.Reason,
 			&report.Description,
 			&report.Status,
 			&report.CreatedAt,
+			&report.ResolvedAt,
+			&report.ResolvedBy,
 		)
 		if err != nil {
 			return nil, err
@@ -139,10 +241,121 @@ func (r *ModerationRepository) GetPendingReports(ctx context.Context) ([]Report,
 	return reports, rows.Err()
 }
 
+// GetReportByID retrieves a single report, or nil if it does not exist
+func (r *ModerationRepository) GetReportByID(ctx context.Context, reportID int) (*Report, error) {
+	query := `
+		SELECT r.id, r.post_id, COALESCE(p.title, '') as post_title,
+		       r.reporter_username, r.reason,
+		       COALESCE(r.description, '') as description, r.status,
+		       to_char(r.created_at, 'YYYY-MM-DD"T"HH24:MI:SS"Z"') as created_at,
+		       COALESCE(to_char(r.resolved_at, 'YYYY-MM-DD"T"HH24:MI:SS"Z"'), '') as resolved_at,
+		       COALESCE(r.resolved_by, '') as resolved_by
+		FROM reports r
+		LEFT JOIN posts p ON p.id = r.post_id
+		WHERE r.id = $1`
+
+	var report Report
+	err := r.db.QueryRowContext(ctx, query, reportID).Scan(
+		&report.ID,
+		&report.PostID,
+		&report.PostTitle,
+		&report.ReporterUsername,
+		&report.Reason,
+		&report.Description,
+		&report.Status,
+		&report.CreatedAt,
+		&report.ResolvedAt,
+		&report.ResolvedBy,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error getting report: %w", err)
+	}
+
+	return &report, nil
+}
+
+// ResolveReport closes a pending report as resolved or dismissed and logs the
+// action. When unpublish is set the reported post is rejected in the same
+// transaction and that is logged as well.
+func (r *ModerationRepository) ResolveReport(ctx context.Context, reportID int, status, moderatorUsername, moderatorDiscordID, reason string, unpublish bool) (*Report, error) {
+	if status != ReportStatusResolved && status != ReportStatusDismissed {
+		return nil, fmt.Errorf("invalid report status: %s", status)
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var postID int
+	var currentStatus string
+	err = tx.QueryRowContext(ctx, "SELECT post_id, status FROM reports WHERE id = $1 FOR UPDATE", reportID).Scan(&postID, &currentStatus)
+	if err == sql.ErrNoRows {
+		return nil, ErrReportNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error locking report: %w", err)
+	}
+
+	if currentStatus != ReportStatusPending {
+		return nil, ErrReportNotPending
+	}
+
+	_, err = tx.ExecContext(ctx,
+		"UPDATE reports SET status = $2, resolved_at = NOW(), resolved_by = $3 WHERE id = $1",
+		reportID, status, moderatorUsername)
+	if err != nil {
+		return nil, fmt.Errorf("error updating report: %w", err)
+	}
+
+	// Log the action
+	action := "report_" + status
+	_, err = tx.ExecContext(ctx,
+		`INSERT INTO moderation_log (post_id, report_id, action, moderator_username, moderator_discord_id, reason) 
+		 VALUES ($1, $2, $3, $4, $5, $6)`,
+		postID, reportID, action, moderatorUsername, moderatorDiscordID, reason)
+	if err != nil {
+		return nil, fmt.Errorf("error logging moderation action: %w", err)
+	}
+
+	// Upheld reports take the post down; posts that are already down stay as they are
+	if unpublish {
+		postStatus, err := lockPostStatus(ctx, tx, postID)
+		if err != nil {
+			return nil, err
+		}
+		unpublish = CanTransition(postStatus, PostStatusRejected)
+	}
+
+	if unpublish {
+		if _, err = transitionPost(ctx, tx, postID, PostStatusRejected); err != nil {
+			return nil, err
+		}
+
+		_, err = tx.ExecContext(ctx,
+			`INSERT INTO moderation_log (post_id, report_id, action, moderator_username, moderator_discord_id, reason) 
+			 VALUES ($1, $2, $3, $4, $5, $6)`,
+			postID, reportID, "unpublished", moderatorUsername, moderatorDiscordID, reason)
+		if err != nil {
+			return nil, fmt.Errorf("error logging moderation action: %w", err)
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("error committing transaction: %w", err)
+	}
+
+	return r.GetReportByID(ctx, reportID)
+}
+
 // GetModerationLogs retrieves recent moderation actions
 func (r *ModerationRepository) GetModerationLogs(ctx context.Context, limit int) ([]ModerationLog, error) {
 	query := `
-		SELECT id, post_id, action, moderator_username, 
+		SELECT id, post_id, COALESCE(report_id, 0) as report_id, action, moderator_username, 
 		       COALESCE(moderator_discord_id, '') as moderator_discord_id,
 		       COALESCE(reason, '') as reason,
 		       to_char(created_at, 'YYYY-MM-DD"T"HH24:MI:SS"Z"') as created_at
@@ -162,6 +375,7 @@ func (r *ModerationRepository) GetModerationLogs(ctx context.Context, limit int)
 		err := rows.Scan(
 			&log.ID,
 			&log.PostID,
+			&log.ReportID,
 			&log.Action,
 			&log.ModeratorUsername,
 			&log.ModeratorDiscordID,
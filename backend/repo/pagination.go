@@ -0,0 +1,255 @@
+package repo
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Cursor marks a position in a listing by the sort key and ID of a row.
+// Before asks for the rows preceding that position instead of following it.
+// Sort names the ordering the key belongs to, so a cursor of one listing is
+// rejected by listings sorted differently.
+type Cursor struct {
+	Sort    string `json:"s"`
+	SortKey string `json:"k"`
+	ID      int    `json:"i"`
+	Before  bool   `json:"b,omitempty"`
+}
+
+// EncodeCursor turns a cursor into an opaque URL-safe token
+func EncodeCursor(c Cursor) string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeCursor parses a token produced by EncodeCursor
+func DecodeCursor(token string) (*Cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	var c Cursor
+	if err := json.Unmarshal(data, &c); err != nil || c.ID <= 0 {
+		return nil, ErrInvalidCursor
+	}
+
+	return &c, nil
+}
+
+// PageRequest selects one page of a listing, either by cursor or by offset.
+// The cursor wins when both are set.
+type PageRequest struct {
+	Limit  int
+	Offset int
+	Cursor *Cursor
+}
+
+// PageInfo describes where a page sits in its listing. Total is only counted
+// for offset based requests, which need it for total_pages.
+type PageInfo struct {
+	NextCursor string
+	PrevCursor string
+	Total      int
+	HasTotal   bool
+}
+
+// postSort describes the keyset of a listing: rows are ordered by expr
+// (cast to sqlType when compared with a cursor) and then by id, both descending.
+type postSort struct {
+	name    string
+	expr    string
+	sqlType string
+}
+
+var (
+	sortByID         = postSort{name: "id", expr: "id", sqlType: "integer"}
+	sortByCreatedAt  = postSort{name: "created", expr: "COALESCE(created_at, 'epoch'::timestamptz)", sqlType: "timestamptz"}
+	sortByLikesCount = postSort{name: "likes", expr: "COALESCE(likes_count, 0)", sqlType: "integer"}
+)
+
+// Layouts of timestamptz values cast to text, with hour or hour and minute offsets
+var timestampKeyLayouts = []string{"2006-01-02 15:04:05.999999Z07", "2006-01-02 15:04:05.999999Z07:00"}
+
+// accepts reports whether c was issued by a listing with this sort and its
+// key can be cast to sqlType, so a replayed or tampered cursor is rejected
+// before it reaches the database
+func (s postSort) accepts(c Cursor) bool {
+	if c.Sort != s.name {
+		return false
+	}
+
+	switch s.sqlType {
+	case "integer":
+		_, err := strconv.ParseInt(c.SortKey, 10, 32)
+		return err == nil
+	case "real":
+		_, err := strconv.ParseFloat(c.SortKey, 32)
+		return err == nil
+	case "timestamptz":
+		for _, layout := range timestampKeyLayouts {
+			if _, err := time.Parse(layout, c.SortKey); err == nil {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// postQuery is a filtered post listing. where holds the filter conditions
+// whose placeholders are bound to args. When tsQuery names the placeholder of a
+// full-text query, every summary gets highlighted snippets of its matches.
+type postQuery struct {
+	where   []string
+	args    []interface{}
+	sort    postSort
+	tsQuery string
+}
+
+// listPosts runs a paginated post summary query
+func (r *PostRepository) listPosts(ctx context.Context, q postQuery, page PageRequest) ([]PostSummary, PageInfo, error) {
+	var info PageInfo
+	sort := q.sort
+	conditions := append([]string{}, q.where...)
+	queryArgs := append([]interface{}{}, q.args...)
+
+	if page.Cursor != nil && !sort.accepts(*page.Cursor) {
+		return nil, info, ErrInvalidCursor
+	}
+
+	if page.Cursor == nil {
+		countQuery := "SELECT COUNT(*) FROM posts"
+		if len(conditions) > 0 {
+			countQuery += " WHERE " + strings.Join(conditions, " AND ")
+		}
+		if err := r.db.QueryRowContext(ctx, countQuery, queryArgs...).Scan(&info.Total); err != nil {
+			return nil, info, fmt.Errorf("error getting total count: %w", err)
+		}
+		info.HasTotal = true
+	}
+
+	direction := "DESC"
+	if page.Cursor != nil {
+		comparison := "<"
+		if page.Cursor.Before {
+			comparison = ">"
+			direction = "ASC"
+		}
+		queryArgs = append(queryArgs, page.Cursor.SortKey, page.Cursor.ID)
+		conditions = append(conditions, fmt.Sprintf("(%s, id) %s ($%d::%s, $%d)",
+			sort.expr, comparison, len(queryArgs)-1, sort.sqlType, len(queryArgs)))
+	}
+
+	highlights := "'' as title_highlight, '' as snippet"
+	if q.tsQuery != "" {
+		highlights = fmt.Sprintf(`
+			ts_headline('english', COALESCE(title, ''), to_tsquery('english', %[1]s),
+				'StartSel=<mark>, StopSel=</mark>, HighlightAll=true') as title_highlight,
+			ts_headline('english', COALESCE(description, ''), to_tsquery('english', %[1]s),
+				'StartSel=<mark>, StopSel=</mark>, MaxFragments=2, MaxWords=20, MinWords=5') as snippet`, q.tsQuery)
+	}
+
+	query := `
+		SELECT
+			CAST(id AS TEXT),
+			COALESCE(title, '') as title,
+			COALESCE(thumbnail_url, '') as thumbnail,
+			COALESCE(author_name, '') as author_name,
+			COALESCE(likes_count, 0) as likes_count,
+			CAST(` + sort.expr + ` AS TEXT) as sort_key,
+			` + highlights + `
+		FROM posts`
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	query += fmt.Sprintf(" ORDER BY %s %s, id %s", sort.expr, direction, direction)
+
+	// Fetch one extra row to know whether there is another page
+	queryArgs = append(queryArgs, page.Limit+1)
+	query += fmt.Sprintf(" LIMIT $%d", len(queryArgs))
+	if page.Cursor == nil && page.Offset > 0 {
+		queryArgs = append(queryArgs, page.Offset)
+		query += fmt.Sprintf(" OFFSET $%d", len(queryArgs))
+	}
+
+	rows, err := r.db.QueryContext(ctx, query, queryArgs...)
+	if err != nil {
+		return nil, info, err
+	}
+	defer rows.Close()
+
+	posts := []PostSummary{}
+	sortKeys := []string{}
+	for rows.Next() {
+		var post PostSummary
+		var sortKey string
+		err := rows.Scan(
+			&post.ID,
+			&post.Title,
+			&post.Thumbnail,
+			&post.AuthorName,
+			&post.LikesCount,
+			&sortKey,
+			&post.TitleHighlight,
+			&post.Snippet,
+		)
+		if err != nil {
+			return nil, info, err
+		}
+		posts = append(posts, post)
+		sortKeys = append(sortKeys, sortKey)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, info, err
+	}
+
+	hasMore := len(posts) > page.Limit
+	if hasMore {
+		posts = posts[:page.Limit]
+		sortKeys = sortKeys[:page.Limit]
+	}
+
+	// Backward pages are read in ascending order; flip them back
+	backward := page.Cursor != nil && page.Cursor.Before
+	if backward {
+		for i, j := 0, len(posts)-1; i < j; i, j = i+1, j-1 {
+			posts[i], posts[j] = posts[j], posts[i]
+			sortKeys[i], sortKeys[j] = sortKeys[j], sortKeys[i]
+		}
+	}
+
+	if len(posts) == 0 {
+		return posts, info, nil
+	}
+
+	cursorAt := func(i int, before bool) string {
+		id, _ := strconv.Atoi(posts[i].ID)
+		return EncodeCursor(Cursor{Sort: sort.name, SortKey: sortKeys[i], ID: id, Before: before})
+	}
+
+	last := len(posts) - 1
+	if backward {
+		info.NextCursor = cursorAt(last, false)
+		if hasMore {
+			info.PrevCursor = cursorAt(0, true)
+		}
+	} else {
+		if hasMore {
+			info.NextCursor = cursorAt(last, false)
+		}
+		if page.Cursor != nil || page.Offset > 0 {
+			info.PrevCursor = cursorAt(0, true)
+		}
+	}
+
+	return posts, info, nil
+}
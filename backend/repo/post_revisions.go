@@ -0,0 +1,86 @@
+package repo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+type PostRevision struct {
+	ID          int             `json:"id"`
+	PostID      int             `json:"post_id"`
+	Revision    int             `json:"revision"`
+	Title       string          `json:"title"`
+	Description string          `json:"description"`
+	Thumbnail   string          `json:"thumbnail"`
+	Image1      string          `json:"image1"`
+	Image2      string          `json:"image2"`
+	Image3      string          `json:"image3"`
+	Image4      string          `json:"image4"`
+	Image5      string          `json:"image5"`
+	Equipments  json.RawMessage `json:"equipments"`
+	Tags        json.RawMessage `json:"tags"`
+	EditedBy    string          `json:"edited_by"`
+	CreatedAt   string          `json:"created_at"`
+}
+
+// GetPostRevisions returns every previous version of a post, newest first
+func (r *PostRepository) GetPostRevisions(ctx context.Context, postID string) ([]PostRevision, error) {
+	query := `
+		SELECT
+			id,
+			post_id,
+			revision,
+			COALESCE(title, '') as title,
+			COALESCE(description, '') as description,
+			COALESCE(thumbnail_url, '') as thumbnail,
+			COALESCE(image1_url, '') as image1,
+			COALESCE(image2_url, '') as image2,
+			COALESCE(image3_url, '') as image3,
+			COALESCE(image4_url, '') as image4,
+			COALESCE(image5_url, '') as image5,
+			COALESCE(equipments, 'null'::json) as equipments,
+			COALESCE(tags, '[]'::jsonb) as tags,
+			edited_by,
+			to_char(created_at, 'YYYY-MM-DD"T"HH24:MI:SS"Z"') as created_at
+		FROM post_revisions
+		WHERE post_id = $1
+		ORDER BY revision DESC`
+
+	rows, err := r.db.QueryContext(ctx, query, postID)
+	if err != nil {
+		return nil, fmt.Errorf("error getting post revisions: %w", err)
+	}
+	defer rows.Close()
+
+	revisions := []PostRevision{}
+	for rows.Next() {
+		var rev PostRevision
+		var equipments, tags []byte
+		err := rows.Scan(
+			&rev.ID,
+			&rev.PostID,
+			&rev.Revision,
+			&rev.Title,
+			&rev.Description,
+			&rev.Thumbnail,
+			&rev.Image1,
+			&rev.Image2,
+			&rev.Image3,
+			&rev.Image4,
+			&rev.Image5,
+			&equipments,
+			&tags,
+			&rev.EditedBy,
+			&rev.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		rev.Equipments = json.RawMessage(equipments)
+		rev.Tags = json.RawMessage(tags)
+		revisions = append(revisions, rev)
+	}
+
+	return revisions, rows.Err()
+}
@@ -0,0 +1,84 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+type PostStatus string
+
+const (
+	PostStatusDraft     = PostStatus("draft")
+	PostStatusPending   = PostStatus("pending")
+	PostStatusPublished = PostStatus("published")
+	PostStatusRejected  = PostStatus("rejected")
+	PostStatusHidden    = PostStatus("hidden")
+	PostStatusDeleted   = PostStatus("deleted")
+)
+
+var (
+	ErrPostNotFound      = errors.New("post not found")
+	ErrInvalidTransition = errors.New("invalid post status transition")
+)
+
+// postTransitions lists the statuses a post may move to from each status.
+// Deleted is terminal.
+var postTransitions = map[PostStatus][]PostStatus{
+	PostStatusDraft:     {PostStatusPending, PostStatusDeleted},
+	PostStatusPending:   {PostStatusPublished, PostStatusRejected, PostStatusDeleted},
+	PostStatusPublished: {PostStatusPending, PostStatusHidden, PostStatusRejected, PostStatusDeleted},
+	PostStatusRejected:  {PostStatusPending, PostStatusDeleted},
+	PostStatusHidden:    {PostStatusPublished, PostStatusRejected, PostStatusDeleted},
+	PostStatusDeleted:   {},
+}
+
+// ValidPostStatus reports whether s is one of the known post statuses
+func ValidPostStatus(s string) bool {
+	_, ok := postTransitions[PostStatus(s)]
+	return ok
+}
+
+// CanTransition reports whether a post may move from one status to another
+func CanTransition(from, to PostStatus) bool {
+	for _, allowed := range postTransitions[from] {
+		if allowed == to {
+			return true
+		}
+	}
+	return false
+}
+
+// lockPostStatus locks a post row for the rest of the transaction and returns its status
+func lockPostStatus(ctx context.Context, tx *sql.Tx, postID interface{}) (PostStatus, error) {
+	var status PostStatus
+	err := tx.QueryRowContext(ctx, "SELECT status FROM posts WHERE id = $1 FOR UPDATE", postID).Scan(&status)
+	if err == sql.ErrNoRows {
+		return "", ErrPostNotFound
+	}
+	if err != nil {
+		return "", fmt.Errorf("error locking post: %w", err)
+	}
+	return status, nil
+}
+
+// transitionPost moves a locked post to a new status if the transition is allowed
+// and returns the status it had before
+func transitionPost(ctx context.Context, tx *sql.Tx, postID interface{}, to PostStatus) (PostStatus, error) {
+	from, err := lockPostStatus(ctx, tx, postID)
+	if err != nil {
+		return "", err
+	}
+
+	if !CanTransition(from, to) {
+		return from, fmt.Errorf("%w: %s -> %s", ErrInvalidTransition, from, to)
+	}
+
+	_, err = tx.ExecContext(ctx, "UPDATE posts SET status = $2 WHERE id = $1", postID, to)
+	if err != nil {
+		return from, fmt.Errorf("error updating post status: %w", err)
+	}
+
+	return from, nil
+}
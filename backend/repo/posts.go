@@ -7,32 +7,40 @@ import (
 	"fmt"
 	"strings"
 	"time"
+	"unicode"
+
+	"github.com/lib/pq"
 )
 
 type Post struct {
-	ID          string      `json:"id"`
-	Title       string      `json:"title"`
-	Description string      `json:"description"`
-	Thumbnail   string      `json:"thumbnail"`
-	Image1      string      `json:"image1"`
-	Image2      string      `json:"image2"`
-	Image3      string      `json:"image3"`
-	Image4      string      `json:"image4"`
-	Image5      string      `json:"image5"`
-	Equipments  interface{} `json:"equipments"` // Using interface{} for JSON
-	AuthorName  string      `json:"author_name"`
-	Tags        interface{} `json:"tags"` // JSONB array of tags
-	CreatedAt   string      `json:"created_at"`
-	LikesCount  int         `json:"likes_count"`
-	Published   bool        `json:"published"`
+	ID           string      `json:"id"`
+	Title        string      `json:"title"`
+	Description  string      `json:"description"`
+	Thumbnail    string      `json:"thumbnail"`
+	Image1       string      `json:"image1"`
+	Image2       string      `json:"image2"`
+	Image3       string      `json:"image3"`
+	Image4       string      `json:"image4"`
+	Image5       string      `json:"image5"`
+	Equipments   interface{} `json:"equipments"` // Using interface{} for JSON
+	AuthorName   string      `json:"author_name"`
+	Tags         interface{} `json:"tags"` // JSONB array of tags
+	CreatedAt    string      `json:"created_at"`
+	UpdatedAt    string      `json:"updated_at,omitempty"`
+	LikesCount   int         `json:"likes_count"`
+	Published    bool        `json:"published"`
+	Status       PostStatus  `json:"status"`
+	StatusReason string      `json:"status_reason,omitempty"` // Latest moderation reason for rejected and hidden posts
 }
 
 type PostSummary struct {
-	ID         string `json:"id"`
-	Title      string `json:"title"`
-	Thumbnail  string `json:"thumbnail"`
-	AuthorName string `json:"author_name"`
-	LikesCount int    `json:"likes_count"`
+	ID             string `json:"id"`
+	Title          string `json:"title"`
+	Thumbnail      string `json:"thumbnail"`
+	AuthorName     string `json:"author_name"`
+	LikesCount     int    `json:"likes_count"`
+	TitleHighlight string `json:"title_highlight,omitempty"` // Search matches wrapped in <mark>
+	Snippet        string `json:"snippet,omitempty"`         // Description fragments around search matches
 }
 
 type PostRepository struct {
@@ -45,74 +53,40 @@ func NewPostRepository(db *sql.DB) *PostRepository {
 	}
 }
 
-func (r *PostRepository) GetPosts(ctx context.Context, limit, offset int) ([]PostSummary, int, error) {
-	queryArgs := []interface{}{limit, offset}
-
-	// Get total count
-	countQuery := "SELECT COUNT(*) FROM posts WHERE published = true"
-	var totalCount int
-	err := r.db.QueryRowContext(ctx, countQuery).Scan(&totalCount)
-	if err != nil {
-		return nil, 0, fmt.Errorf("error getting total count: %w", err)
-	}
-
-	query := `
-		SELECT 
-			CAST(id AS TEXT),
-			COALESCE(title, '') as title,
-			COALESCE(thumbnail_url, '') as thumbnail,
-			COALESCE(author_name, '') as author_name,
-			COALESCE(likes_count, 0) as likes_count
-		FROM posts
-		WHERE published = true
-		ORDER BY CAST(id AS INTEGER) DESC
-		LIMIT $1 OFFSET $2`
-
-	rows, err := r.db.QueryContext(ctx, query, queryArgs...)
-	if err != nil {
-		return nil, 0, err
-	}
-	defer rows.Close()
-
-	var posts []PostSummary
-	for rows.Next() {
-		var post PostSummary
-		err := rows.Scan(
-			&post.ID,
-			&post.Title,
-			&post.Thumbnail,
-			&post.AuthorName,
-			&post.LikesCount,
-		)
-		if err != nil {
-			return nil, 0, err
-		}
-		posts = append(posts, post)
-	}
-
-	if err = rows.Err(); err != nil {
-		return nil, 0, err
-	}
-
-	return posts, totalCount, nil
+// GetPosts returns one page of the published feed, newest first
+func (r *PostRepository) GetPosts(ctx context.Context, page PageRequest) ([]PostSummary, PageInfo, error) {
+	return r.listPosts(ctx, postQuery{where: []string{"status = 'published'"}, sort: sortByID}, page)
 }
 
+// Search result orderings
+const (
+	SearchSortNewest    = "newest"
+	SearchSortRelevance = "relevance"
+)
+
 type SearchParams struct {
 	Query         string
-	Tags          []string // Array of tags to filter by (AND condition)
+	Tags          []string // Posts must have all of these tags
+	AnyTags       []string // Posts must have at least one of these tags
+	ExcludeTags   []string // Posts must have none of these tags
 	OnlyPublished bool
 	AuthorName    string
-	Limit         int
-	Offset        int
+	Sort          string // SearchSortNewest (default) or SearchSortRelevance
+	Page          PageRequest
 }
 
 // Create adds a new post to the database
+// Posts start as pending unless they are saved as a draft
 func (r *PostRepository) Create(post Post) (*Post, error) {
+	if post.Status != PostStatusDraft {
+		post.Status = PostStatusPending
+	}
+
 	query := `
 		INSERT INTO posts (
 			title, description, thumbnail_url, image1_url, image2_url, 
 			image3_url, image4_url, image5_url, equipments, author_name, 
-			tags, published
+			tags, status
 		) VALUES (
 			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12
 		) RETURNING id`
@@ -122,7 +96,7 @@ func (r *PostRepository) Create(post Post) (*Post, error) {
 		query,
 		post.Title, post.Description, post.Thumbnail, post.Image1,
 		post.Image2, post.Image3, post.Image4, post.Image5,
-		post.Equipments, post.AuthorName, post.Tags, post.Published,
+		post.Equipments, post.AuthorName, post.Tags, post.Status,
 	).Scan(&id)
 
 	if err != nil {
@@ -132,11 +106,13 @@ func (r *PostRepository) Create(post Post) (*Post, error) {
 	post.ID = id
 	post.CreatedAt = time.Now().Format(time.RFC3339)
 	post.LikesCount = 0
+	post.Published = false
 
 	return &post, nil
 }
 
 // GetPostByID retrieves a single post by its ID
+// Deleted posts are treated as missing
 func (r *PostRepository) GetPostByID(ctx context.Context, id string) (*Post, error) {
 	query := `
 		SELECT 
@@ -153,10 +129,17 @@ func (r *PostRepository) GetPostByID(ctx context.Context, id string) (*Post, err
 			COALESCE(author_name, '') as author_name,
 			COALESCE(tags, '[]'::jsonb) as tags,
 			to_char(COALESCE(created_at, NOW()), 'YYYY-MM-DD"T"HH24:MI:SS"Z"') as created_at,
+			COALESCE(to_char(updated_at, 'YYYY-MM-DD"T"HH24:MI:SS"Z"'), '') as updated_at,
 			COALESCE(likes_count, 0) as likes_count,
-			COALESCE(published, false) as published
+			status,
+			CASE WHEN status IN ('rejected', 'hidden') THEN COALESCE((
+				SELECT reason FROM moderation_log ml
+				WHERE ml.post_id = posts.id
+				ORDER BY ml.created_at DESC, ml.id DESC
+				LIMIT 1
+			), '') ELSE '' END as status_reason
 		FROM posts
-		WHERE id = $1`
+		WHERE id = $1 AND status <> 'deleted'`
 
 	var post Post
 	err := r.db.QueryRowContext(ctx, query, id).Scan(
@@ -173,8 +156,10 @@ func (r *PostRepository) GetPostByID(ctx context.Context, id string) (*Post, err
 		&post.AuthorName,
 		&post.Tags,
 		&post.CreatedAt,
+		&post.UpdatedAt,
 		&post.LikesCount,
-		&post.Published,
+		&post.Status,
+		&post.StatusReason,
 	)
 
 	if err == sql.ErrNoRows {
@@ -185,166 +170,221 @@ func (r *PostRepository) GetPostByID(ctx context.Context, id string) (*Post, err
 		return nil, fmt.Errorf("error getting post: %w", err)
 	}
 
+	post.Published = post.Status == PostStatusPublished
+
 	return &post, nil
 }
 
-func (r *PostRepository) GetPopularPosts(ctx context.Context, timeframe string, limit int) ([]PostSummary, error) {
-	var timeCondition string
+// ReindexEquipment rebuilds the search vector of every post with equipment,
+// picking up skin names the catalog did not have when the post was indexed
+func (r *PostRepository) ReindexEquipment(ctx context.Context) (int64, error) {
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE posts SET search_vector = post_search_document(title, description, tags, equipments)
+		WHERE equipments IS NOT NULL`)
+	if err != nil {
+		return 0, fmt.Errorf("error reindexing posts: %w", err)
+	}
+	return result.RowsAffected()
+}
+
+// GetPopularPosts returns one page of the most liked published posts within a timeframe
+func (r *PostRepository) GetPopularPosts(ctx context.Context, timeframe string, page PageRequest) ([]PostSummary, PageInfo, error) {
+	conditions := []string{"status = 'published'"}
 	switch timeframe {
 	case "week":
 		// Start of current week (Monday)
-		timeCondition = "AND created_at >= DATE_TRUNC('week', NOW())"
+		conditions = append(conditions, "created_at >= DATE_TRUNC('week', NOW())")
 	case "month":
 		// Start of current month
-		timeCondition = "AND created_at >= DATE_TRUNC('month', NOW())"
+		conditions = append(conditions, "created_at >= DATE_TRUNC('month', NOW())")
+	}
+
+	return r.listPosts(ctx, postQuery{where: conditions, sort: sortByLikesCount}, page)
+}
+
+// UpdatePost snapshots the current version of a post into post_revisions and
+// then overwrites it with the given content. Drafts stay drafts; published and
+// rejected posts go back to pending moderation. The returned status is the one
+// the post had before the edit.
+func (r *PostRepository) UpdatePost(ctx context.Context, post Post, editedBy string) (PostStatus, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return "", fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Lock the post so concurrent edits get sequential revision numbers
+	from, err := lockPostStatus(ctx, tx, post.ID)
+	if err != nil {
+		return "", err
+	}
+
+	to := PostStatusPending
+	switch from {
+	case PostStatusDraft, PostStatusPending:
+		to = from
+	case PostStatusPublished, PostStatusRejected:
 	default:
-		timeCondition = ""
+		return from, fmt.Errorf("%w: cannot edit a %s post", ErrInvalidTransition, from)
 	}
 
-	query := fmt.Sprintf(`
-		SELECT 
-			CAST(id AS TEXT),
-			COALESCE(title, '') as title,
-			COALESCE(thumbnail_url, '') as thumbnail,
-			COALESCE(author_name, '') as author_name,
-			COALESCE(likes_count, 0) as likes_count
+	// Snapshot the current version before overwriting it
+	snapshotQuery := `
+		INSERT INTO post_revisions (
+			post_id, revision, title, description, thumbnail_url, image1_url,
+			image2_url, image3_url, image4_url, image5_url, equipments, tags, edited_by
+		)
+		SELECT
+			id,
+			(SELECT COALESCE(MAX(revision), 0) + 1 FROM post_revisions WHERE post_id = posts.id),
+			title, description, thumbnail_url, image1_url, image2_url,
+			image3_url, image4_url, image5_url, equipments, COALESCE(tags, '[]'::jsonb), $2
 		FROM posts
-		WHERE published = true %s
-		ORDER BY likes_count DESC
-		LIMIT $1`, timeCondition)
+		WHERE id = $1`
+	_, err = tx.ExecContext(ctx, snapshotQuery, post.ID, editedBy)
+	if err != nil {
+		return from, fmt.Errorf("error saving post revision: %w", err)
+	}
 
-	rows, err := r.db.QueryContext(ctx, query, limit)
+	updateQuery := `
+		UPDATE posts SET
+			title = $2, description = $3, thumbnail_url = $4, image1_url = $5,
+			image2_url = $6, image3_url = $7, image4_url = $8, image5_url = $9,
+			equipments = $10, tags = $11, status = $12, updated_at = NOW()
+		WHERE id = $1`
+	_, err = tx.ExecContext(ctx, updateQuery,
+		post.ID, post.Title, post.Description, post.Thumbnail, post.Image1,
+		post.Image2, post.Image3, post.Image4, post.Image5,
+		post.Equipments, post.Tags, to,
+	)
 	if err != nil {
-		return nil, err
+		return from, fmt.Errorf("error updating post: %w", err)
 	}
-	defer rows.Close()
-
-	var posts []PostSummary
-	for rows.Next() {
-		var post PostSummary
-		err := rows.Scan(
-			&post.ID,
-			&post.Title,
-			&post.Thumbnail,
-			&post.AuthorName,
-			&post.LikesCount,
-		)
-		if err != nil {
-			return nil, err
-		}
-		posts = append(posts, post)
+
+	if err = tx.Commit(); err != nil {
+		return from, fmt.Errorf("error committing transaction: %w", err)
+	}
+
+	return from, nil
+}
+
+// SubmitPost sends a draft to the moderation queue
+func (r *PostRepository) SubmitPost(ctx context.Context, postID string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	from, err := lockPostStatus(ctx, tx, postID)
+	if err != nil {
+		return err
+	}
+	if from != PostStatusDraft {
+		return fmt.Errorf("%w: only drafts can be submitted", ErrInvalidTransition)
 	}
 
-	if err = rows.Err(); err != nil {
-		return nil, err
+	if _, err = transitionPost(ctx, tx, postID, PostStatusPending); err != nil {
+		return err
 	}
 
-	return posts, nil
+	return tx.Commit()
 }
 
-// DeletePost soft deletes a post by setting published to false
+// DeletePost soft deletes a post by moving it to the deleted status
 func (r *PostRepository) DeletePost(ctx context.Context, postID string) error {
-	query := `UPDATE posts SET published = false WHERE id = $1`
-	result, err := r.db.ExecContext(ctx, query, postID)
+	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
-		return fmt.Errorf("error deleting post: %w", err)
+		return fmt.Errorf("error starting transaction: %w", err)
 	}
+	defer tx.Rollback()
 
-	rowsAffected, err := result.RowsAffected()
-	if err != nil {
-		return fmt.Errorf("error checking rows affected: %w", err)
+	if _, err = transitionPost(ctx, tx, postID, PostStatusDeleted); err != nil {
+		return err
 	}
 
-	if rowsAffected == 0 {
-		return fmt.Errorf("post not found")
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("error deleting post: %w", err)
 	}
 
 	return nil
 }
 
-func (r *PostRepository) SearchPosts(ctx context.Context, params SearchParams) ([]PostSummary, int, error) {
-	queryArgs := []interface{}{}
-	conditions := []string{}
+// SearchPosts filters posts by full-text query, tags and author. The query is
+// matched by prefix against the weighted search_vector so partially typed words
+// match too.
+func (r *PostRepository) SearchPosts(ctx context.Context, params SearchParams) ([]PostSummary, PageInfo, error) {
+	q, err := searchQuery(params)
+	if err != nil {
+		return nil, PageInfo{}, err
+	}
+
+	return r.listPosts(ctx, q, params.Page)
+}
+
+// searchQuery builds the filters and ordering shared by search results and their facets
+func searchQuery(params SearchParams) (postQuery, error) {
+	q := postQuery{sort: sortByCreatedAt}
 
 	if params.OnlyPublished {
-		conditions = append(conditions, "published = true")
+		q.where = append(q.where, "status = 'published'")
 	}
 
-	if params.Query != "" {
-		queryArgs = append(queryArgs, "%"+params.Query+"%", "%"+params.Query+"%")
-		conditions = append(conditions, "(title ILIKE $"+fmt.Sprint(len(queryArgs)-1)+" OR description ILIKE $"+fmt.Sprint(len(queryArgs))+")")
+	if tsQuery := prefixTSQuery(params.Query); tsQuery != "" {
+		q.args = append(q.args, tsQuery)
+		q.tsQuery = "$" + fmt.Sprint(len(q.args))
+		q.where = append(q.where, "search_vector @@ to_tsquery('english', "+q.tsQuery+")")
+
+		if params.Sort == SearchSortRelevance {
+			q.sort = postSort{
+				name:    "relevance",
+				expr:    "ts_rank(search_vector, to_tsquery('english', " + q.tsQuery + "))",
+				sqlType: "real",
+			}
+		}
 	}
 
 	// Filter by tags using JSONB contains operator (@>)
 	if len(params.Tags) > 0 {
 		tagsJSON, err := json.Marshal(params.Tags)
 		if err != nil {
-			return nil, 0, fmt.Errorf("error marshaling tags: %w", err)
+			return q, fmt.Errorf("error marshaling tags: %w", err)
 		}
-		queryArgs = append(queryArgs, string(tagsJSON))
-		conditions = append(conditions, "tags @> $"+fmt.Sprint(len(queryArgs))+"::jsonb")
-	}
-
-	if params.AuthorName != "" {
-		queryArgs = append(queryArgs, params.AuthorName)
-		conditions = append(conditions, "author_name = $"+fmt.Sprint(len(queryArgs)))
+		q.args = append(q.args, string(tagsJSON))
+		q.where = append(q.where, "tags @> $"+fmt.Sprint(len(q.args))+"::jsonb")
 	}
 
-	whereClause := ""
-	if len(conditions) > 0 {
-		whereClause = "WHERE " + strings.Join(conditions, " AND ")
+	// ?| matches posts having any of the given tags
+	if len(params.AnyTags) > 0 {
+		q.args = append(q.args, pq.Array(params.AnyTags))
+		q.where = append(q.where, "tags ?| $"+fmt.Sprint(len(q.args))+"::text[]")
 	}
 
-	// Get total count
-	countQuery := "SELECT COUNT(*) FROM posts " + whereClause
-	var totalCount int
-	err := r.db.QueryRowContext(ctx, countQuery, queryArgs...).Scan(&totalCount)
-	if err != nil {
-		return nil, 0, fmt.Errorf("error getting total count: %w", err)
+	if len(params.ExcludeTags) > 0 {
+		q.args = append(q.args, pq.Array(params.ExcludeTags))
+		q.where = append(q.where, "NOT (COALESCE(tags, '[]'::jsonb) ?| $"+fmt.Sprint(len(q.args))+"::text[])")
 	}
 
-	baseQuery := `
-		SELECT 
-			CAST(id AS TEXT),
-			COALESCE(title, '') as title,
-			COALESCE(thumbnail_url, '') as thumbnail,
-			COALESCE(author_name, '') as author_name,
-			COALESCE(likes_count, 0) as likes_count
-		FROM posts`
-
-	query := baseQuery + " " + whereClause + " ORDER BY created_at DESC"
-
-	// Add pagination if limit is set
-	if params.Limit > 0 {
-		queryArgs = append(queryArgs, params.Limit, params.Offset)
-		query += fmt.Sprintf(" LIMIT $%d OFFSET $%d", len(queryArgs)-1, len(queryArgs))
+	if params.AuthorName != "" {
+		q.args = append(q.args, params.AuthorName)
+		q.where = append(q.where, "author_name = $"+fmt.Sprint(len(q.args)))
 	}
 
-	rows, err := r.db.QueryContext(ctx, query, queryArgs...)
-	if err != nil {
-		return nil, 0, err
-	}
-	defer rows.Close()
-
-	var posts []PostSummary
-	for rows.Next() {
-		var post PostSummary
-		err := rows.Scan(
-			&post.ID,
-			&post.Title,
-			&post.Thumbnail,
-			&post.AuthorName,
-			&post.LikesCount,
-		)
-		if err != nil {
-			return nil, 0, err
-		}
-		posts = append(posts, post)
-	}
+	return q, nil
+}
 
-	if err = rows.Err(); err != nil {
-		return nil, 0, err
+// prefixTSQuery turns free text into a tsquery matching every word by prefix,
+// e.g. "sylv arm" becomes "sylv:* & arm:*". Punctuation is dropped so user
+// input can never produce tsquery syntax errors.
+func prefixTSQuery(text string) string {
+	words := strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+
+	terms := make([]string, 0, len(words))
+	for _, word := range words {
+		terms = append(terms, strings.ToLower(word)+":*")
 	}
 
-	return posts, totalCount, nil
+	return strings.Join(terms, " & ")
 }
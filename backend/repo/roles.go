@@ -0,0 +1,146 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+// Roles in ascending order of privilege
+const (
+	RoleUser           = "user"
+	RoleTrustedCreator = "trusted_creator"
+	RoleModerator      = "moderator"
+	RoleAdmin          = "admin"
+)
+
+// RoleChangedByCLI identifies role changes made with `gw2style roles set`
+const RoleChangedByCLI = "cli"
+
+var (
+	ErrUserNotFound  = errors.New("user not found")
+	ErrRoleUnchanged = errors.New("user already has this role")
+)
+
+var roleRanks = map[string]int{
+	RoleUser:           0,
+	RoleTrustedCreator: 1,
+	RoleModerator:      2,
+	RoleAdmin:          3,
+}
+
+// ValidRole reports whether role is a known role
+func ValidRole(role string) bool {
+	_, ok := roleRanks[role]
+	return ok
+}
+
+// HasRole reports whether role grants at least the privileges of required
+func HasRole(role, required string) bool {
+	rank, ok := roleRanks[role]
+	return ok && rank >= roleRanks[required]
+}
+
+type RoleChange struct {
+	ID        int    `json:"id"`
+	UserID    string `json:"user_id"`
+	Username  string `json:"username"`
+	OldRole   string `json:"old_role"`
+	NewRole   string `json:"new_role"`
+	ChangedBy string `json:"changed_by"`
+	Reason    string `json:"reason"`
+	CreatedAt string `json:"created_at"`
+}
+
+type RoleRepository struct {
+	db *sql.DB
+}
+
+func NewRoleRepository(db *sql.DB) *RoleRepository {
+	return &RoleRepository{db: db}
+}
+
+// GetUserRole returns the user's current role
+func (r *RoleRepository) GetUserRole(ctx context.Context, userID string) (string, error) {
+	var role string
+	err := r.db.QueryRowContext(ctx, `SELECT role FROM users WHERE id = $1`, userID).Scan(&role)
+	if err == sql.ErrNoRows {
+		return "", ErrUserNotFound
+	}
+	if err != nil {
+		return "", fmt.Errorf("error getting role: %w", err)
+	}
+	return role, nil
+}
+
+// SetUserRole changes the user's role and records the change in the audit log
+func (r *RoleRepository) SetUserRole(ctx context.Context, userID, role, changedBy, reason string) (*RoleChange, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	change := RoleChange{UserID: userID, NewRole: role, ChangedBy: changedBy, Reason: reason}
+	err = tx.QueryRowContext(ctx, `SELECT username, role FROM users WHERE id = $1 FOR UPDATE`, userID).
+		Scan(&change.Username, &change.OldRole)
+	if err == sql.ErrNoRows {
+		return nil, ErrUserNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error getting user: %w", err)
+	}
+
+	if change.OldRole == role {
+		return nil, ErrRoleUnchanged
+	}
+
+	if _, err = tx.ExecContext(ctx, `UPDATE users SET role = $2 WHERE id = $1`, userID, role); err != nil {
+		return nil, fmt.Errorf("error updating role: %w", err)
+	}
+
+	query := `
+		INSERT INTO role_audit_log (user_id, old_role, new_role, changed_by, reason)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, to_char(created_at, 'YYYY-MM-DD"T"HH24:MI:SS"Z"')`
+	err = tx.QueryRowContext(ctx, query, userID, change.OldRole, role, changedBy, reason).Scan(&change.ID, &change.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("error logging role change: %w", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("error committing transaction: %w", err)
+	}
+
+	return &change, nil
+}
+
+// GetRoleChanges lists role changes, newest first, optionally only those of one user
+func (r *RoleRepository) GetRoleChanges(ctx context.Context, userID string, limit, offset int) ([]RoleChange, error) {
+	query := `
+		SELECT l.id, l.user_id, u.username, l.old_role, l.new_role, l.changed_by,
+			COALESCE(l.reason, ''), to_char(l.created_at, 'YYYY-MM-DD"T"HH24:MI:SS"Z"')
+		FROM role_audit_log l
+		JOIN users u ON u.id = l.user_id
+		WHERE $1 = '' OR l.user_id = $1
+		ORDER BY l.created_at DESC, l.id DESC
+		LIMIT $2 OFFSET $3`
+
+	rows, err := r.db.QueryContext(ctx, query, userID, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("error getting role changes: %w", err)
+	}
+	defer rows.Close()
+
+	changes := []RoleChange{}
+	for rows.Next() {
+		var c RoleChange
+		if err := rows.Scan(&c.ID, &c.UserID, &c.Username, &c.OldRole, &c.NewRole, &c.ChangedBy, &c.Reason, &c.CreatedAt); err != nil {
+			return nil, err
+		}
+		changes = append(changes, c)
+	}
+
+	return changes, rows.Err()
+}
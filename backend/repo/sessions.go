@@ -0,0 +1,301 @@
+package repo
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrSessionNotFound    = errors.New("session not found")
+	ErrSessionInactive    = errors.New("session revoked or expired")
+	ErrRefreshTokenReused = errors.New("refresh token reused")
+)
+
+// Reasons recorded when a session is revoked
+const (
+	SessionRevokedLogout      = "logout"
+	SessionRevokedByUser      = "revoked_by_user"
+	SessionRevokedTokenReused = "refresh_token_reused"
+	SessionRevokedKeyInvalid  = "api_key_invalid"
+)
+
+type Session struct {
+	ID         string `json:"id"`
+	UserID     string `json:"-"`
+	UserAgent  string `json:"user_agent"`
+	IPAddress  string `json:"ip_address"`
+	CreatedAt  string `json:"created_at"`
+	LastUsedAt string `json:"last_used_at"`
+	ExpiresAt  string `json:"expires_at"`
+}
+
+type SessionRepository struct {
+	db *sql.DB
+}
+
+func NewSessionRepository(db *sql.DB) *SessionRepository {
+	return &SessionRepository{
+		db: db,
+	}
+}
+
+// newRefreshToken returns a random refresh token and the hash stored for it
+func newRefreshToken() (token, hash string, err error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", "", fmt.Errorf("error generating refresh token: %w", err)
+	}
+	token = base64.RawURLEncoding.EncodeToString(buf)
+	return token, hashRefreshToken(token), nil
+}
+
+func hashRefreshToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// CreateSession starts a session for a user and returns it with its first refresh token
+func (r *SessionRepository) CreateSession(ctx context.Context, userID, userAgent, ipAddress string, ttl time.Duration) (*Session, string, error) {
+	token, hash, err := newRefreshToken()
+	if err != nil {
+		return nil, "", err
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, "", fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	session := Session{ID: uuid.NewString(), UserID: userID, UserAgent: userAgent, IPAddress: ipAddress}
+	query := `
+		INSERT INTO sessions (id, user_id, user_agent, ip_address, expires_at)
+		VALUES ($1, $2, $3, $4, NOW() + $5::bigint * INTERVAL '1 second')
+		RETURNING
+			to_char(created_at, 'YYYY-MM-DD"T"HH24:MI:SS"Z"'),
+			to_char(last_used_at, 'YYYY-MM-DD"T"HH24:MI:SS"Z"'),
+			to_char(expires_at, 'YYYY-MM-DD"T"HH24:MI:SS"Z"')`
+	err = tx.QueryRowContext(ctx, query, session.ID, userID, userAgent, ipAddress, int64(ttl.Seconds())).
+		Scan(&session.CreatedAt, &session.LastUsedAt, &session.ExpiresAt)
+	if err != nil {
+		return nil, "", fmt.Errorf("error creating session: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx, `INSERT INTO refresh_tokens (token_hash, session_id) VALUES ($1, $2)`, hash, session.ID)
+	if err != nil {
+		return nil, "", fmt.Errorf("error storing refresh token: %w", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, "", fmt.Errorf("error committing transaction: %w", err)
+	}
+
+	return &session, token, nil
+}
+
+// RotateRefreshToken exchanges a refresh token for a new one and extends the
+// session by ttl. Presenting a token that was already exchanged revokes the
+// whole session and returns ErrRefreshTokenReused.
+//
+// issue runs before the rotation is committed, to build whatever is handed
+// out with the new token; when it fails the old token stays valid, so a
+// client retrying after a server error is not mistaken for a token thief.
+func (r *SessionRepository) RotateRefreshToken(ctx context.Context, token string, ttl time.Duration, issue func(*Session) error) (*Session, string, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, "", fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var session Session
+	var usedAt sql.NullTime
+	var active bool
+	query := `
+		SELECT s.id, s.user_id, rt.used_at, s.revoked_at IS NULL AND s.expires_at > NOW()
+		FROM refresh_tokens rt
+		JOIN sessions s ON s.id = rt.session_id
+		WHERE rt.token_hash = $1
+		FOR UPDATE OF rt, s`
+	err = tx.QueryRowContext(ctx, query, hashRefreshToken(token)).Scan(&session.ID, &session.UserID, &usedAt, &active)
+	if err == sql.ErrNoRows {
+		return nil, "", ErrSessionNotFound
+	}
+	if err != nil {
+		return nil, "", fmt.Errorf("error getting refresh token: %w", err)
+	}
+
+	if usedAt.Valid {
+		// The token was already exchanged, so someone else holds a copy of it
+		if active {
+			if err = revokeSession(ctx, tx, session.ID, SessionRevokedTokenReused); err != nil {
+				return nil, "", err
+			}
+			if err = tx.Commit(); err != nil {
+				return nil, "", fmt.Errorf("error committing transaction: %w", err)
+			}
+		}
+		return nil, "", ErrRefreshTokenReused
+	}
+
+	if !active {
+		return nil, "", ErrSessionInactive
+	}
+
+	newToken, newHash, err := newRefreshToken()
+	if err != nil {
+		return nil, "", err
+	}
+
+	_, err = tx.ExecContext(ctx, `UPDATE refresh_tokens SET used_at = NOW() WHERE token_hash = $1`, hashRefreshToken(token))
+	if err != nil {
+		return nil, "", fmt.Errorf("error marking refresh token used: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx, `INSERT INTO refresh_tokens (token_hash, session_id) VALUES ($1, $2)`, newHash, session.ID)
+	if err != nil {
+		return nil, "", fmt.Errorf("error storing refresh token: %w", err)
+	}
+
+	updateQuery := `
+		UPDATE sessions
+		SET last_used_at = NOW(), expires_at = NOW() + $2::bigint * INTERVAL '1 second'
+		WHERE id = $1
+		RETURNING
+			user_agent, ip_address,
+			to_char(created_at, 'YYYY-MM-DD"T"HH24:MI:SS"Z"'),
+			to_char(last_used_at, 'YYYY-MM-DD"T"HH24:MI:SS"Z"'),
+			to_char(expires_at, 'YYYY-MM-DD"T"HH24:MI:SS"Z"')`
+	err = tx.QueryRowContext(ctx, updateQuery, session.ID, int64(ttl.Seconds())).
+		Scan(&session.UserAgent, &session.IPAddress, &session.CreatedAt, &session.LastUsedAt, &session.ExpiresAt)
+	if err != nil {
+		return nil, "", fmt.Errorf("error extending session: %w", err)
+	}
+
+	if err = issue(&session); err != nil {
+		return nil, "", err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, "", fmt.Errorf("error committing transaction: %w", err)
+	}
+
+	return &session, newToken, nil
+}
+
+// IsSessionActive reports whether a session of the user exists and is neither revoked nor expired
+func (r *SessionRepository) IsSessionActive(ctx context.Context, sessionID, userID string) (bool, error) {
+	if _, err := uuid.Parse(sessionID); err != nil {
+		return false, nil
+	}
+
+	var active bool
+	query := `
+		SELECT EXISTS (
+			SELECT 1 FROM sessions
+			WHERE id = $1 AND user_id = $2 AND revoked_at IS NULL AND expires_at > NOW()
+		)`
+	if err := r.db.QueryRowContext(ctx, query, sessionID, userID).Scan(&active); err != nil {
+		return false, fmt.Errorf("error checking session: %w", err)
+	}
+
+	return active, nil
+}
+
+// GetUserSessions lists a user's active sessions, most recently used first
+func (r *SessionRepository) GetUserSessions(ctx context.Context, userID string) ([]Session, error) {
+	query := `
+		SELECT
+			CAST(id AS TEXT), user_id, user_agent, ip_address,
+			to_char(created_at, 'YYYY-MM-DD"T"HH24:MI:SS"Z"'),
+			to_char(last_used_at, 'YYYY-MM-DD"T"HH24:MI:SS"Z"'),
+			to_char(expires_at, 'YYYY-MM-DD"T"HH24:MI:SS"Z"')
+		FROM sessions
+		WHERE user_id = $1 AND revoked_at IS NULL AND expires_at > NOW()
+		ORDER BY last_used_at DESC`
+
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("error getting sessions: %w", err)
+	}
+	defer rows.Close()
+
+	sessions := []Session{}
+	for rows.Next() {
+		var s Session
+		if err := rows.Scan(&s.ID, &s.UserID, &s.UserAgent, &s.IPAddress, &s.CreatedAt, &s.LastUsedAt, &s.ExpiresAt); err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, s)
+	}
+
+	return sessions, rows.Err()
+}
+
+// RevokeSession ends one of the user's active sessions
+func (r *SessionRepository) RevokeSession(ctx context.Context, sessionID, userID, reason string) error {
+	if _, err := uuid.Parse(sessionID); err != nil {
+		return ErrSessionNotFound
+	}
+
+	query := `
+		UPDATE sessions SET revoked_at = NOW(), revoked_reason = $3
+		WHERE id = $1 AND user_id = $2 AND revoked_at IS NULL`
+	result, err := r.db.ExecContext(ctx, query, sessionID, userID, reason)
+	if err != nil {
+		return fmt.Errorf("error revoking session: %w", err)
+	}
+
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return ErrSessionNotFound
+	}
+
+	return nil
+}
+
+// RevokeUserSessions ends every active session of the user and returns how many were ended
+func (r *SessionRepository) RevokeUserSessions(ctx context.Context, userID, reason string) (int64, error) {
+	query := `
+		UPDATE sessions SET revoked_at = NOW(), revoked_reason = $2
+		WHERE user_id = $1 AND revoked_at IS NULL`
+	result, err := r.db.ExecContext(ctx, query, userID, reason)
+	if err != nil {
+		return 0, fmt.Errorf("error revoking sessions: %w", err)
+	}
+
+	return result.RowsAffected()
+}
+
+// RevokeSessionByRefreshToken ends the session a refresh token belongs to
+func (r *SessionRepository) RevokeSessionByRefreshToken(ctx context.Context, token, reason string) error {
+	query := `
+		UPDATE sessions SET revoked_at = NOW(), revoked_reason = $2
+		WHERE revoked_at IS NULL
+		  AND id = (SELECT session_id FROM refresh_tokens WHERE token_hash = $1)`
+	result, err := r.db.ExecContext(ctx, query, hashRefreshToken(token), reason)
+	if err != nil {
+		return fmt.Errorf("error revoking session: %w", err)
+	}
+
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return ErrSessionNotFound
+	}
+
+	return nil
+}
+
+func revokeSession(ctx context.Context, tx *sql.Tx, sessionID, reason string) error {
+	_, err := tx.ExecContext(ctx, `UPDATE sessions SET revoked_at = NOW(), revoked_reason = $2 WHERE id = $1`, sessionID, reason)
+	if err != nil {
+		return fmt.Errorf("error revoking session: %w", err)
+	}
+	return nil
+}
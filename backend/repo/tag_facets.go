@@ -0,0 +1,79 @@
+package repo
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/lib/pq"
+)
+
+// facetCategories are the tag categories counted for search facets
+var facetCategories = []TagCategory{
+	TagCategoryRace,
+	TagCategoryGender,
+	TagCategoryArmorWeight,
+	TagCategoryClass,
+	TagCategoryDyeColor,
+}
+
+type TagFacet struct {
+	Tag   string `json:"tag"`
+	Count int    `json:"count"`
+}
+
+// GetTagFacets counts how many posts matching the search carry each facet tag.
+// Every facet tag is returned, including those with no matching posts.
+func (r *PostRepository) GetTagFacets(ctx context.Context, params SearchParams) (map[TagCategory][]TagFacet, error) {
+	q, err := searchQuery(params)
+	if err != nil {
+		return nil, err
+	}
+
+	where := "true"
+	if len(q.where) > 0 {
+		where = strings.Join(q.where, " AND ")
+	}
+
+	categories := make([]string, len(facetCategories))
+	for i, category := range facetCategories {
+		categories[i] = string(category)
+	}
+	args := append(q.args, pq.Array(categories))
+
+	query := `
+		SELECT tg.category, tg.display_name, COUNT(matched.id)
+		FROM tags tg
+		LEFT JOIN (
+			SELECT posts.id, t.tag
+			FROM posts
+			CROSS JOIN LATERAL jsonb_array_elements_text(
+				CASE WHEN jsonb_typeof(tags) = 'array' THEN tags ELSE '[]'::jsonb END
+			) AS t(tag)
+			WHERE ` + where + `
+		) matched ON matched.tag = tg.display_name
+		WHERE tg.category = ANY($` + fmt.Sprint(len(args)) + `)
+		GROUP BY tg.id, tg.category, tg.display_name
+		ORDER BY tg.id`
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error counting tag facets: %w", err)
+	}
+	defer rows.Close()
+
+	facets := make(map[TagCategory][]TagFacet, len(facetCategories))
+	for _, category := range facetCategories {
+		facets[category] = []TagFacet{}
+	}
+	for rows.Next() {
+		var category TagCategory
+		var facet TagFacet
+		if err := rows.Scan(&category, &facet.Tag, &facet.Count); err != nil {
+			return nil, err
+		}
+		facets[category] = append(facets[category], facet)
+	}
+
+	return facets, rows.Err()
+}
@@ -0,0 +1,283 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/lib/pq"
+)
+
+var (
+	ErrTagNotFound = errors.New("tag not found")
+	ErrTagExists   = errors.New("tag already exists")
+)
+
+// TagCategory groups the tags of the taxonomy
+type TagCategory string
+
+const (
+	TagCategoryRace        TagCategory = "race"
+	TagCategoryGender      TagCategory = "gender"
+	TagCategoryArmorWeight TagCategory = "armor_weight"
+	TagCategoryClass       TagCategory = "class"
+	TagCategoryDyeColor    TagCategory = "dye_color"
+	TagCategorySource      TagCategory = "source"
+	TagCategoryArmorSkin   TagCategory = "armor_skin"
+)
+
+// TagCategories lists every category in display order
+var TagCategories = []TagCategory{
+	TagCategoryRace,
+	TagCategoryGender,
+	TagCategoryArmorWeight,
+	TagCategoryClass,
+	TagCategoryDyeColor,
+	TagCategorySource,
+	TagCategoryArmorSkin,
+}
+
+// ValidTagCategory reports whether c is a known tag category
+func ValidTagCategory(c string) bool {
+	for _, category := range TagCategories {
+		if string(category) == c {
+			return true
+		}
+	}
+	return false
+}
+
+type Tag struct {
+	ID          int         `json:"id"`
+	Slug        string      `json:"slug"`
+	DisplayName string      `json:"display_name"`
+	Category    TagCategory `json:"category"`
+	Aliases     []string    `json:"aliases"`
+}
+
+type TagRepository struct {
+	db *sql.DB
+}
+
+func NewTagRepository(db *sql.DB) *TagRepository {
+	return &TagRepository{
+		db: db,
+	}
+}
+
+// Slugify turns a tag name into its lookup key, e.g. "Red dyes" becomes "red-dyes"
+func Slugify(name string) string {
+	words := strings.FieldsFunc(strings.ToLower(name), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+	return strings.Join(words, "-")
+}
+
+// GetTags returns every tag ordered by category and creation
+func (r *TagRepository) GetTags(ctx context.Context) ([]Tag, error) {
+	query := `
+		SELECT id, slug, display_name, category, aliases
+		FROM tags
+		ORDER BY category, id`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("error getting tags: %w", err)
+	}
+	defer rows.Close()
+
+	tags := []Tag{}
+	for rows.Next() {
+		var tag Tag
+		if err := rows.Scan(&tag.ID, &tag.Slug, &tag.DisplayName, &tag.Category, pq.Array(&tag.Aliases)); err != nil {
+			return nil, err
+		}
+		tags = append(tags, tag)
+	}
+
+	return tags, rows.Err()
+}
+
+// GetTagBySlug returns a tag by its slug or one of its aliases
+func (r *TagRepository) GetTagBySlug(ctx context.Context, slug string) (*Tag, error) {
+	query := `
+		SELECT id, slug, display_name, category, aliases
+		FROM tags
+		WHERE slug = $1 OR $1 = ANY(aliases)
+		ORDER BY slug = $1 DESC
+		LIMIT 1`
+
+	var tag Tag
+	err := r.db.QueryRowContext(ctx, query, slug).Scan(&tag.ID, &tag.Slug, &tag.DisplayName, &tag.Category, pq.Array(&tag.Aliases))
+	if err == sql.ErrNoRows {
+		return nil, ErrTagNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error getting tag: %w", err)
+	}
+
+	return &tag, nil
+}
+
+// NormalizeTags maps submitted tag names to their canonical display names by
+// matching slugs and aliases. Duplicates are dropped and the order is kept.
+// Names that match no tag are returned as unknown.
+func (r *TagRepository) NormalizeTags(ctx context.Context, names []string) (canonical []string, unknown []string, err error) {
+	if len(names) == 0 {
+		return []string{}, nil, nil
+	}
+
+	slugs := make([]string, len(names))
+	for i, name := range names {
+		slugs[i] = Slugify(name)
+	}
+
+	query := `
+		SELECT DISTINCT ON (s.slug) s.slug, t.display_name
+		FROM unnest($1::text[]) AS s(slug)
+		JOIN tags t ON t.slug = s.slug OR s.slug = ANY(t.aliases)
+		ORDER BY s.slug, t.slug = s.slug DESC`
+
+	rows, err := r.db.QueryContext(ctx, query, pq.Array(slugs))
+	if err != nil {
+		return nil, nil, fmt.Errorf("error normalizing tags: %w", err)
+	}
+	defer rows.Close()
+
+	displayNames := map[string]string{}
+	for rows.Next() {
+		var slug, displayName string
+		if err := rows.Scan(&slug, &displayName); err != nil {
+			return nil, nil, err
+		}
+		displayNames[slug] = displayName
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	canonical = []string{}
+	seen := map[string]bool{}
+	for i, name := range names {
+		displayName, ok := displayNames[slugs[i]]
+		if !ok {
+			unknown = append(unknown, name)
+			continue
+		}
+		if !seen[displayName] {
+			seen[displayName] = true
+			canonical = append(canonical, displayName)
+		}
+	}
+
+	return canonical, unknown, nil
+}
+
+// CreateTag adds a tag to the taxonomy. The slug and aliases are slugified and
+// must not collide with any existing slug or alias.
+func (r *TagRepository) CreateTag(ctx context.Context, tag Tag) (*Tag, error) {
+	if tag.Slug == "" {
+		tag.Slug = tag.DisplayName
+	}
+	tag.Slug = Slugify(tag.Slug)
+
+	aliases := []string{}
+	for _, alias := range tag.Aliases {
+		if slug := Slugify(alias); slug != "" && slug != tag.Slug {
+			aliases = append(aliases, slug)
+		}
+	}
+	tag.Aliases = aliases
+
+	query := `
+		INSERT INTO tags (slug, display_name, category, aliases)
+		SELECT $1::text, $2::text, $3::text, $4::text[]
+		WHERE NOT EXISTS (
+			SELECT 1 FROM tags
+			WHERE slug = ANY($1::text || $4::text[])
+			   OR aliases && ($1::text || $4::text[])
+			   OR lower(display_name) = lower($2::text)
+		)
+		RETURNING id`
+
+	err := r.db.QueryRowContext(ctx, query, tag.Slug, tag.DisplayName, tag.Category, pq.Array(tag.Aliases)).Scan(&tag.ID)
+	if err == sql.ErrNoRows {
+		return nil, ErrTagExists
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error creating tag: %w", err)
+	}
+
+	return &tag, nil
+}
+
+// MergeTag folds one tag into another: posts carrying the source tag get the
+// target tag instead, and the source slug and aliases become target aliases so
+// old spellings keep resolving. The source tag is deleted.
+func (r *TagRepository) MergeTag(ctx context.Context, sourceSlug, targetSlug string) (*Tag, int64, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	lockQuery := `
+		SELECT id, slug, display_name, category, aliases
+		FROM tags
+		WHERE slug = $1
+		FOR UPDATE`
+
+	var source, target Tag
+	for _, t := range []struct {
+		slug string
+		tag  *Tag
+	}{{sourceSlug, &source}, {targetSlug, &target}} {
+		err := tx.QueryRowContext(ctx, lockQuery, t.slug).Scan(&t.tag.ID, &t.tag.Slug, &t.tag.DisplayName, &t.tag.Category, pq.Array(&t.tag.Aliases))
+		if err == sql.ErrNoRows {
+			return nil, 0, fmt.Errorf("%w: %s", ErrTagNotFound, t.slug)
+		}
+		if err != nil {
+			return nil, 0, fmt.Errorf("error getting tag: %w", err)
+		}
+	}
+
+	// Replace the source tag in every post, keeping the first position of each tag
+	postsQuery := `
+		UPDATE posts SET tags = (
+			SELECT COALESCE(jsonb_agg(tag ORDER BY position), '[]'::jsonb)
+			FROM (
+				SELECT CASE WHEN e.tag = $1 THEN $2 ELSE e.tag END AS tag, MIN(e.position) AS position
+				FROM jsonb_array_elements_text(posts.tags) WITH ORDINALITY AS e(tag, position)
+				GROUP BY 1
+			) merged
+		)
+		WHERE jsonb_typeof(tags) = 'array' AND tags ? $1`
+	result, err := tx.ExecContext(ctx, postsQuery, source.DisplayName, target.DisplayName)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error retagging posts: %w", err)
+	}
+	postsUpdated, _ := result.RowsAffected()
+
+	if _, err = tx.ExecContext(ctx, `DELETE FROM tags WHERE id = $1`, source.ID); err != nil {
+		return nil, 0, fmt.Errorf("error deleting merged tag: %w", err)
+	}
+
+	aliasQuery := `
+		UPDATE tags
+		SET aliases = ARRAY(SELECT DISTINCT unnest(aliases || $2::text[]))
+		WHERE id = $1
+		RETURNING aliases`
+	newAliases := append([]string{source.Slug}, source.Aliases...)
+	if err = tx.QueryRowContext(ctx, aliasQuery, target.ID, pq.Array(newAliases)).Scan(pq.Array(&target.Aliases)); err != nil {
+		return nil, 0, fmt.Errorf("error updating tag aliases: %w", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, 0, fmt.Errorf("error committing transaction: %w", err)
+	}
+
+	return &target, postsUpdated, nil
+}
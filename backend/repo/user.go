@@ -1,56 +1,353 @@
 package repo
 
 import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+
+	"github.com/NesoHQ/gw2style/apikey"
 )
 
 type User struct {
 	ID     string `json:"id" db:"id"`
 	Name   string `json:"name" db:"username"`
-	ApiKey string `json:"api_key" db:"api_key"`
+	ApiKey string `json:"-" db:"-"` // Decrypted, never stored or serialized as is
+	// Set when the stored key cannot be decrypted, e.g. after its master key
+	// was removed from the config. ApiKey is empty until the key is replaced.
+	ApiKeyUndecryptable bool `json:"-" db:"-"`
+	// Permissions GW2 reported for the key when it was last validated
+	ApiKeyPermissions []string   `json:"api_key_permissions" db:"api_key_permissions"`
+	ApiKeyValidatedAt *time.Time `json:"api_key_validated_at" db:"api_key_validated_at"`
+	// Set when the revalidation job found the key revoked
+	ApiKeyInvalidatedAt *time.Time `json:"api_key_invalidated_at" db:"api_key_invalidated_at"`
 }
 
 type UserRepo interface {
 	Create(User) (*User, error)
 	FindUser(ID string) (*User, error)
 	FindUserByAPIKey(apiKey string) (*User, error)
+	EncryptAPIKeys(batchSize int) (int, error)
+	FindUsersDueForRevalidation(checkedBefore time.Time, limit int) ([]User, error)
+	MarkAPIKeyValid(ID, name string, permissions []string) error
+	MarkAPIKeyInvalid(ID string) error
+	MarkAPIKeyChecked(ID string) error
+	ReplaceAPIKey(User) (*User, error)
 }
 
 type userRepo struct {
-	db *sqlx.DB
+	db     *sqlx.DB
+	cipher *apikey.Cipher
 }
 
-func NewUserRepo(db *sqlx.DB) UserRepo {
+func NewUserRepo(db *sqlx.DB, cipher *apikey.Cipher) UserRepo {
 	return &userRepo{
-		db: db,
+		db:     db,
+		cipher: cipher,
 	}
 }
 
 func (r *userRepo) Create(newUser User) (*User, error) {
+	encrypted, err := r.cipher.Encrypt(newUser.ApiKey, newUser.ID)
+	if err != nil {
+		return nil, err
+	}
+
 	query := `INSERT INTO users 
-				(id, username, api_key) 
-				VALUES ($1, $2, $3)`
-	_, err := r.db.Exec(query, newUser.ID, newUser.Name, newUser.ApiKey)
+				(id, username, api_key_encrypted, api_key_hash, api_key_version, api_key_permissions, api_key_validated_at, api_key_checked_at) 
+				VALUES ($1, $2, $3, $4, $5, $6, NOW(), NOW())
+				RETURNING api_key_validated_at`
+	err = r.db.QueryRow(query,
+		newUser.ID,
+		newUser.Name,
+		encrypted,
+		r.cipher.Hash(newUser.ApiKey),
+		r.cipher.CurrentVersion(),
+		pq.Array(newUser.ApiKeyPermissions),
+	).Scan(&newUser.ApiKeyValidatedAt)
 	if err != nil {
 		return nil, err
 	}
 	return &newUser, nil
 }
 
-func (u *userRepo) FindUser(ID string) (*User, error) {
-	var user User
-	err := u.db.QueryRow("SELECT id, api_key FROM users WHERE id = $1", ID).Scan(&user.ID, &user.ApiKey)
+// ReplaceAPIKey stores a new, already validated key for an existing user,
+// together with the account name and permissions GW2 reported for it
+func (r *userRepo) ReplaceAPIKey(user User) (*User, error) {
+	encrypted, err := r.cipher.Encrypt(user.ApiKey, user.ID)
 	if err != nil {
 		return nil, err
 	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if err := renameUser(tx, user.ID, user.Name); err != nil {
+		return nil, err
+	}
+
+	query := `UPDATE users
+				SET username = $2,
+				    api_key = NULL,
+				    api_key_encrypted = $3,
+				    api_key_hash = $4,
+				    api_key_version = $5,
+				    api_key_permissions = $6,
+				    api_key_validated_at = NOW(),
+				    api_key_checked_at = NOW(),
+				    api_key_invalidated_at = NULL
+				WHERE id = $1
+				RETURNING api_key_validated_at`
+	err = tx.QueryRow(query,
+		user.ID,
+		user.Name,
+		encrypted,
+		r.cipher.Hash(user.ApiKey),
+		r.cipher.CurrentVersion(),
+		pq.Array(user.ApiKeyPermissions),
+	).Scan(&user.ApiKeyValidatedAt)
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	user.ApiKeyInvalidatedAt = nil
 	return &user, nil
 }
 
-func (u *userRepo) FindUserByAPIKey(apiKey string) (*User, error) {
+// renameUser carries an account rename over to the tables that reference the
+// user by name without a foreign key. posts.author_name follows on its own
+// through ON UPDATE CASCADE. It locks the user row, so run it in the
+// transaction that updates users.username.
+func renameUser(tx *sql.Tx, ID, name string) error {
+	var current string
+	err := tx.QueryRow(`SELECT username FROM users WHERE id = $1 FOR UPDATE`, ID).Scan(&current)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && current == name) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`UPDATE reports SET reporter_username = $2 WHERE reporter_username = $1`, current, name)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`UPDATE post_revisions SET edited_by = $2 WHERE edited_by = $1`, current, name)
+	return err
+}
+
+const userColumns = `id, username, api_key_encrypted, api_key_permissions, api_key_validated_at, api_key_invalidated_at`
+
+// scanUser reads a row selected with userColumns and decrypts its API key.
+// A key that cannot be decrypted is logged and flagged instead of failing, so
+// the user can still log in, replace the key or delete their account.
+func (u *userRepo) scanUser(row interface{ Scan(...any) error }) (*User, error) {
 	var user User
-	err := u.db.QueryRow("SELECT id, username, api_key FROM users WHERE api_key = $1", apiKey).Scan(&user.ID, &user.Name, &user.ApiKey)
+	var encrypted sql.NullString
+	err := row.Scan(&user.ID, &user.Name, &encrypted, pq.Array(&user.ApiKeyPermissions), &user.ApiKeyValidatedAt, &user.ApiKeyInvalidatedAt)
 	if err != nil {
 		return nil, err
 	}
+
+	// Rows that have not been through `api-keys encrypt` yet have no usable key
+	if encrypted.Valid {
+		user.ApiKey, err = u.cipher.Decrypt(encrypted.String, user.ID)
+		if err != nil {
+			slog.Warn("Stored API key cannot be decrypted", "user_id", user.ID, "error", err.Error())
+			user.ApiKey = ""
+			user.ApiKeyUndecryptable = true
+		}
+	}
 	return &user, nil
 }
+
+func (u *userRepo) FindUser(ID string) (*User, error) {
+	return u.scanUser(u.db.QueryRow("SELECT "+userColumns+" FROM users WHERE id = $1", ID))
+}
+
+// FindUserByAPIKey looks the key up by its keyed hash; plaintext keys are never compared
+func (u *userRepo) FindUserByAPIKey(apiKey string) (*User, error) {
+	return u.scanUser(u.db.QueryRow("SELECT "+userColumns+" FROM users WHERE api_key_hash = $1", u.cipher.Hash(apiKey)))
+}
+
+// FindUsersDueForRevalidation returns up to limit users with a usable key that
+// was last checked before checkedBefore, least recently checked first. Keys
+// already marked invalid are skipped. Keys that cannot be decrypted are marked
+// checked, so they do not hold up the users behind them.
+func (u *userRepo) FindUsersDueForRevalidation(checkedBefore time.Time, limit int) ([]User, error) {
+	rows, err := u.db.Query(`
+		SELECT `+userColumns+`
+		FROM users
+		WHERE api_key_encrypted IS NOT NULL
+		  AND api_key_invalidated_at IS NULL
+		  AND (api_key_checked_at IS NULL OR api_key_checked_at < $1)
+		ORDER BY api_key_checked_at NULLS FIRST
+		LIMIT $2`, checkedBefore, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var users []User
+	var undecryptable []string
+	for rows.Next() {
+		user, err := u.scanUser(rows)
+		if err != nil {
+			return nil, err
+		}
+		if user.ApiKeyUndecryptable {
+			undecryptable = append(undecryptable, user.ID)
+			continue
+		}
+		users = append(users, *user)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for _, ID := range undecryptable {
+		if err := u.MarkAPIKeyChecked(ID); err != nil {
+			return nil, err
+		}
+	}
+	return users, nil
+}
+
+// MarkAPIKeyValid records a successful check of the user's key. name is the
+// account name GW2 reported; a rename is carried over to the user's posts,
+// reports and revisions, see renameUser.
+func (u *userRepo) MarkAPIKeyValid(ID, name string, permissions []string) error {
+	tx, err := u.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := renameUser(tx, ID, name); err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`
+		UPDATE users
+		SET username = $2,
+		    api_key_permissions = $3,
+		    api_key_validated_at = NOW(),
+		    api_key_checked_at = NOW(),
+		    api_key_invalidated_at = NULL
+		WHERE id = $1`, ID, name, pq.Array(permissions))
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// MarkAPIKeyChecked records a check of the user's key that neither confirmed
+// nor revoked it, so the key waits a full interval before it is tried again
+func (u *userRepo) MarkAPIKeyChecked(ID string) error {
+	_, err := u.db.Exec(`UPDATE users SET api_key_checked_at = NOW() WHERE id = $1`, ID)
+	return err
+}
+
+// MarkAPIKeyInvalid flags the user's key as revoked so login stops accepting it
+func (u *userRepo) MarkAPIKeyInvalid(ID string) error {
+	_, err := u.db.Exec(`UPDATE users SET api_key_invalidated_at = NOW() WHERE id = $1 AND api_key_invalidated_at IS NULL`, ID)
+	return err
+}
+
+// EncryptAPIKeys encrypts keys still stored in plaintext and re-encrypts keys
+// sealed with an older master key, batchSize rows per transaction. It returns
+// the number of users updated.
+func (u *userRepo) EncryptAPIKeys(batchSize int) (int, error) {
+	version := u.cipher.CurrentVersion()
+	updated := 0
+
+	for {
+		n, err := u.encryptAPIKeyBatch(version, batchSize)
+		updated += n
+		if err != nil {
+			return updated, err
+		}
+		if n < batchSize {
+			return updated, nil
+		}
+	}
+}
+
+func (u *userRepo) encryptAPIKeyBatch(version string, batchSize int) (int, error) {
+	tx, err := u.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.Query(`
+		SELECT id, api_key, api_key_encrypted
+		FROM users
+		WHERE api_key IS NOT NULL
+		   OR (api_key_encrypted IS NOT NULL AND api_key_version IS DISTINCT FROM $1)
+		ORDER BY id
+		LIMIT $2
+		FOR UPDATE`, version, batchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	type pending struct {
+		id, apiKey string
+	}
+	var batch []pending
+	for rows.Next() {
+		var id string
+		var plaintext, encrypted sql.NullString
+		if err := rows.Scan(&id, &plaintext, &encrypted); err != nil {
+			rows.Close()
+			return 0, err
+		}
+
+		apiKey := plaintext.String
+		if !plaintext.Valid {
+			apiKey, err = u.cipher.Decrypt(encrypted.String, id)
+			if err != nil {
+				rows.Close()
+				return 0, fmt.Errorf("user %s: %w", id, err)
+			}
+		}
+		batch = append(batch, pending{id: id, apiKey: apiKey})
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	for _, p := range batch {
+		encrypted, err := u.cipher.Encrypt(p.apiKey, p.id)
+		if err != nil {
+			return 0, err
+		}
+		_, err = tx.Exec(`
+			UPDATE users
+			SET api_key = NULL,
+			    api_key_encrypted = $2,
+			    api_key_hash = $3,
+			    api_key_version = $4
+			WHERE id = $1`, p.id, encrypted, u.cipher.Hash(p.apiKey), version)
+		if err != nil {
+			return 0, fmt.Errorf("user %s: %w", p.id, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	return len(batch), nil
+}
@@ -0,0 +1,153 @@
+package handlers
+
+import (
+	"archive/zip"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+
+	"github.com/NesoHQ/gw2style/repo"
+	"github.com/NesoHQ/gw2style/rest/utils"
+)
+
+// ExportUserDataHandler handles GET /api/v1/user/export
+// Downloads everything stored about the user as one JSON file, or with
+// ?format=zip as a ZIP archive holding one JSON file per section
+func (h *Handlers) ExportUserDataHandler(w http.ResponseWriter, r *http.Request) {
+	user, err := utils.GetUserFromContext(r.Context())
+	if err != nil {
+		utils.SendError(w, http.StatusUnauthorized, "unauthorized", err)
+		return
+	}
+
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = "json"
+	}
+	if format != "json" && format != "zip" {
+		utils.SendError(w, http.StatusBadRequest, `format must be "json" or "zip"`, nil)
+		return
+	}
+
+	export, err := h.accountRepo.ExportAccount(r.Context(), user.ID)
+	if errors.Is(err, repo.ErrUserNotFound) {
+		utils.SendError(w, http.StatusNotFound, "user not found", nil)
+		return
+	}
+	if err != nil {
+		utils.SendError(w, http.StatusInternalServerError, "failed to export account", err)
+		return
+	}
+
+	filename := fmt.Sprintf("gw2style-export-%s.%s", export.ExportedAt.Format("20060102"), format)
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+	w.Header().Set("Cache-Control", "no-store")
+
+	if format == "json" {
+		w.Header().Set("Content-Type", "application/json")
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		encoder.Encode(export)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/zip")
+	if err := writeExportZip(w, export); err != nil {
+		// Headers are already sent, so the client just gets a truncated archive
+		slog.Error("Failed to write account export", "user_id", user.ID, "error", err)
+	}
+}
+
+func writeExportZip(out io.Writer, export *repo.AccountExport) error {
+	files := []struct {
+		name string
+		data interface{}
+	}{
+		{"profile.json", map[string]interface{}{"exported_at": export.ExportedAt, "profile": export.Profile}},
+		{"posts.json", export.Posts},
+		{"likes.json", export.Likes},
+		{"reports.json", export.Reports},
+		{"moderation.json", export.Moderation},
+		{"bans.json", export.Bans},
+		{"role_changes.json", export.RoleChanges},
+	}
+
+	archive := zip.NewWriter(out)
+	for _, file := range files {
+		f, err := archive.Create(file.name)
+		if err != nil {
+			return err
+		}
+		encoder := json.NewEncoder(f)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(file.data); err != nil {
+			return err
+		}
+	}
+
+	return archive.Close()
+}
+
+// DeleteUserHandler handles DELETE /api/v1/user
+// Permanently deletes the account and its API key. The user chooses whether
+// their posts stay up without an author or are removed too, and has to repeat
+// their account name to confirm.
+func (h *Handlers) DeleteUserHandler(w http.ResponseWriter, r *http.Request) {
+	user, err := utils.GetUserFromContext(r.Context())
+	if err != nil {
+		utils.SendError(w, http.StatusUnauthorized, "unauthorized", err)
+		return
+	}
+
+	var req struct {
+		Posts   string `json:"posts"`   // "anonymize" or "delete"
+		Confirm string `json:"confirm"` // The account name, e.g. "PlayerName.1234"
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.SendError(w, http.StatusBadRequest, "invalid JSON body", nil)
+		return
+	}
+	if req.Posts != repo.DeletedPostsAnonymize && req.Posts != repo.DeletedPostsRemove {
+		utils.SendError(w, http.StatusBadRequest, repo.ErrInvalidPostDisposition.Error(), nil)
+		return
+	}
+
+	dbUser, err := h.repoUser.FindUser(user.ID)
+	if errors.Is(err, sql.ErrNoRows) {
+		utils.SendError(w, http.StatusNotFound, "user not found", nil)
+		return
+	}
+	if err != nil {
+		utils.SendError(w, http.StatusInternalServerError, "failed to fetch user data", err)
+		return
+	}
+	if req.Confirm != dbUser.Name {
+		utils.SendError(w, http.StatusBadRequest, "confirm must match your account name", nil)
+		return
+	}
+
+	deletion, err := h.accountRepo.DeleteAccount(r.Context(), user.ID, req.Posts)
+	if errors.Is(err, repo.ErrUserNotFound) {
+		utils.SendError(w, http.StatusNotFound, "user not found", nil)
+		return
+	}
+	if err != nil {
+		utils.SendError(w, http.StatusInternalServerError, "failed to delete account", err)
+		return
+	}
+
+	h.gw2.Invalidate(dbUser.ApiKey)
+	h.clearAuthCookies(w)
+
+	slog.Info("Account deleted", "user_id", user.ID, "posts", req.Posts)
+
+	utils.SendData(w, http.StatusOK, map[string]interface{}{
+		"success": true,
+		"message": "account deleted",
+		"data":    deletion,
+	})
+}
@@ -2,9 +2,12 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
+	"io"
 	"net/http"
 	"strconv"
 
+	"github.com/NesoHQ/gw2style/repo"
 	"github.com/NesoHQ/gw2style/rest/utils"
 )
 
@@ -19,6 +22,15 @@ type RejectPostRequest struct {
 	Reason             string `json:"reason"`
 }
 
+// moderatorIdentity replaces the moderator named in the body with the logged in
+// user when a web moderator, rather than the Discord bot, made the request
+func moderatorIdentity(r *http.Request, username, discordID *string) {
+	if user, err := utils.GetUserFromContext(r.Context()); err == nil {
+		*username = user.Name
+		*discordID = ""
+	}
+}
+
 // PublishPostHandler approves and publishes a post
 func (h *Handlers) PublishPostHandler(w http.ResponseWriter, r *http.Request) {
 	postID := r.PathValue("id")
@@ -34,10 +46,11 @@ func (h *Handlers) PublishPostHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	var req PublishPostRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
 		utils.SendError(w, http.StatusBadRequest, "invalid request body", err)
 		return
 	}
+	moderatorIdentity(r, &req.ModeratorUsername, &req.ModeratorDiscordID)
 
 	if req.ModeratorUsername == "" {
 		utils.SendError(w, http.StatusBadRequest, "moderator_username is required", nil)
@@ -45,6 +58,14 @@ func (h *Handlers) PublishPostHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	err = h.moderationRepo.PublishPost(r.Context(), postIDInt, req.ModeratorUsername, req.ModeratorDiscordID)
+	if errors.Is(err, repo.ErrPostNotFound) {
+		utils.SendError(w, http.StatusNotFound, "post not found", nil)
+		return
+	}
+	if errors.Is(err, repo.ErrInvalidTransition) {
+		utils.SendError(w, http.StatusConflict, err.Error(), nil)
+		return
+	}
 	if err != nil {
 		utils.SendError(w, http.StatusInternalServerError, "failed to publish post", err)
 		return
@@ -71,10 +92,11 @@ func (h *Handlers) RejectPostHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	var req RejectPostRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
 		utils.SendError(w, http.StatusBadRequest, "invalid request body", err)
 		return
 	}
+	moderatorIdentity(r, &req.ModeratorUsername, &req.ModeratorDiscordID)
 
 	if req.ModeratorUsername == "" {
 		utils.SendError(w, http.StatusBadRequest, "moderator_username is required", nil)
@@ -86,6 +108,14 @@ func (h *Handlers) RejectPostHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	err = h.moderationRepo.RejectPost(r.Context(), postIDInt, req.ModeratorUsername, req.ModeratorDiscordID, req.Reason)
+	if errors.Is(err, repo.ErrPostNotFound) {
+		utils.SendError(w, http.StatusNotFound, "post not found", nil)
+		return
+	}
+	if errors.Is(err, repo.ErrInvalidTransition) {
+		utils.SendError(w, http.StatusConflict, err.Error(), nil)
+		return
+	}
 	if err != nil {
 		utils.SendError(w, http.StatusInternalServerError, "failed to reject post", err)
 		return
@@ -0,0 +1,171 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/NesoHQ/gw2style/repo"
+	"github.com/NesoHQ/gw2style/rest/utils"
+)
+
+type CreateBanRequest struct {
+	// GW2 account ID, or the account name of a registered user
+	Account            string `json:"account"`
+	Reason             string `json:"reason"`
+	DurationHours      int    `json:"duration_hours"` // 0 bans permanently
+	ModeratorUsername  string `json:"moderator_username"`
+	ModeratorDiscordID string `json:"moderator_discord_id"`
+}
+
+type LiftBanRequest struct {
+	ModeratorUsername  string `json:"moderator_username"`
+	ModeratorDiscordID string `json:"moderator_discord_id"`
+	Reason             string `json:"reason"`
+}
+
+// rejectBanned answers 403 with the ban reason and its expiry when the account is
+// banned or suspended, and reports whether it did
+func (h *Handlers) rejectBanned(w http.ResponseWriter, r *http.Request, accountID string) bool {
+	ban, err := h.banRepo.GetActiveBan(r.Context(), accountID)
+	if err != nil {
+		utils.SendError(w, http.StatusInternalServerError, "failed to check ban", err)
+		return true
+	}
+	if ban != nil {
+		utils.SendError(w, http.StatusForbidden, ban.Message(), ban.Notice())
+		return true
+	}
+	return false
+}
+
+// CreateBanHandler handles POST /api/v1/admin/bans
+// Bans an account permanently or suspends it for duration_hours
+func (h *Handlers) CreateBanHandler(w http.ResponseWriter, r *http.Request) {
+	var req CreateBanRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.SendError(w, http.StatusBadRequest, "invalid request body", err)
+		return
+	}
+	moderatorIdentity(r, &req.ModeratorUsername, &req.ModeratorDiscordID)
+
+	switch {
+	case req.Account == "":
+		utils.SendError(w, http.StatusBadRequest, "account is required", nil)
+		return
+	case req.Reason == "":
+		utils.SendError(w, http.StatusBadRequest, "reason is required", nil)
+		return
+	case req.ModeratorUsername == "":
+		utils.SendError(w, http.StatusBadRequest, "moderator_username is required", nil)
+		return
+	case req.DurationHours < 0:
+		utils.SendError(w, http.StatusBadRequest, "duration_hours cannot be negative", nil)
+		return
+	}
+
+	accountID, err := h.banRepo.ResolveAccountID(r.Context(), req.Account)
+	if errors.Is(err, repo.ErrUnknownAccount) {
+		utils.SendError(w, http.StatusNotFound, err.Error(), nil)
+		return
+	}
+	if err != nil {
+		utils.SendError(w, http.StatusInternalServerError, "failed to resolve account", err)
+		return
+	}
+
+	if user, err := utils.GetUserFromContext(r.Context()); err == nil && user.ID == accountID {
+		utils.SendError(w, http.StatusBadRequest, "moderators cannot ban themselves", nil)
+		return
+	}
+
+	ban := repo.Ban{
+		AccountID:          accountID,
+		Reason:             req.Reason,
+		ModeratorUsername:  req.ModeratorUsername,
+		ModeratorDiscordID: req.ModeratorDiscordID,
+	}
+	if req.DurationHours > 0 {
+		expiresAt := time.Now().Add(time.Duration(req.DurationHours) * time.Hour)
+		ban.ExpiresAt = &expiresAt
+	}
+
+	created, err := h.banRepo.CreateBan(r.Context(), ban)
+	if err != nil {
+		utils.SendError(w, http.StatusInternalServerError, "failed to ban account", err)
+		return
+	}
+
+	utils.SendData(w, http.StatusCreated, map[string]interface{}{
+		"message": "account banned successfully",
+		"ban":     created,
+	})
+}
+
+// ListBansHandler handles GET /api/v1/admin/bans
+// Lists bans and suspensions that are in effect
+func (h *Handlers) ListBansHandler(w http.ResponseWriter, r *http.Request) {
+	page, limit := parsePage(r, 50)
+
+	bans, err := h.banRepo.GetActiveBans(r.Context(), limit, (page-1)*limit)
+	if err != nil {
+		utils.SendError(w, http.StatusInternalServerError, "failed to fetch bans", err)
+		return
+	}
+
+	utils.SendData(w, http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    bans,
+		"pagination": map[string]interface{}{
+			"page":  page,
+			"limit": limit,
+		},
+	})
+}
+
+// LiftBanHandler handles POST /api/v1/admin/bans/{account}/lift
+// Ends a ban or suspension early
+func (h *Handlers) LiftBanHandler(w http.ResponseWriter, r *http.Request) {
+	var req LiftBanRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		utils.SendError(w, http.StatusBadRequest, "invalid request body", err)
+		return
+	}
+	moderatorIdentity(r, &req.ModeratorUsername, &req.ModeratorDiscordID)
+
+	if req.ModeratorUsername == "" {
+		utils.SendError(w, http.StatusBadRequest, "moderator_username is required", nil)
+		return
+	}
+
+	if req.Reason == "" {
+		req.Reason = "Lifted by moderator"
+	}
+
+	accountID, err := h.banRepo.ResolveAccountID(r.Context(), r.PathValue("account"))
+	if errors.Is(err, repo.ErrUnknownAccount) {
+		utils.SendError(w, http.StatusNotFound, err.Error(), nil)
+		return
+	}
+	if err != nil {
+		utils.SendError(w, http.StatusInternalServerError, "failed to resolve account", err)
+		return
+	}
+
+	err = h.banRepo.LiftBan(r.Context(), accountID, req.ModeratorUsername, req.ModeratorDiscordID, req.Reason)
+	if errors.Is(err, repo.ErrBanNotFound) {
+		utils.SendError(w, http.StatusNotFound, err.Error(), nil)
+		return
+	}
+	if err != nil {
+		utils.SendError(w, http.StatusInternalServerError, "failed to lift ban", err)
+		return
+	}
+
+	utils.SendData(w, http.StatusOK, map[string]interface{}{
+		"message":    "ban lifted successfully",
+		"account_id": accountID,
+	})
+}
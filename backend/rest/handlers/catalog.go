@@ -0,0 +1,126 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/NesoHQ/gw2style/gw2api"
+	"github.com/NesoHQ/gw2style/repo"
+	"github.com/NesoHQ/gw2style/rest/utils"
+)
+
+const defaultCatalogLimit = 50
+
+// GetCatalogSkinsHandler handles GET /api/v1/catalog/skins
+func (h *Handlers) GetCatalogSkinsHandler(w http.ResponseWriter, r *http.Request) {
+	q, page, err := parseCatalogQuery(r, repo.CatalogSkins)
+	if err != nil {
+		utils.SendError(w, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+	skins, total, err := h.catalogRepo.ListSkins(r.Context(), q)
+	sendCatalogPage(w, skins, total, page, q, err)
+}
+
+// GetCatalogColorsHandler handles GET /api/v1/catalog/colors
+func (h *Handlers) GetCatalogColorsHandler(w http.ResponseWriter, r *http.Request) {
+	q, page, err := parseCatalogQuery(r, repo.CatalogColors)
+	if err != nil {
+		utils.SendError(w, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+	colors, total, err := h.catalogRepo.ListColors(r.Context(), q)
+	sendCatalogPage(w, colors, total, page, q, err)
+}
+
+// GetCatalogOutfitsHandler handles GET /api/v1/catalog/outfits
+func (h *Handlers) GetCatalogOutfitsHandler(w http.ResponseWriter, r *http.Request) {
+	q, page, err := parseCatalogQuery(r, repo.CatalogOutfits)
+	if err != nil {
+		utils.SendError(w, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+	outfits, total, err := h.catalogRepo.ListOutfits(r.Context(), q)
+	sendCatalogPage(w, outfits, total, page, q, err)
+}
+
+// GetCatalogGlidersHandler handles GET /api/v1/catalog/gliders
+func (h *Handlers) GetCatalogGlidersHandler(w http.ResponseWriter, r *http.Request) {
+	q, page, err := parseCatalogQuery(r, repo.CatalogGliders)
+	if err != nil {
+		utils.SendError(w, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+	gliders, total, err := h.catalogRepo.ListGliders(r.Context(), q)
+	sendCatalogPage(w, gliders, total, page, q, err)
+}
+
+// GetCatalogMountSkinsHandler handles GET /api/v1/catalog/mount-skins
+func (h *Handlers) GetCatalogMountSkinsHandler(w http.ResponseWriter, r *http.Request) {
+	q, page, err := parseCatalogQuery(r, repo.CatalogMountSkins)
+	if err != nil {
+		utils.SendError(w, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+	skins, total, err := h.catalogRepo.ListMountSkins(r.Context(), q)
+	sendCatalogPage(w, skins, total, page, q, err)
+}
+
+// parseCatalogQuery reads the q, ids, page and limit parameters and the
+// filters of the catalog. Catalogs are paged by page number only.
+func parseCatalogQuery(r *http.Request, kind repo.CatalogKind) (repo.CatalogQuery, int, error) {
+	pageReq, page, err := parsePageRequest(r, defaultCatalogLimit)
+	if err != nil || pageReq.Cursor != nil {
+		return repo.CatalogQuery{}, 0, errors.New("catalogs are paged with page and limit")
+	}
+
+	query := r.URL.Query()
+	q := repo.CatalogQuery{
+		Name:    strings.TrimSpace(query.Get("q")),
+		Filters: map[string]string{},
+		Limit:   pageReq.Limit,
+		Offset:  pageReq.Offset,
+	}
+
+	if ids := query.Get("ids"); ids != "" {
+		for _, part := range strings.Split(ids, ",") {
+			id, err := strconv.Atoi(strings.TrimSpace(part))
+			if err != nil || id <= 0 {
+				return q, 0, fmt.Errorf("invalid id %q", part)
+			}
+			q.IDs = append(q.IDs, id)
+		}
+		if len(q.IDs) > gw2api.MaxIDsPerRequest {
+			return q, 0, fmt.Errorf("at most %d ids", gw2api.MaxIDsPerRequest)
+		}
+	}
+
+	for _, param := range repo.CatalogFilters(kind) {
+		if value := query.Get(param); value != "" {
+			q.Filters[param] = value
+		}
+	}
+
+	return q, page, nil
+}
+
+func sendCatalogPage(w http.ResponseWriter, entries interface{}, total int, page int, q repo.CatalogQuery, err error) {
+	if err != nil {
+		utils.SendError(w, http.StatusInternalServerError, "failed to fetch catalog", err)
+		return
+	}
+
+	utils.SendData(w, http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    entries,
+		"pagination": map[string]interface{}{
+			"page":        page,
+			"limit":       q.Limit,
+			"total":       total,
+			"total_pages": (total + q.Limit - 1) / q.Limit,
+		},
+	})
+}
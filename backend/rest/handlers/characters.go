@@ -0,0 +1,259 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/NesoHQ/gw2style/equipment"
+	"github.com/NesoHQ/gw2style/gw2api"
+	"github.com/NesoHQ/gw2style/repo"
+	"github.com/NesoHQ/gw2style/rest/utils"
+)
+
+// maxSuggestedDyeTags caps the dye color tags suggested for a character
+const maxSuggestedDyeTags = 3
+
+// CharacterFashion is the look of one of the user's characters, ready to be posted
+type CharacterFashion struct {
+	Character     *gw2api.CharacterCore `json:"character"`
+	Equipments    *equipment.Equipment  `json:"equipments"`
+	Skins         map[int]gw2api.Skin   `json:"skins"` // Every skin of equipments, by ID
+	Dyes          map[int]gw2api.Color  `json:"dyes"`  // Every dye of equipments, by ID
+	SuggestedTags []string              `json:"suggested_tags"`
+}
+
+// GetCharactersHandler handles GET /api/v1/user/characters
+// Lists the names of the characters on the user's GW2 account
+func (h *Handlers) GetCharactersHandler(w http.ResponseWriter, r *http.Request) {
+	apiKey, ok := h.userAPIKey(w, r)
+	if !ok {
+		return
+	}
+
+	names, err := h.gw2.Characters(r.Context(), apiKey)
+	if err != nil {
+		sendGW2Error(w, err)
+		return
+	}
+
+	utils.SendData(w, http.StatusOK, map[string]interface{}{
+		"success":    true,
+		"characters": names,
+	})
+}
+
+// GetCharacterFashionHandler handles GET /api/v1/user/characters/{name}/fashion
+// Reads what the character wears and returns it as an equipments payload for
+// a new post, with its skins and dyes resolved and tags suggested from the
+// character's race, gender and profession and the look itself
+func (h *Handlers) GetCharacterFashionHandler(w http.ResponseWriter, r *http.Request) {
+	apiKey, ok := h.userAPIKey(w, r)
+	if !ok {
+		return
+	}
+
+	name := r.PathValue("name")
+	if name == "" {
+		utils.SendError(w, http.StatusBadRequest, "character name is required", nil)
+		return
+	}
+
+	fashion, err := h.characterFashion(r.Context(), apiKey, name)
+	if err != nil {
+		sendGW2Error(w, err)
+		return
+	}
+
+	fashion.SuggestedTags, err = h.suggestFashionTags(r.Context(), fashion)
+	if err != nil {
+		utils.SendError(w, http.StatusInternalServerError, "failed to suggest tags", err)
+		return
+	}
+
+	utils.SendData(w, http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    fashion,
+	})
+}
+
+// userAPIKey returns the logged in user's GW2 API key. It writes the error
+// response and returns false when there is no usable key.
+func (h *Handlers) userAPIKey(w http.ResponseWriter, r *http.Request) (string, bool) {
+	user, err := utils.GetUserFromContext(r.Context())
+	if err != nil {
+		utils.SendError(w, http.StatusUnauthorized, "unauthorized", err)
+		return "", false
+	}
+
+	dbUser, err := h.repoUser.FindUser(user.ID)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && dbUser == nil) {
+		utils.SendError(w, http.StatusNotFound, "user not found", nil)
+		return "", false
+	}
+	if err != nil {
+		utils.SendError(w, http.StatusInternalServerError, "failed to fetch user data", err)
+		return "", false
+	}
+
+	if dbUser.ApiKeyInvalidatedAt != nil {
+		utils.SendError(w, http.StatusForbidden, "your GW2 API key was revoked, replace it to import characters", nil)
+		return "", false
+	}
+	if dbUser.ApiKeyUndecryptable {
+		utils.SendError(w, http.StatusForbidden, "your stored GW2 API key can no longer be read, replace it to import characters", nil)
+		return "", false
+	}
+	return dbUser.ApiKey, true
+}
+
+// characterFashion reads a character and resolves the skins and dyes it wears
+func (h *Handlers) characterFashion(ctx context.Context, apiKey, name string) (*CharacterFashion, error) {
+	core, err := h.gw2.CharacterCore(ctx, apiKey, name)
+	if err != nil {
+		return nil, err
+	}
+
+	items, err := h.gw2.CharacterEquipment(ctx, apiKey, name)
+	if err != nil {
+		return nil, err
+	}
+
+	// Items that were not transmuted show their default skin
+	var untransmuted []int
+	for _, item := range items {
+		if item.Skin == 0 {
+			untransmuted = append(untransmuted, item.ID)
+		}
+	}
+	itemDetails, err := h.gw2.Items(ctx, untransmuted)
+	if err != nil {
+		return nil, err
+	}
+	defaultSkins := make(map[int]int, len(itemDetails))
+	for id, item := range itemDetails {
+		defaultSkins[id] = item.DefaultSkin
+	}
+
+	look := equipment.FromCharacter(items, defaultSkins)
+
+	var skinIDs []int
+	for _, piece := range look.Pieces {
+		if piece.SkinID != 0 {
+			skinIDs = append(skinIDs, piece.SkinID)
+		}
+	}
+	skins, err := h.catalog.Skins(ctx, skinIDs)
+	if err != nil {
+		return nil, err
+	}
+	look.FitToSkins(skins)
+
+	var dyeIDs []int
+	for _, piece := range look.Pieces {
+		dyeIDs = append(dyeIDs, piece.Dyes.IDs()...)
+	}
+	dyes, err := h.catalog.Colors(ctx, dyeIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	// Only return the skins still worn after fitting
+	worn := make(map[int]gw2api.Skin)
+	for _, piece := range look.Pieces {
+		if skin, ok := skins[piece.SkinID]; ok {
+			worn[skin.ID] = skin
+		}
+	}
+
+	fashion := &CharacterFashion{
+		Character: core,
+		Skins:     worn,
+		Dyes:      dyes,
+	}
+	// Posts store no document rather than an empty one
+	if !look.Empty() {
+		fashion.Equipments = look
+	}
+	return fashion, nil
+}
+
+// suggestFashionTags suggests taxonomy tags for a look: the character's race,
+// gender and profession, the armor weight most pieces share, the most used dye
+// hues and armor_skin tags named after a worn armor skin. Suggestions missing
+// from the taxonomy are dropped.
+func (h *Handlers) suggestFashionTags(ctx context.Context, fashion *CharacterFashion) ([]string, error) {
+	names := []string{fashion.Character.Race, fashion.Character.Gender, fashion.Character.Profession}
+
+	weights := map[string]int{}
+	hues := map[string]int{}
+	var armorSkins []string
+	var pieces []equipment.Piece
+	if fashion.Equipments != nil {
+		pieces = fashion.Equipments.Pieces
+	}
+	for _, piece := range pieces {
+		if kind, _ := piece.Slot.Kind(); kind == equipment.KindArmor {
+			if skin, ok := fashion.Skins[piece.SkinID]; ok {
+				armorSkins = append(armorSkins, strings.ToLower(skin.Name))
+				if skin.Details.WeightClass != "Clothing" {
+					weights[skin.Details.WeightClass]++
+				}
+			}
+		}
+		for _, id := range piece.Dyes.IDs() {
+			// GW2 lists the hue first, e.g. ["Red", "Vibrant", "Rare"]
+			if dye, ok := fashion.Dyes[id]; ok && len(dye.Categories) > 0 {
+				hues[dye.Categories[0]]++
+			}
+		}
+	}
+
+	if weight := mostUsed(weights, 1); len(weight) > 0 {
+		names = append(names, weight[0])
+	}
+	for _, hue := range mostUsed(hues, maxSuggestedDyeTags) {
+		names = append(names, hue+" dyes")
+	}
+
+	tags, err := h.tagRepo.GetTags(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, tag := range tags {
+		if tag.Category != repo.TagCategoryArmorSkin {
+			continue
+		}
+		// "Carapace Armor" is suggested for any armor skin named "Carapace ..."
+		keyword := strings.ToLower(strings.TrimSuffix(tag.DisplayName, " Armor"))
+		if keyword != "" && slices.ContainsFunc(armorSkins, func(name string) bool { return strings.Contains(name, keyword) }) {
+			names = append(names, tag.DisplayName)
+		}
+	}
+
+	canonical, _, err := h.tagRepo.NormalizeTags(ctx, names)
+	return canonical, err
+}
+
+// mostUsed returns up to n keys with the highest counts, most used first
+func mostUsed(counts map[string]int, n int) []string {
+	keys := make([]string, 0, len(counts))
+	for key := range counts {
+		if key != "" {
+			keys = append(keys, key)
+		}
+	}
+	slices.SortFunc(keys, func(a, b string) int {
+		if counts[a] != counts[b] {
+			return counts[b] - counts[a]
+		}
+		return strings.Compare(a, b)
+	})
+	if len(keys) > n {
+		keys = keys[:n]
+	}
+	return keys
+}
@@ -2,6 +2,8 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
+	"log/slog"
 	"net/http"
 
 	"github.com/NesoHQ/gw2style/repo"
@@ -17,8 +19,9 @@ type CreatePostRequest struct {
 	Image3URL    string          `json:"image3Url"`
 	Image4URL    string          `json:"image4Url"`
 	Image5URL    string          `json:"image5Url"`
-	Equipments   json.RawMessage `json:"equipments"` // Will store GW2 equipment data
+	Equipments   json.RawMessage `json:"equipments"` // Equipment document, see the equipment package
 	Tags         json.RawMessage `json:"tags"`       // Array of tags
+	Draft        bool            `json:"draft"`      // Save without sending to moderation
 }
 
 func (h *Handlers) CreatePostHandler(w http.ResponseWriter, r *http.Request) {
@@ -41,7 +44,38 @@ func (h *Handlers) CreatePostHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Create post in database (always unpublished, requires moderation)
+	tags, unknownTags, err := h.normalizePostTags(r.Context(), req.Tags)
+	if errors.Is(err, errInvalidTags) {
+		utils.SendError(w, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+	if err != nil {
+		utils.SendError(w, http.StatusInternalServerError, "failed to validate tags", err)
+		return
+	}
+	if len(unknownTags) > 0 {
+		utils.SendError(w, http.StatusBadRequest, "unknown tags", map[string]interface{}{
+			"unknown_tags": unknownTags,
+		})
+		return
+	}
+
+	equipments, fieldErrors, err := h.normalizePostEquipment(r.Context(), req.Equipments)
+	if err != nil {
+		sendGW2Error(w, err)
+		return
+	}
+	if len(fieldErrors) > 0 {
+		sendEquipmentErrors(w, fieldErrors)
+		return
+	}
+
+	// Create post in database (never published, requires moderation)
+	status := repo.PostStatusPending
+	if req.Draft {
+		status = repo.PostStatusDraft
+	}
+
 	post := &repo.Post{
 		Title:       req.Title,
 		Description: req.Description,
@@ -51,10 +85,13 @@ func (h *Handlers) CreatePostHandler(w http.ResponseWriter, r *http.Request) {
 		Image3:      req.Image3URL,
 		Image4:      req.Image4URL,
 		Image5:      req.Image5URL,
-		Equipments:  req.Equipments,
 		AuthorName:  user.Name,
-		Tags:        req.Tags,
-		Published:   false, // All posts require moderation approval
+		Tags:        tags,
+		Status:      status, // All posts require moderation approval
+	}
+	if equipments != nil {
+		// Left unset otherwise, a nil json.RawMessage would not be stored as NULL
+		post.Equipments = equipments
 	}
 
 	createdPost, err := h.postRepo.Create(*post)
@@ -63,9 +100,14 @@ func (h *Handlers) CreatePostHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if createdPost.Status == repo.PostStatusDraft {
+		utils.SendData(w, http.StatusCreated, createdPost)
+		return
+	}
+
 	// Send notification to Discord for moderation (async, don't block response)
 	go func() {
-		if err := h.SendPostToDiscord(createdPost); err != nil {
+		if err := h.SendPostToDiscord(createdPost, nil); err != nil {
 			// Log error but don't fail the request
 			// TODO: Add proper logging
 			_ = err
@@ -74,3 +116,59 @@ func (h *Handlers) CreatePostHandler(w http.ResponseWriter, r *http.Request) {
 
 	utils.SendData(w, http.StatusCreated, createdPost)
 }
+
+// SubmitPostHandler handles POST /api/v1/posts/{id}/submit
+// Sends one of the author's drafts to moderation
+func (h *Handlers) SubmitPostHandler(w http.ResponseWriter, r *http.Request) {
+	user, err := utils.GetUserFromContext(r.Context())
+	if err != nil {
+		utils.SendError(w, http.StatusUnauthorized, "unauthorized", err)
+		return
+	}
+
+	postID := r.PathValue("id")
+	if postID == "" {
+		utils.SendError(w, http.StatusBadRequest, "post ID is required", nil)
+		return
+	}
+
+	post, err := h.postRepo.GetPostByID(r.Context(), postID)
+	if err != nil {
+		utils.SendError(w, http.StatusInternalServerError, "failed to fetch post", err)
+		return
+	}
+	if post == nil {
+		utils.SendError(w, http.StatusNotFound, "post not found", nil)
+		return
+	}
+
+	if post.AuthorName != user.Name {
+		utils.SendError(w, http.StatusForbidden, "you can only submit your own posts", nil)
+		return
+	}
+
+	err = h.postRepo.SubmitPost(r.Context(), postID)
+	if errors.Is(err, repo.ErrInvalidTransition) {
+		utils.SendError(w, http.StatusConflict, "only drafts can be submitted", nil)
+		return
+	}
+	if err != nil {
+		utils.SendError(w, http.StatusInternalServerError, "failed to submit post", err)
+		return
+	}
+
+	post.Status = repo.PostStatusPending
+	post.Tags = rawJSON(post.Tags)
+	post.Equipments = rawJSON(post.Equipments)
+
+	go func() {
+		if err := h.SendPostToDiscord(post, nil); err != nil {
+			slog.Error("Failed to send submitted post to Discord", "postID", postID, "error", err)
+		}
+	}()
+
+	utils.SendData(w, http.StatusOK, map[string]interface{}{
+		"message": "post submitted for moderation",
+		"post":    post,
+	})
+}
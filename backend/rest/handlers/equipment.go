@@ -0,0 +1,42 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/NesoHQ/gw2style/equipment"
+	"github.com/NesoHQ/gw2style/rest/utils"
+)
+
+// normalizePostEquipment parses an equipment document and validates it against
+// the GW2 catalog. It returns the document re-encoded in its canonical form, or
+// nil when there is nothing to show. Problems with the document are returned
+// as field errors; the error is only set when the catalog could not be reached.
+func (h *Handlers) normalizePostEquipment(ctx context.Context, raw json.RawMessage) (json.RawMessage, []equipment.FieldError, error) {
+	e, err := equipment.Parse(raw)
+	if err != nil {
+		return nil, []equipment.FieldError{{Field: "equipments", Message: err.Error()}}, nil
+	}
+	if e == nil || e.Empty() {
+		return nil, nil, nil
+	}
+
+	fieldErrors, err := e.Validate(ctx, h.catalog)
+	if err != nil || len(fieldErrors) > 0 {
+		return nil, fieldErrors, err
+	}
+
+	normalized, err := json.Marshal(e)
+	if err != nil {
+		return nil, nil, err
+	}
+	return normalized, nil, nil
+}
+
+// sendEquipmentErrors responds with the field errors of an invalid equipment document
+func sendEquipmentErrors(w http.ResponseWriter, fieldErrors []equipment.FieldError) {
+	utils.SendError(w, http.StatusBadRequest, "invalid equipment", map[string]interface{}{
+		"errors": fieldErrors,
+	})
+}
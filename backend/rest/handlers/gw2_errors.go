@@ -0,0 +1,27 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/NesoHQ/gw2style/gw2api"
+	"github.com/NesoHQ/gw2style/rest/utils"
+)
+
+// sendGW2Error maps a gw2api error to the response the client should see
+func sendGW2Error(w http.ResponseWriter, err error) {
+	var missing *gw2api.MissingScopeError
+
+	switch {
+	case errors.As(err, &missing):
+		utils.SendError(w, http.StatusForbidden, missing.Error(), map[string]any{"missing_permissions": missing.Scopes})
+	case errors.Is(err, gw2api.ErrInvalidKey):
+		utils.SendError(w, http.StatusUnauthorized, "invalid GW2 API key", nil)
+	case errors.Is(err, gw2api.ErrNotFound):
+		utils.SendError(w, http.StatusNotFound, "not found on the GW2 API", nil)
+	case gw2api.IsUpstreamError(err):
+		utils.SendError(w, http.StatusServiceUnavailable, "GW2 API is unavailable, try again later", nil)
+	default:
+		utils.SendError(w, http.StatusBadGateway, "failed to reach the GW2 API", err)
+	}
+}
@@ -8,7 +8,10 @@ import (
 	_ "github.com/lib/pq"
 
 	"github.com/NesoHQ/gw2style/config"
+	"github.com/NesoHQ/gw2style/equipment"
+	"github.com/NesoHQ/gw2style/gw2api"
 	"github.com/NesoHQ/gw2style/repo"
+	"github.com/NesoHQ/gw2style/rest/utils"
 )
 
 type Handlers struct {
@@ -17,15 +20,35 @@ type Handlers struct {
 	repoUser       repo.UserRepo
 	postRepo       *repo.PostRepository
 	moderationRepo *repo.ModerationRepository
+	tagRepo        *repo.TagRepository
+	sessionRepo    *repo.SessionRepository
+	roleRepo       *repo.RoleRepository
+	banRepo        *repo.BanRepository
+	accountRepo    *repo.AccountRepository
+	catalogRepo    *repo.CatalogRepository
+	jwtSigner      *utils.JWTSigner
+	gw2            *gw2api.Client
+	catalog        equipment.Catalog
 }
 
-func NewHandler(cnf *config.Config, db *sqlx.DB, userRepo repo.UserRepo) *Handlers {
+func NewHandler(cnf *config.Config, db *sqlx.DB, userRepo repo.UserRepo, sessionRepo *repo.SessionRepository, jwtSigner *utils.JWTSigner, gw2Client *gw2api.Client) *Handlers {
+	catalogRepo := repo.NewCatalogRepository(db.DB)
+
 	return &Handlers{
 		cnf:            cnf,
+		jwtSigner:      jwtSigner,
 		DB:             db,
 		repoUser:       userRepo,
 		postRepo:       repo.NewPostRepository(db.DB),
 		moderationRepo: repo.NewModerationRepository(db.DB),
+		tagRepo:        repo.NewTagRepository(db.DB),
+		sessionRepo:    sessionRepo,
+		roleRepo:       repo.NewRoleRepository(db.DB),
+		banRepo:        repo.NewBanRepository(db.DB),
+		accountRepo:    repo.NewAccountRepository(db.DB),
+		catalogRepo:    catalogRepo,
+		gw2:            gw2Client,
+		catalog:        catalogRepo.WithFallback(gw2Client), // GW2 is only asked for cosmetics not synced yet
 	}
 }
 
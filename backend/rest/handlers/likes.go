@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 	"strings"
 
@@ -38,7 +39,7 @@ func (h *Handlers) LikePost(w http.ResponseWriter, r *http.Request) {
 		h.sendError(w, http.StatusInternalServerError, "error fetching post")
 		return
 	}
-	if post == nil {
+	if post == nil || !post.Published {
 		h.sendError(w, http.StatusNotFound, "post not found")
 		return
 	}
@@ -49,7 +50,7 @@ func (h *Handlers) LikePost(w http.ResponseWriter, r *http.Request) {
 	// Add like
 	err = likeRepo.LikePost(r.Context(), postID, user.ID)
 	if err != nil {
-		if err.Error() == "user already liked this post" {
+		if errors.Is(err, repo.ErrAlreadyLiked) {
 			h.sendError(w, http.StatusConflict, "you already liked this post")
 			return
 		}
@@ -113,7 +114,7 @@ func (h *Handlers) UnlikePost(w http.ResponseWriter, r *http.Request) {
 	// Remove like
 	err = likeRepo.UnlikePost(r.Context(), postID, user.ID)
 	if err != nil {
-		if err.Error() == "like not found" {
+		if errors.Is(err, repo.ErrLikeNotFound) {
 			h.sendError(w, http.StatusNotFound, "you haven't liked this post")
 			return
 		}
@@ -202,20 +203,61 @@ func (h *Handlers) GetUserLikedPosts(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Query user's liked_posts directly
-	var likedPostsJSON []byte
-	query := `SELECT COALESCE(liked_posts, '[]'::json) FROM users WHERE id = $1`
-	err := h.DB.QueryRow(query, user.ID).Scan(&likedPostsJSON)
+	likeRepo := repo.NewLikeRepository(h.DB.DB)
+
+	likedPosts, err := likeRepo.GetUserLikedPosts(r.Context(), user.ID)
 	if err != nil {
 		h.sendError(w, http.StatusInternalServerError, "error fetching liked posts")
 		return
 	}
 
-	// Send response with raw JSON array
+	// Send response
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success":     true,
+		"liked_posts": likedPosts,
+	})
+}
+
+// GetPostLikes handles GET /api/v1/posts/{id}/likes
+// Returns who liked the post and when, most recent first
+func (h *Handlers) GetPostLikes(w http.ResponseWriter, r *http.Request) {
+	postID := r.PathValue("id")
+	if postID == "" {
+		h.sendError(w, http.StatusBadRequest, "post ID is required")
+		return
+	}
+
+	page, limit := parsePage(r, 50)
+
+	post, err := h.postRepo.GetPostByID(r.Context(), postID)
+	if err != nil {
+		h.sendError(w, http.StatusInternalServerError, "error fetching post")
+		return
+	}
+	if post == nil || !post.Published {
+		h.sendError(w, http.StatusNotFound, "post not found")
+		return
+	}
+
+	likeRepo := repo.NewLikeRepository(h.DB.DB)
+
+	likes, err := likeRepo.GetPostLikes(r.Context(), postID, limit, (page-1)*limit)
+	if err != nil {
+		h.sendError(w, http.StatusInternalServerError, "error fetching post likes")
+		return
+	}
+
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(map[string]interface{}{
 		"success":     true,
-		"liked_posts": json.RawMessage(likedPostsJSON),
+		"likes":       likes,
+		"likes_count": post.LikesCount,
+		"pagination": map[string]interface{}{
+			"page":  page,
+			"limit": limit,
+		},
 	})
 }
@@ -3,9 +3,11 @@ package handlers
 import (
 	"database/sql"
 	"encoding/json"
+	"errors"
+	"log/slog"
 	"net/http"
 
-	"github.com/NesoHQ/gw2style/config"
+	"github.com/NesoHQ/gw2style/gw2api"
 	"github.com/NesoHQ/gw2style/repo"
 	"github.com/NesoHQ/gw2style/rest/utils"
 )
@@ -26,36 +28,57 @@ func (h *Handlers) LoginHandler(w http.ResponseWriter, r *http.Request) {
 
 	// First, try to find user by API key in database
 	user, err := h.repoUser.FindUserByAPIKey(apiKey)
-	if err != nil && err != sql.ErrNoRows {
-		utils.SendError(w, http.StatusInternalServerError, "DB error: "+err.Error(), err)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		slog.Error("Failed to fetch user data", "error", err.Error())
+		utils.SendError(w, http.StatusInternalServerError, "failed to fetch user data", nil)
 		return
 	}
 
-	if user != nil {
-		// User exists in database, skip GW2 API validation
-		JWT, err := utils.GenerateJWT(utils.User{
-			ID:   user.ID,
-			Name: user.Name,
-		})
+	if user != nil && h.rejectBanned(w, r, user.ID) {
+		return
+	}
+
+	// The stored key can no longer be decrypted; validate the presented one
+	// with GW2 below and seal it again with the current master key
+	if user != nil && user.ApiKeyUndecryptable {
+		user = nil
+	}
+
+	if user != nil && user.ApiKeyInvalidatedAt != nil {
+		// The revalidation job found the key revoked; only accept it if GW2 does again
+		permissions, err := h.gw2.CheckPermissions(r.Context(), apiKey, gw2api.RequiredPermissions...)
 		if err != nil {
-			utils.SendError(w, http.StatusInternalServerError, "Failed to generate token", err)
+			sendGW2Error(w, err)
 			return
 		}
 
-		// Set HTTP-only cookie
-		http.SetCookie(w, &http.Cookie{
-			Name:     "jwt_token",
-			Value:    JWT,
-			HttpOnly: true,
-			Secure:   h.cnf.Mode == config.ReleaseMode, // Automatically set based on MODE env variable
-			SameSite: http.SameSiteStrictMode,
-			Path:     "/",
-			MaxAge:   86400 * 30, // 30 days (1 month)
-		})
+		account, err := h.gw2.Account(r.Context(), apiKey)
+		if err != nil {
+			sendGW2Error(w, err)
+			return
+		}
+
+		if err := h.repoUser.MarkAPIKeyValid(user.ID, account.Name, permissions); err != nil {
+			slog.Error("Failed to update user", "user_id", user.ID, "error", err.Error())
+			utils.SendError(w, http.StatusInternalServerError, "Failed to update user", nil)
+			return
+		}
+		user.Name = account.Name
+	}
+
+	if user != nil {
+		// User exists in database with a valid key, skip GW2 API validation
+		csrfToken, err := h.startSession(w, r, utils.User{ID: user.ID, Name: user.Name})
+		if err != nil {
+			slog.Error("Failed to start session", "error", err.Error())
+			utils.SendError(w, http.StatusInternalServerError, "Failed to start session", nil)
+			return
+		}
 
 		// Return user data (not the token)
 		utils.SendData(w, http.StatusOK, map[string]interface{}{
-			"success": true,
+			"success":   true,
+			"csrfToken": csrfToken,
 			"user": map[string]interface{}{
 				"id":       user.ID,
 				"username": user.Name,
@@ -65,57 +88,63 @@ func (h *Handlers) LoginHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// If user not found in database, validate with GW2 API
-	hasRequiredPermissions, err := utils.HasRequiredPermissions(apiKey)
+	permissions, err := h.gw2.CheckPermissions(r.Context(), apiKey, gw2api.RequiredPermissions...)
 	if err != nil {
-		utils.SendError(w, http.StatusForbidden, err.Error(), err)
+		sendGW2Error(w, err)
 		return
 	}
 
-	if !hasRequiredPermissions {
-		utils.SendError(w, http.StatusForbidden, "missing required permissions", nil)
+	userInfo, err := h.gw2.Account(r.Context(), apiKey)
+	if err != nil {
+		sendGW2Error(w, err)
 		return
 	}
 
-	userInfo, err := utils.GetUserInfo(apiKey)
-	if err != nil {
-		utils.SendError(w, http.StatusInternalServerError, err.Error(), err)
+	if h.rejectBanned(w, r, userInfo.ID) {
 		return
 	}
 
-	// Create new user in database
-	newUser, err := h.repoUser.Create(repo.User{
-		ID:     userInfo.ID,
-		Name:   userInfo.Name,
-		ApiKey: apiKey,
-	})
-	if err != nil {
-		utils.SendError(w, http.StatusInternalServerError, "Failed to create user: "+err.Error(), err)
+	// The account may already be registered with a key the player has since replaced
+	existing, err := h.repoUser.FindUser(userInfo.ID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		slog.Error("Failed to fetch user data", "error", err.Error())
+		utils.SendError(w, http.StatusInternalServerError, "failed to fetch user data", nil)
 		return
 	}
 
-	JWT, err := utils.GenerateJWT(utils.User{
-		ID:   newUser.ID,
-		Name: newUser.Name,
-	})
+	account := repo.User{
+		ID:                userInfo.ID,
+		Name:              userInfo.Name,
+		ApiKey:            apiKey,
+		ApiKeyPermissions: permissions,
+	}
+
+	var newUser *repo.User
+	if existing != nil {
+		newUser, err = h.repoUser.ReplaceAPIKey(account)
+		if err == nil {
+			h.gw2.Invalidate(existing.ApiKey)
+		}
+	} else {
+		newUser, err = h.repoUser.Create(account)
+	}
 	if err != nil {
-		utils.SendError(w, http.StatusInternalServerError, "Failed to generate token", err)
+		slog.Error("Failed to save user", "user_id", account.ID, "error", err.Error())
+		utils.SendError(w, http.StatusInternalServerError, "Failed to save user", nil)
 		return
 	}
 
-	// Set HTTP-only cookie
-	http.SetCookie(w, &http.Cookie{
-		Name:     "jwt_token",
-		Value:    JWT,
-		HttpOnly: true,
-		Secure:   h.cnf.Mode == config.ReleaseMode, // Automatically set based on MODE env variable
-		SameSite: http.SameSiteStrictMode,
-		Path:     "/",
-		MaxAge:   86400 * 30, // 30 days (1 month)
-	})
+	csrfToken, err := h.startSession(w, r, utils.User{ID: newUser.ID, Name: newUser.Name})
+	if err != nil {
+		slog.Error("Failed to start session", "error", err.Error())
+		utils.SendError(w, http.StatusInternalServerError, "Failed to start session", nil)
+		return
+	}
 
 	// Return user data (not the token)
 	utils.SendData(w, http.StatusOK, map[string]interface{}{
-		"success": true,
+		"success":   true,
+		"csrfToken": csrfToken,
 		"user": map[string]interface{}{
 			"id":       newUser.ID,
 			"username": newUser.Name,
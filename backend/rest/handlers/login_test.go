@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/NesoHQ/gw2style/config"
+	"github.com/NesoHQ/gw2style/gw2api"
+	"github.com/NesoHQ/gw2style/gw2api/gw2fake"
+	"github.com/NesoHQ/gw2style/repo"
+)
+
+// unknownUserRepo finds no user, so login always validates the key with GW2
+type unknownUserRepo struct {
+	repo.UserRepo
+}
+
+func (unknownUserRepo) FindUserByAPIKey(string) (*repo.User, error) {
+	return nil, sql.ErrNoRows
+}
+
+// newLoginHandlers returns handlers whose GW2 client talks to the fake
+func newLoginHandlers(t *testing.T) (*Handlers, *gw2fake.Server) {
+	t.Helper()
+
+	fake := gw2fake.New()
+	srv := fake.Start()
+	t.Cleanup(srv.Close)
+
+	h := &Handlers{
+		cnf:      &config.Config{},
+		repoUser: unknownUserRepo{},
+		gw2:      gw2api.New(gw2api.Options{BaseURL: srv.URL, RetryBackoff: time.Millisecond}),
+	}
+	return h, fake
+}
+
+func login(h *Handlers, apiKey string) (int, map[string]any) {
+	body := strings.NewReader(`{"apiKey":"` + apiKey + `"}`)
+	rec := httptest.NewRecorder()
+	h.LoginHandler(rec, httptest.NewRequest(http.MethodPost, "/api/v1/login", body))
+
+	var resp map[string]any
+	json.Unmarshal(rec.Body.Bytes(), &resp)
+	return rec.Code, resp
+}
+
+func TestLoginInvalidKey(t *testing.T) {
+	h, _ := newLoginHandlers(t)
+
+	status, _ := login(h, "unknown-key")
+	if status != http.StatusUnauthorized {
+		t.Errorf("status = %d, want %d", status, http.StatusUnauthorized)
+	}
+}
+
+func TestLoginMissingScope(t *testing.T) {
+	h, fake := newLoginHandlers(t)
+	fake.AddKey("limited-key", gw2fake.Key{Permissions: []string{"account", "characters"}})
+
+	status, resp := login(h, "limited-key")
+	if status != http.StatusForbidden {
+		t.Fatalf("status = %d, want %d", status, http.StatusForbidden)
+	}
+
+	data, _ := resp["data"].(map[string]any)
+	var missing []string
+	for _, scope := range data["missing_permissions"].([]any) {
+		missing = append(missing, scope.(string))
+	}
+	if !slices.Equal(missing, []string{"builds"}) {
+		t.Errorf("missing_permissions = %v, want [builds]", missing)
+	}
+}
+
+func TestLoginUpstreamDown(t *testing.T) {
+	h, fake := newLoginHandlers(t)
+	fake.AddKey("valid-key", gw2fake.Key{Permissions: gw2api.RequiredPermissions})
+	fake.SetDown(true)
+
+	status, _ := login(h, "valid-key")
+	if status != http.StatusServiceUnavailable {
+		t.Errorf("status = %d, want %d", status, http.StatusServiceUnavailable)
+	}
+	if got := fake.Requests(); got != 1+gw2api.DefaultMaxRetries {
+		t.Errorf("Requests() = %d, want %d", got, 1+gw2api.DefaultMaxRetries)
+	}
+}
@@ -0,0 +1,71 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/NesoHQ/gw2style/repo"
+)
+
+const maxPageLimit = 100
+
+// parsePage reads the page and limit query parameters of offset paginated
+// listings. Invalid values fall back to the first page and defaultLimit.
+func parsePage(r *http.Request, defaultLimit int) (page, limit int) {
+	page = 1
+	limit = defaultLimit
+
+	if pageStr := r.URL.Query().Get("page"); pageStr != "" {
+		if p, err := strconv.Atoi(pageStr); err == nil && p > 0 {
+			page = p
+		}
+	}
+
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 {
+			limit = l
+			if limit > maxPageLimit {
+				limit = maxPageLimit
+			}
+		}
+	}
+
+	return page, limit
+}
+
+// parsePageRequest reads the cursor, page and limit query parameters.
+// A cursor takes precedence over page, which is still accepted for older clients.
+func parsePageRequest(r *http.Request, defaultLimit int) (repo.PageRequest, int, error) {
+	page, limit := parsePage(r, defaultLimit)
+
+	req := repo.PageRequest{Limit: limit}
+	if token := r.URL.Query().Get("cursor"); token != "" {
+		cursor, err := repo.DecodeCursor(token)
+		if err != nil {
+			return req, page, err
+		}
+		req.Cursor = cursor
+		return req, 0, nil
+	}
+
+	req.Offset = (page - 1) * limit
+	return req, page, nil
+}
+
+// paginationMeta builds the pagination object of a listing response.
+// page, total and total_pages are only included for page based requests.
+func paginationMeta(page int, req repo.PageRequest, info repo.PageInfo) map[string]interface{} {
+	meta := map[string]interface{}{
+		"limit":       req.Limit,
+		"next_cursor": info.NextCursor,
+		"prev_cursor": info.PrevCursor,
+	}
+
+	if info.HasTotal {
+		meta["page"] = page
+		meta["total"] = info.Total
+		meta["total_pages"] = (info.Total + req.Limit - 1) / req.Limit
+	}
+
+	return meta
+}
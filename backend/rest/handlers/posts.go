@@ -2,9 +2,9 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"log/slog"
 	"net/http"
-	"strconv"
 	"strings"
 
 	"github.com/NesoHQ/gw2style/repo"
@@ -26,51 +26,32 @@ func (h *Handlers) GetPostsHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Parse pagination parameters
-	page := 1
-	limit := 20 // Default limit
-	maxLimit := 100
-
-	if pageStr := r.URL.Query().Get("page"); pageStr != "" {
-		if p, err := strconv.Atoi(pageStr); err == nil && p > 0 {
-			page = p
-		}
-	}
-
-	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
-		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 {
-			limit = l
-			if limit > maxLimit {
-				limit = maxLimit
-			}
-		}
+	pageReq, page, err := parsePageRequest(r, 20)
+	if err != nil {
+		h.sendError(w, http.StatusBadRequest, "Invalid cursor")
+		return
 	}
 
-	offset := (page - 1) * limit
-
 	// Get posts from repository
-	posts, totalCount, err := h.postRepo.GetPosts(r.Context(), limit, offset)
+	posts, info, err := h.postRepo.GetPosts(r.Context(), pageReq)
+	if errors.Is(err, repo.ErrInvalidCursor) {
+		h.sendError(w, http.StatusBadRequest, "Invalid cursor")
+		return
+	}
 	if err != nil {
 		slog.Error("Failed to fetch posts", "error", err.Error())
 		h.sendError(w, http.StatusInternalServerError, "Failed to fetch posts: "+err.Error())
 		return
 	}
 
-	// Calculate pagination metadata
-	totalPages := (totalCount + limit - 1) / limit
-
 	// Set content type
 	w.Header().Set("Content-Type", "application/json")
 
 	// Create response structure
 	response := map[string]interface{}{
-		"success": true,
-		"data":    posts,
-		"pagination": map[string]interface{}{
-			"page":        page,
-			"limit":       limit,
-			"total":       totalCount,
-			"total_pages": totalPages,
-		},
+		"success":    true,
+		"data":       posts,
+		"pagination": paginationMeta(page, pageReq, info),
 	}
 
 	// Encode response
@@ -115,6 +96,22 @@ func (h *Handlers) GetPostByIDHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Drafts, posts in moderation and hidden posts are only shown to their
+	// author and to moderators, who are also the only ones to see why
+	privileged, err := h.canSeeModeration(r, post)
+	if err != nil {
+		slog.Error("Failed to check post visibility", "error", err.Error())
+		h.sendError(w, http.StatusInternalServerError, "Failed to fetch post")
+		return
+	}
+	if !privileged {
+		if !post.Published {
+			h.sendError(w, http.StatusNotFound, "Post not found")
+			return
+		}
+		post.StatusReason = ""
+	}
+
 	// Set content type
 	w.Header().Set("Content-Type", "application/json")
 
@@ -131,6 +128,27 @@ func (h *Handlers) GetPostByIDHandler(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// canSeeModeration reports whether the logged in user, if any, is the post's
+// author or a moderator
+func (h *Handlers) canSeeModeration(r *http.Request, post *repo.Post) (bool, error) {
+	user, err := utils.GetUserFromContext(r.Context())
+	if err != nil {
+		return false, nil
+	}
+	if post.AuthorName == user.Name {
+		return true, nil
+	}
+
+	role, err := h.roleRepo.GetUserRole(r.Context(), user.ID)
+	if errors.Is(err, repo.ErrUserNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return repo.HasRole(role, repo.RoleModerator), nil
+}
+
 func (h *Handlers) GetPopularPostsHandler(w http.ResponseWriter, r *http.Request) {
 	// Only allow GET method
 	if r.Method != http.MethodGet {
@@ -147,10 +165,18 @@ func (h *Handlers) GetPopularPostsHandler(w http.ResponseWriter, r *http.Request
 
 	// Parse query parameters
 	timeframe := r.URL.Query().Get("timeframe")
-	limit := 100 // Default to top 100 posts
+	pageReq, page, err := parsePageRequest(r, 100) // Default to top 100 posts
+	if err != nil {
+		h.sendError(w, http.StatusBadRequest, "Invalid cursor")
+		return
+	}
 
 	// Get popular posts from repository
-	posts, err := h.postRepo.GetPopularPosts(r.Context(), timeframe, limit)
+	posts, info, err := h.postRepo.GetPopularPosts(r.Context(), timeframe, pageReq)
+	if errors.Is(err, repo.ErrInvalidCursor) {
+		h.sendError(w, http.StatusBadRequest, "Invalid cursor")
+		return
+	}
 	if err != nil {
 		slog.Error("Failed to fetch popular posts", "error", err.Error())
 		h.sendError(w, http.StatusInternalServerError, "Failed to fetch popular posts")
@@ -162,8 +188,9 @@ func (h *Handlers) GetPopularPostsHandler(w http.ResponseWriter, r *http.Request
 
 	// Create response structure
 	response := map[string]interface{}{
-		"success": true,
-		"data":    posts,
+		"success":    true,
+		"data":       posts,
+		"pagination": paginationMeta(page, pageReq, info),
 	}
 
 	// Encode response
@@ -189,88 +216,85 @@ func (h *Handlers) SearchPostsHandler(w http.ResponseWriter, r *http.Request) {
 
 	// Parse query parameters
 	query := r.URL.Query().Get("q")
-	tagsParam := r.URL.Query().Get("tags") // Comma-separated tags
+	tags := splitTags(r.URL.Query().Get("tags"))                // Posts must have all of these
+	anyTags := splitTags(r.URL.Query().Get("any_tags"))         // Posts must have at least one of these
+	excludeTags := splitTags(r.URL.Query().Get("exclude_tags")) // Posts must have none of these
 	authorName := r.URL.Query().Get("author")
 
-	// Parse pagination parameters
-	page := 1
-	limit := 20
-	maxLimit := 100
-
-	if pageStr := r.URL.Query().Get("page"); pageStr != "" {
-		if p, err := strconv.Atoi(pageStr); err == nil && p > 0 {
-			page = p
-		}
-	}
-
-	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
-		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 {
-			limit = l
-			if limit > maxLimit {
-				limit = maxLimit
-			}
-		}
+	sort := r.URL.Query().Get("sort")
+	switch sort {
+	case "":
+		sort = repo.SearchSortNewest
+	case repo.SearchSortNewest, repo.SearchSortRelevance:
+	default:
+		h.sendError(w, http.StatusBadRequest, "Invalid sort, expected newest or relevance")
+		return
 	}
 
-	offset := (page - 1) * limit
-
-	// Parse tags if provided (comma-separated)
-	var tags []string
-	if tagsParam != "" {
-		tags = strings.Split(tagsParam, ",")
-		// Trim whitespace from each tag
-		for i := range tags {
-			tags[i] = strings.TrimSpace(tags[i])
-		}
+	// Parse pagination parameters
+	pageReq, page, err := parsePageRequest(r, 20)
+	if err != nil {
+		h.sendError(w, http.StatusBadRequest, "Invalid cursor")
+		return
 	}
 
 	slog.Info("Search request",
 		"query", query,
 		"tags", tags,
+		"any_tags", anyTags,
+		"exclude_tags", excludeTags,
 		"author", authorName,
+		"sort", sort,
 		"page", page,
-		"limit", limit,
+		"limit", pageReq.Limit,
+		"cursor", pageReq.Cursor != nil,
 	)
 
 	// Prepare search parameters
 	params := repo.SearchParams{
 		Query:         query,
 		Tags:          tags,
+		AnyTags:       anyTags,
+		ExcludeTags:   excludeTags,
 		OnlyPublished: true,
 		AuthorName:    authorName,
-		Limit:         limit,
-		Offset:        offset,
+		Sort:          sort,
+		Page:          pageReq,
 	}
 
 	// Get posts from repository
-	posts, totalCount, err := h.postRepo.SearchPosts(r.Context(), params)
+	posts, info, err := h.postRepo.SearchPosts(r.Context(), params)
+	if errors.Is(err, repo.ErrInvalidCursor) {
+		h.sendError(w, http.StatusBadRequest, "Invalid cursor")
+		return
+	}
 	if err != nil {
 		slog.Error("Failed to search posts", "error", err.Error())
 		h.sendError(w, http.StatusInternalServerError, "Failed to search posts")
 		return
 	}
 
-	// Calculate pagination metadata
-	totalPages := 0
-	if totalCount > 0 {
-		totalPages = (totalCount + limit - 1) / limit
-	}
-
-	slog.Info("Search results", "count", len(posts), "total", totalCount)
+	slog.Info("Search results", "count", len(posts), "total", info.Total)
 
 	// Set content type
 	w.Header().Set("Content-Type", "application/json")
 
 	// Create response structure
 	response := map[string]interface{}{
-		"success": true,
-		"data":    posts,
-		"pagination": map[string]interface{}{
-			"page":        page,
-			"limit":       limit,
-			"total":       totalCount,
-			"total_pages": totalPages,
-		},
+		"success":    true,
+		"data":       posts,
+		"pagination": paginationMeta(page, pageReq, info),
+	}
+
+	// Like the total, facets describe the whole result set and are left out of cursor pages
+	if pageReq.Cursor == nil {
+		facets, err := h.postRepo.GetTagFacets(r.Context(), params)
+		if err != nil {
+			slog.Error("Failed to count tag facets", "error", err.Error())
+			h.sendError(w, http.StatusInternalServerError, "Failed to search posts")
+			return
+		}
+		response["facets"] = facets
 	}
 
 	// Encode response
@@ -333,8 +357,12 @@ func (h *Handlers) DeletePostHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Soft delete the post (set published to false)
+	// Soft delete the post (move it to the deleted status)
 	err = h.postRepo.DeletePost(r.Context(), postID)
+	if errors.Is(err, repo.ErrPostNotFound) {
+		h.sendError(w, http.StatusNotFound, "Post not found")
+		return
+	}
 	if err != nil {
 		slog.Error("Failed to delete post", "error", err.Error())
 		h.sendError(w, http.StatusInternalServerError, "Failed to delete post")
@@ -358,3 +386,14 @@ func (h *Handlers) DeletePostHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 }
+
+// splitTags parses a comma-separated tag list, trimming whitespace and dropping empty entries
+func splitTags(param string) []string {
+	var tags []string
+	for _, tag := range strings.Split(param, ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
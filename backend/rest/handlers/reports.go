@@ -2,8 +2,12 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/NesoHQ/gw2style/repo"
 	"github.com/NesoHQ/gw2style/rest/utils"
@@ -14,6 +18,13 @@ type CreateReportRequest struct {
 	Description string `json:"description"`
 }
 
+type ResolveReportRequest struct {
+	ModeratorUsername  string `json:"moderator_username"`
+	ModeratorDiscordID string `json:"moderator_discord_id"`
+	Reason             string `json:"reason"`
+	Unpublish          bool   `json:"unpublish"`
+}
+
 // CreateReportHandler allows users to report posts
 func (h *Handlers) CreateReportHandler(w http.ResponseWriter, r *http.Request) {
 	postID := r.PathValue("id")
@@ -55,6 +66,28 @@ func (h *Handlers) CreateReportHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	post, err := h.postRepo.GetPostByID(r.Context(), postID)
+	if err != nil {
+		utils.SendError(w, http.StatusInternalServerError, "failed to fetch post", err)
+		return
+	}
+	// Only published posts can be seen, so only they can be reported
+	if post == nil || !post.Published {
+		utils.SendError(w, http.StatusNotFound, "post not found", nil)
+		return
+	}
+
+	// Enforce the per-user daily report cap
+	reportsToday, err := h.moderationRepo.CountReportsSince(r.Context(), user.Name, time.Now().Add(-24*time.Hour))
+	if err != nil {
+		utils.SendError(w, http.StatusInternalServerError, "failed to create report", err)
+		return
+	}
+	if reportsToday >= h.cnf.ReportDailyLimit {
+		utils.SendError(w, http.StatusTooManyRequests, "daily report limit reached, please try again later", nil)
+		return
+	}
+
 	report := repo.Report{
 		PostID:           postIDInt,
 		ReporterUsername: user.Name,
@@ -62,14 +95,133 @@ func (h *Handlers) CreateReportHandler(w http.ResponseWriter, r *http.Request) {
 		Description:      req.Description,
 	}
 
-	err = h.moderationRepo.CreateReport(r.Context(), report)
+	createdReport, err := h.moderationRepo.CreateReport(r.Context(), report)
+	if errors.Is(err, repo.ErrDuplicateReport) {
+		utils.SendError(w, http.StatusConflict, "you already reported this post", nil)
+		return
+	}
 	if err != nil {
 		utils.SendError(w, http.StatusInternalServerError, "failed to create report", err)
 		return
 	}
 
+	// Hide the post for review once enough distinct users reported it
+	hidden, reporters, err := h.moderationRepo.HideReportedPost(r.Context(), postIDInt, h.cnf.ReportHideThreshold)
+	if err != nil {
+		slog.Error("Failed to check report threshold", "postID", postID, "error", err)
+	}
+	if hidden {
+		slog.Info("Post hidden after reports", "postID", postID, "reporters", reporters)
+		go func() {
+			if err := h.SendAutoHideToDiscord(post, reporters); err != nil {
+				slog.Error("Failed to announce hidden post to Discord", "postID", postID, "error", err)
+			}
+		}()
+	}
+
+	// Forward the report to the moderation channel (async, don't block response)
+	go func() {
+		if err := h.SendReportToDiscord(createdReport, post); err != nil {
+			slog.Error("Failed to send report to Discord", "reportID", createdReport.ID, "error", err)
+		}
+	}()
+
 	utils.SendData(w, http.StatusCreated, map[string]interface{}{
 		"message": "report submitted successfully",
 		"post_id": postID,
 	})
 }
+
+// ListReportsHandler returns reports for moderators, pending ones by default
+func (h *Handlers) ListReportsHandler(w http.ResponseWriter, r *http.Request) {
+	status := r.URL.Query().Get("status")
+	switch status {
+	case "":
+		status = repo.ReportStatusPending
+	case "all":
+		status = ""
+	case repo.ReportStatusPending, repo.ReportStatusResolved, repo.ReportStatusDismissed:
+	default:
+		utils.SendError(w, http.StatusBadRequest, "invalid status", nil)
+		return
+	}
+
+	page, limit := parsePage(r, 50)
+
+	reports, err := h.moderationRepo.GetReports(r.Context(), status, limit, (page-1)*limit)
+	if err != nil {
+		utils.SendError(w, http.StatusInternalServerError, "failed to fetch reports", err)
+		return
+	}
+
+	utils.SendData(w, http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    reports,
+		"pagination": map[string]interface{}{
+			"page":  page,
+			"limit": limit,
+		},
+	})
+}
+
+// ResolveReportHandler marks a report as valid, optionally unpublishing the post
+func (h *Handlers) ResolveReportHandler(w http.ResponseWriter, r *http.Request) {
+	h.closeReport(w, r, repo.ReportStatusResolved)
+}
+
+// DismissReportHandler closes a report without taking action on the post
+func (h *Handlers) DismissReportHandler(w http.ResponseWriter, r *http.Request) {
+	h.closeReport(w, r, repo.ReportStatusDismissed)
+}
+
+func (h *Handlers) closeReport(w http.ResponseWriter, r *http.Request, status string) {
+	reportID, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		utils.SendError(w, http.StatusBadRequest, "invalid report ID", err)
+		return
+	}
+
+	var req ResolveReportRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		utils.SendError(w, http.StatusBadRequest, "invalid request body", err)
+		return
+	}
+	moderatorIdentity(r, &req.ModeratorUsername, &req.ModeratorDiscordID)
+
+	if req.ModeratorUsername == "" {
+		utils.SendError(w, http.StatusBadRequest, "moderator_username is required", nil)
+		return
+	}
+
+	if status == repo.ReportStatusDismissed && req.Unpublish {
+		utils.SendError(w, http.StatusBadRequest, "a dismissed report cannot unpublish the post", nil)
+		return
+	}
+
+	if req.Reason == "" {
+		if status == repo.ReportStatusResolved {
+			req.Reason = "Report upheld by moderator"
+		} else {
+			req.Reason = "Report dismissed by moderator"
+		}
+	}
+
+	report, err := h.moderationRepo.ResolveReport(r.Context(), reportID, status, req.ModeratorUsername, req.ModeratorDiscordID, req.Reason, req.Unpublish)
+	if err != nil {
+		switch {
+		case errors.Is(err, repo.ErrReportNotFound):
+			utils.SendError(w, http.StatusNotFound, "report not found", nil)
+		case errors.Is(err, repo.ErrReportNotPending):
+			utils.SendError(w, http.StatusConflict, "report has already been handled", nil)
+		default:
+			utils.SendError(w, http.StatusInternalServerError, "failed to update report", err)
+		}
+		return
+	}
+
+	utils.SendData(w, http.StatusOK, map[string]interface{}{
+		"message":     "report " + status + " successfully",
+		"report":      report,
+		"unpublished": req.Unpublish,
+	})
+}
@@ -0,0 +1,101 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/NesoHQ/gw2style/repo"
+	"github.com/NesoHQ/gw2style/rest/utils"
+)
+
+type SetUserRoleRequest struct {
+	Role   string `json:"role"`
+	Reason string `json:"reason"`
+}
+
+// SetUserRoleHandler handles PUT /api/v1/admin/users/{id}/role
+// Grants a role to a user
+func (h *Handlers) SetUserRoleHandler(w http.ResponseWriter, r *http.Request) {
+	var req SetUserRoleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.SendError(w, http.StatusBadRequest, "invalid request body", err)
+		return
+	}
+
+	if !repo.ValidRole(req.Role) {
+		utils.SendError(w, http.StatusBadRequest, "invalid role", nil)
+		return
+	}
+
+	h.changeUserRole(w, r, req.Role, req.Reason)
+}
+
+// RevokeUserRoleHandler handles DELETE /api/v1/admin/users/{id}/role
+// Takes a user back to the plain user role
+func (h *Handlers) RevokeUserRoleHandler(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Reason string `json:"reason"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		utils.SendError(w, http.StatusBadRequest, "invalid request body", err)
+		return
+	}
+
+	h.changeUserRole(w, r, repo.RoleUser, req.Reason)
+}
+
+func (h *Handlers) changeUserRole(w http.ResponseWriter, r *http.Request, role, reason string) {
+	admin, err := utils.GetUserFromContext(r.Context())
+	if err != nil {
+		utils.SendError(w, http.StatusUnauthorized, "unauthorized", err)
+		return
+	}
+
+	userID := r.PathValue("id")
+	// Keeps an admin from locking everyone out by demoting themselves
+	if userID == admin.ID {
+		utils.SendError(w, http.StatusBadRequest, "admins cannot change their own role", nil)
+		return
+	}
+
+	change, err := h.roleRepo.SetUserRole(r.Context(), userID, role, admin.Name, reason)
+	if err != nil {
+		switch {
+		case errors.Is(err, repo.ErrUserNotFound):
+			utils.SendError(w, http.StatusNotFound, "user not found", nil)
+		case errors.Is(err, repo.ErrRoleUnchanged):
+			utils.SendError(w, http.StatusConflict, err.Error(), nil)
+		default:
+			utils.SendError(w, http.StatusInternalServerError, "failed to change role", err)
+		}
+		return
+	}
+
+	utils.SendData(w, http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    change,
+	})
+}
+
+// ListRoleChangesHandler handles GET /api/v1/admin/roles/audit
+// Lists role changes newest first, optionally filtered by ?user_id=
+func (h *Handlers) ListRoleChangesHandler(w http.ResponseWriter, r *http.Request) {
+	page, limit := parsePage(r, 50)
+
+	changes, err := h.roleRepo.GetRoleChanges(r.Context(), r.URL.Query().Get("user_id"), limit, (page-1)*limit)
+	if err != nil {
+		utils.SendError(w, http.StatusInternalServerError, "failed to fetch role changes", err)
+		return
+	}
+
+	utils.SendData(w, http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    changes,
+		"pagination": map[string]interface{}{
+			"page":  page,
+			"limit": limit,
+		},
+	})
+}
@@ -0,0 +1,241 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"github.com/NesoHQ/gw2style/config"
+	"github.com/NesoHQ/gw2style/repo"
+	"github.com/NesoHQ/gw2style/rest/utils"
+)
+
+const (
+	accessTokenCookie  = "jwt_token"
+	refreshTokenCookie = "refresh_token"
+	// The refresh token is only sent to the refresh and logout endpoints
+	refreshTokenPath = "/api/v1/auth"
+	// Refresh tokens used to be scoped to the whole API; cookies left from
+	// then are still accepted and deleted on the next refresh or logout
+	legacyRefreshTokenPath = "/api/v1"
+)
+
+// startSession creates a session for the user, sets the token cookies and
+// returns the session's CSRF token
+func (h *Handlers) startSession(w http.ResponseWriter, r *http.Request, user utils.User) (string, error) {
+	session, refreshToken, err := h.sessionRepo.CreateSession(r.Context(), user.ID, r.UserAgent(), utils.ClientIP(r, h.cnf.TrustProxyHeaders), h.cnf.RefreshTokenLifetime())
+	if err != nil {
+		return "", err
+	}
+
+	user.SessionID = session.ID
+	accessToken, err := h.jwtSigner.GenerateJWT(user)
+	if err != nil {
+		return "", err
+	}
+
+	csrfToken, err := h.jwtSigner.CSRFToken(session.ID)
+	if err != nil {
+		return "", err
+	}
+
+	h.setAuthCookies(w, accessToken, refreshToken, csrfToken)
+	return csrfToken, nil
+}
+
+// setAuthCookies stores both tokens in HTTP-only cookies and the CSRF token in
+// a cookie the frontend can read
+func (h *Handlers) setAuthCookies(w http.ResponseWriter, accessToken, refreshToken, csrfToken string) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     accessTokenCookie,
+		Value:    accessToken,
+		HttpOnly: true,
+		Secure:   h.cnf.Mode == config.ReleaseMode, // Automatically set based on MODE env variable
+		SameSite: http.SameSiteStrictMode,
+		Path:     "/",
+		MaxAge:   int(h.jwtSigner.Lifetime().Seconds()),
+	})
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     refreshTokenCookie,
+		Value:    refreshToken,
+		HttpOnly: true,
+		Secure:   h.cnf.Mode == config.ReleaseMode,
+		SameSite: http.SameSiteStrictMode,
+		Path:     refreshTokenPath,
+		MaxAge:   int(h.cnf.RefreshTokenLifetime().Seconds()),
+	})
+	http.SetCookie(w, &http.Cookie{
+		Name:     refreshTokenCookie,
+		HttpOnly: true,
+		Secure:   h.cnf.Mode == config.ReleaseMode,
+		SameSite: http.SameSiteStrictMode,
+		Path:     legacyRefreshTokenPath,
+		MaxAge:   -1,
+	})
+
+	// Lives as long as the refresh token, which needs it too
+	http.SetCookie(w, &http.Cookie{
+		Name:     utils.CSRFCookieName,
+		Value:    csrfToken,
+		HttpOnly: false,
+		Secure:   h.cnf.Mode == config.ReleaseMode,
+		SameSite: http.SameSiteStrictMode,
+		Path:     "/",
+		MaxAge:   int(h.cnf.RefreshTokenLifetime().Seconds()),
+	})
+}
+
+// clearAuthCookies deletes the token and CSRF cookies
+func (h *Handlers) clearAuthCookies(w http.ResponseWriter) {
+	cookies := []http.Cookie{
+		{Name: accessTokenCookie, Path: "/", HttpOnly: true},
+		{Name: refreshTokenCookie, Path: refreshTokenPath, HttpOnly: true},
+		{Name: refreshTokenCookie, Path: legacyRefreshTokenPath, HttpOnly: true},
+		{Name: utils.CSRFCookieName, Path: "/"},
+	}
+
+	for _, cookie := range cookies {
+		cookie.Value = ""
+		cookie.Secure = h.cnf.Mode == config.ReleaseMode
+		cookie.SameSite = http.SameSiteStrictMode
+		cookie.MaxAge = -1 // Delete cookie
+		http.SetCookie(w, &cookie)
+	}
+}
+
+// RefreshHandler handles POST /api/v1/auth/refresh
+// Exchanges the refresh token cookie for a new access token and refresh token
+func (h *Handlers) RefreshHandler(w http.ResponseWriter, r *http.Request) {
+	cookie, err := r.Cookie(refreshTokenCookie)
+	if err != nil || cookie.Value == "" {
+		utils.SendError(w, http.StatusUnauthorized, "missing refresh token", nil)
+		return
+	}
+
+	// The user and tokens are built before the rotation commits, so a failure
+	// here leaves the presented refresh token valid for a retry
+	var user *repo.User
+	var accessToken, csrfToken string
+	_, refreshToken, err := h.sessionRepo.RotateRefreshToken(r.Context(), cookie.Value, h.cnf.RefreshTokenLifetime(), func(session *repo.Session) error {
+		var err error
+		if user, err = h.repoUser.FindUser(session.UserID); err != nil {
+			return fmt.Errorf("failed to fetch user: %w", err)
+		}
+		if accessToken, err = h.jwtSigner.GenerateJWT(utils.User{ID: user.ID, Name: user.Name, SessionID: session.ID}); err != nil {
+			return fmt.Errorf("failed to generate token: %w", err)
+		}
+		if csrfToken, err = h.jwtSigner.CSRFToken(session.ID); err != nil {
+			return fmt.Errorf("failed to generate CSRF token: %w", err)
+		}
+		return nil
+	})
+	if errors.Is(err, repo.ErrRefreshTokenReused) {
+		slog.Warn("Refresh token reused, session revoked", "ip", utils.ClientIP(r, h.cnf.TrustProxyHeaders))
+		h.clearAuthCookies(w)
+		utils.SendError(w, http.StatusUnauthorized, "session revoked", nil)
+		return
+	}
+	if errors.Is(err, repo.ErrSessionNotFound) || errors.Is(err, repo.ErrSessionInactive) {
+		h.clearAuthCookies(w)
+		utils.SendError(w, http.StatusUnauthorized, "session expired", nil)
+		return
+	}
+	if err != nil {
+		utils.SendError(w, http.StatusInternalServerError, "failed to refresh session", err)
+		return
+	}
+
+	h.setAuthCookies(w, accessToken, refreshToken, csrfToken)
+
+	utils.SendData(w, http.StatusOK, map[string]interface{}{
+		"success":   true,
+		"csrfToken": csrfToken,
+		"user": map[string]interface{}{
+			"id":       user.ID,
+			"username": user.Name,
+		},
+	})
+}
+
+// CSRFTokenHandler handles GET /api/v1/csrf
+// Returns the CSRF token from the csrf_token cookie, for frontends on another
+// origin that cannot read the cookie themselves. CORS keeps the response from
+// any origin not listed in CORS_ALLOWED_ORIGINS.
+func (h *Handlers) CSRFTokenHandler(w http.ResponseWriter, r *http.Request) {
+	cookie, err := r.Cookie(utils.CSRFCookieName)
+	if err != nil || cookie.Value == "" {
+		utils.SendError(w, http.StatusUnauthorized, "missing CSRF token", nil)
+		return
+	}
+
+	utils.SendData(w, http.StatusOK, map[string]interface{}{
+		"success":   true,
+		"csrfToken": cookie.Value,
+	})
+}
+
+// GetUserSessionsHandler handles GET /api/v1/user/sessions
+// Lists the user's active logins
+func (h *Handlers) GetUserSessionsHandler(w http.ResponseWriter, r *http.Request) {
+	user, err := utils.GetUserFromContext(r.Context())
+	if err != nil {
+		utils.SendError(w, http.StatusUnauthorized, "unauthorized", err)
+		return
+	}
+
+	sessions, err := h.sessionRepo.GetUserSessions(r.Context(), user.ID)
+	if err != nil {
+		utils.SendError(w, http.StatusInternalServerError, "failed to fetch sessions", err)
+		return
+	}
+
+	data := make([]map[string]interface{}, 0, len(sessions))
+	for _, s := range sessions {
+		data = append(data, map[string]interface{}{
+			"id":           s.ID,
+			"user_agent":   s.UserAgent,
+			"ip_address":   s.IPAddress,
+			"created_at":   s.CreatedAt,
+			"last_used_at": s.LastUsedAt,
+			"expires_at":   s.ExpiresAt,
+			"current":      s.ID == user.SessionID,
+		})
+	}
+
+	utils.SendData(w, http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    data,
+	})
+}
+
+// RevokeSessionHandler handles DELETE /api/v1/user/sessions/{id}
+// Logs one of the user's sessions out
+func (h *Handlers) RevokeSessionHandler(w http.ResponseWriter, r *http.Request) {
+	user, err := utils.GetUserFromContext(r.Context())
+	if err != nil {
+		utils.SendError(w, http.StatusUnauthorized, "unauthorized", err)
+		return
+	}
+
+	sessionID := r.PathValue("id")
+	err = h.sessionRepo.RevokeSession(r.Context(), sessionID, user.ID, repo.SessionRevokedByUser)
+	if errors.Is(err, repo.ErrSessionNotFound) {
+		utils.SendError(w, http.StatusNotFound, "session not found", nil)
+		return
+	}
+	if err != nil {
+		utils.SendError(w, http.StatusInternalServerError, "failed to revoke session", err)
+		return
+	}
+
+	if sessionID == user.SessionID {
+		h.clearAuthCookies(w)
+	}
+
+	utils.SendData(w, http.StatusOK, map[string]interface{}{
+		"success": true,
+		"message": "session revoked",
+	})
+}
@@ -0,0 +1,147 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/NesoHQ/gw2style/repo"
+	"github.com/NesoHQ/gw2style/rest/utils"
+)
+
+var errInvalidTags = errors.New("tags must be an array of strings")
+
+type CreateTagRequest struct {
+	DisplayName string   `json:"display_name"`
+	Slug        string   `json:"slug"`
+	Category    string   `json:"category"`
+	Aliases     []string `json:"aliases"`
+}
+
+type MergeTagRequest struct {
+	Into string `json:"into"` // Slug of the tag that replaces the merged one
+}
+
+// GetTagsHandler handles GET /api/v1/tags
+// Returns the tag vocabulary grouped by category
+func (h *Handlers) GetTagsHandler(w http.ResponseWriter, r *http.Request) {
+	tags, err := h.tagRepo.GetTags(r.Context())
+	if err != nil {
+		utils.SendError(w, http.StatusInternalServerError, "failed to fetch tags", err)
+		return
+	}
+
+	grouped := make(map[repo.TagCategory][]repo.Tag, len(repo.TagCategories))
+	for _, category := range repo.TagCategories {
+		grouped[category] = []repo.Tag{}
+	}
+	for _, tag := range tags {
+		grouped[tag.Category] = append(grouped[tag.Category], tag)
+	}
+
+	utils.SendData(w, http.StatusOK, map[string]interface{}{
+		"success":    true,
+		"categories": repo.TagCategories,
+		"data":       grouped,
+	})
+}
+
+// CreateTagHandler handles POST /api/v1/admin/tags
+func (h *Handlers) CreateTagHandler(w http.ResponseWriter, r *http.Request) {
+	var req CreateTagRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.SendError(w, http.StatusBadRequest, "invalid request body", err)
+		return
+	}
+
+	if req.DisplayName == "" {
+		utils.SendError(w, http.StatusBadRequest, "display_name is required", nil)
+		return
+	}
+	if !repo.ValidTagCategory(req.Category) {
+		utils.SendError(w, http.StatusBadRequest, "invalid category", repo.TagCategories)
+		return
+	}
+	if repo.Slugify(req.DisplayName) == "" {
+		utils.SendError(w, http.StatusBadRequest, "display_name must contain letters or numbers", nil)
+		return
+	}
+
+	tag, err := h.tagRepo.CreateTag(r.Context(), repo.Tag{
+		DisplayName: req.DisplayName,
+		Slug:        req.Slug,
+		Category:    repo.TagCategory(req.Category),
+		Aliases:     req.Aliases,
+	})
+	if errors.Is(err, repo.ErrTagExists) {
+		utils.SendError(w, http.StatusConflict, "a tag with this name, slug or alias already exists", nil)
+		return
+	}
+	if err != nil {
+		utils.SendError(w, http.StatusInternalServerError, "failed to create tag", err)
+		return
+	}
+
+	utils.SendData(w, http.StatusCreated, map[string]interface{}{
+		"message": "tag created successfully",
+		"tag":     tag,
+	})
+}
+
+// MergeTagHandler handles POST /api/v1/admin/tags/{slug}/merge
+// Replaces the tag with another one on every post and keeps its spellings as aliases
+func (h *Handlers) MergeTagHandler(w http.ResponseWriter, r *http.Request) {
+	slug := r.PathValue("slug")
+
+	var req MergeTagRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.SendError(w, http.StatusBadRequest, "invalid request body", err)
+		return
+	}
+
+	if req.Into == "" {
+		utils.SendError(w, http.StatusBadRequest, "into is required", nil)
+		return
+	}
+	if req.Into == slug {
+		utils.SendError(w, http.StatusBadRequest, "a tag cannot be merged into itself", nil)
+		return
+	}
+
+	tag, postsUpdated, err := h.tagRepo.MergeTag(r.Context(), slug, req.Into)
+	if errors.Is(err, repo.ErrTagNotFound) {
+		utils.SendError(w, http.StatusNotFound, err.Error(), nil)
+		return
+	}
+	if err != nil {
+		utils.SendError(w, http.StatusInternalServerError, "failed to merge tag", err)
+		return
+	}
+
+	utils.SendData(w, http.StatusOK, map[string]interface{}{
+		"message":       "tag merged successfully",
+		"tag":           tag,
+		"posts_updated": postsUpdated,
+	})
+}
+
+// normalizePostTags validates a tags JSON array against the taxonomy and
+// returns it with every tag replaced by its canonical display name.
+// Tags missing from the taxonomy are returned as unknown.
+func (h *Handlers) normalizePostTags(ctx context.Context, raw json.RawMessage) (json.RawMessage, []string, error) {
+	var names []string
+	if len(raw) > 0 && string(raw) != "null" {
+		if err := json.Unmarshal(raw, &names); err != nil {
+			return nil, nil, errInvalidTags
+		}
+	}
+
+	canonical, unknown, err := h.tagRepo.NormalizeTags(ctx, names)
+	if err != nil || len(unknown) > 0 {
+		return nil, unknown, err
+	}
+
+	normalized, err := json.Marshal(canonical)
+	return normalized, nil, err
+}
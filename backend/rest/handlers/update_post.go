@@ -0,0 +1,345 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"github.com/NesoHQ/gw2style/repo"
+	"github.com/NesoHQ/gw2style/rest/utils"
+)
+
+// UpdatePostRequest holds the fields of a post that can be edited.
+// Fields left out of the request body keep their current value.
+type UpdatePostRequest struct {
+	Title        *string         `json:"title"`
+	Description  *string         `json:"description"`
+	ThumbnailURL *string         `json:"thumbnailUrl"`
+	Image1URL    *string         `json:"image1Url"`
+	Image2URL    *string         `json:"image2Url"`
+	Image3URL    *string         `json:"image3Url"`
+	Image4URL    *string         `json:"image4Url"`
+	Image5URL    *string         `json:"image5Url"`
+	Equipments   json.RawMessage `json:"equipments"`
+	Tags         json.RawMessage `json:"tags"`
+}
+
+// UpdatePostHandler handles PATCH /api/v1/posts/{id}
+// Only the author can edit a post. Edits to a published or rejected post send it back to moderation.
+func (h *Handlers) UpdatePostHandler(w http.ResponseWriter, r *http.Request) {
+	user, err := utils.GetUserFromContext(r.Context())
+	if err != nil {
+		utils.SendError(w, http.StatusUnauthorized, "unauthorized", err)
+		return
+	}
+
+	postID := r.PathValue("id")
+	if postID == "" {
+		utils.SendError(w, http.StatusBadRequest, "post ID is required", nil)
+		return
+	}
+
+	var req UpdatePostRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.SendError(w, http.StatusBadRequest, "invalid request body", err)
+		return
+	}
+
+	if req.Title != nil && *req.Title == "" {
+		utils.SendError(w, http.StatusBadRequest, "title cannot be empty", nil)
+		return
+	}
+
+	post, err := h.postRepo.GetPostByID(r.Context(), postID)
+	if err != nil {
+		utils.SendError(w, http.StatusInternalServerError, "failed to fetch post", err)
+		return
+	}
+	if post == nil {
+		utils.SendError(w, http.StatusNotFound, "post not found", nil)
+		return
+	}
+
+	if post.AuthorName != user.Name {
+		slog.Warn("Unauthorized edit attempt",
+			"username", user.Name,
+			"author", post.AuthorName,
+			"postID", postID,
+		)
+		utils.SendError(w, http.StatusForbidden, "you can only edit your own posts", nil)
+		return
+	}
+
+	if req.Tags != nil {
+		tags, unknownTags, err := h.normalizePostTags(r.Context(), req.Tags)
+		if errors.Is(err, errInvalidTags) {
+			utils.SendError(w, http.StatusBadRequest, err.Error(), nil)
+			return
+		}
+		if err != nil {
+			utils.SendError(w, http.StatusInternalServerError, "failed to validate tags", err)
+			return
+		}
+		if len(unknownTags) > 0 {
+			utils.SendError(w, http.StatusBadRequest, "unknown tags", map[string]interface{}{
+				"unknown_tags": unknownTags,
+			})
+			return
+		}
+		req.Tags = tags
+	}
+
+	if req.Equipments != nil {
+		equipments, fieldErrors, err := h.normalizePostEquipment(r.Context(), req.Equipments)
+		if err != nil {
+			sendGW2Error(w, err)
+			return
+		}
+		if len(fieldErrors) > 0 {
+			sendEquipmentErrors(w, fieldErrors)
+			return
+		}
+		if equipments == nil {
+			// Still clears the equipment, which a nil value would leave as it is
+			equipments = json.RawMessage("null")
+		}
+		req.Equipments = equipments
+	}
+
+	post.Equipments = rawJSON(post.Equipments)
+	post.Tags = rawJSON(post.Tags)
+
+	updated := *post
+	applyPostUpdate(&updated, req)
+
+	changes := diffPosts(post, &updated)
+	if len(changes) == 0 {
+		utils.SendError(w, http.StatusBadRequest, "no changes to apply", nil)
+		return
+	}
+
+	previousStatus, err := h.postRepo.UpdatePost(r.Context(), updated, user.Name)
+	if errors.Is(err, repo.ErrInvalidTransition) {
+		utils.SendError(w, http.StatusConflict, "this post can no longer be edited", nil)
+		return
+	}
+	if errors.Is(err, repo.ErrPostNotFound) {
+		utils.SendError(w, http.StatusNotFound, "post not found", nil)
+		return
+	}
+	if err != nil {
+		utils.SendError(w, http.StatusInternalServerError, "failed to update post", err)
+		return
+	}
+
+	// Published and rejected posts have to be approved again (async, don't block response)
+	requeued := previousStatus == repo.PostStatusPublished || previousStatus == repo.PostStatusRejected
+	if requeued {
+		go func() {
+			if err := h.SendPostToDiscord(&updated, changes); err != nil {
+				slog.Error("Failed to send edited post to Discord", "postID", postID, "error", err)
+			}
+		}()
+	}
+
+	updatedPost, err := h.postRepo.GetPostByID(r.Context(), postID)
+	if err != nil || updatedPost == nil {
+		utils.SendError(w, http.StatusInternalServerError, "failed to fetch updated post", err)
+		return
+	}
+	updatedPost.Equipments = rawJSON(updatedPost.Equipments)
+	updatedPost.Tags = rawJSON(updatedPost.Tags)
+
+	utils.SendData(w, http.StatusOK, map[string]interface{}{
+		"message":  "post updated successfully",
+		"requeued": requeued,
+		"changes":  changes,
+		"post":     updatedPost,
+	})
+}
+
+// GetPostRevisionsHandler handles GET /api/v1/posts/{id}/revisions
+// Returns the edit history of a post to its author
+func (h *Handlers) GetPostRevisionsHandler(w http.ResponseWriter, r *http.Request) {
+	user, err := utils.GetUserFromContext(r.Context())
+	if err != nil {
+		utils.SendError(w, http.StatusUnauthorized, "unauthorized", err)
+		return
+	}
+
+	postID := r.PathValue("id")
+	if postID == "" {
+		utils.SendError(w, http.StatusBadRequest, "post ID is required", nil)
+		return
+	}
+
+	post, err := h.postRepo.GetPostByID(r.Context(), postID)
+	if err != nil {
+		utils.SendError(w, http.StatusInternalServerError, "failed to fetch post", err)
+		return
+	}
+	if post == nil {
+		utils.SendError(w, http.StatusNotFound, "post not found", nil)
+		return
+	}
+
+	if post.AuthorName != user.Name {
+		utils.SendError(w, http.StatusForbidden, "you can only view the history of your own posts", nil)
+		return
+	}
+
+	revisions, err := h.postRepo.GetPostRevisions(r.Context(), postID)
+	if err != nil {
+		utils.SendError(w, http.StatusInternalServerError, "failed to fetch post revisions", err)
+		return
+	}
+
+	utils.SendData(w, http.StatusOK, map[string]interface{}{
+		"success":   true,
+		"post_id":   postID,
+		"revisions": revisions,
+	})
+}
+
+// applyPostUpdate copies every field present in the request onto the post
+func applyPostUpdate(post *repo.Post, req UpdatePostRequest) {
+	if req.Title != nil {
+		post.Title = *req.Title
+	}
+	if req.Description != nil {
+		post.Description = *req.Description
+	}
+	if req.ThumbnailURL != nil {
+		post.Thumbnail = *req.ThumbnailURL
+	}
+	if req.Image1URL != nil {
+		post.Image1 = *req.Image1URL
+	}
+	if req.Image2URL != nil {
+		post.Image2 = *req.Image2URL
+	}
+	if req.Image3URL != nil {
+		post.Image3 = *req.Image3URL
+	}
+	if req.Image4URL != nil {
+		post.Image4 = *req.Image4URL
+	}
+	if req.Image5URL != nil {
+		post.Image5 = *req.Image5URL
+	}
+	if req.Equipments != nil {
+		post.Equipments = req.Equipments
+	}
+	if req.Tags != nil {
+		post.Tags = req.Tags
+	}
+}
+
+// diffPosts returns a human readable list of what changed between two versions of a post
+func diffPosts(before, after *repo.Post) []string {
+	changes := []string{}
+
+	if before.Title != after.Title {
+		changes = append(changes, fmt.Sprintf("Title: %q → %q", before.Title, after.Title))
+	}
+	if before.Description != after.Description {
+		changes = append(changes, "Description updated")
+	}
+	if before.Thumbnail != after.Thumbnail {
+		changes = append(changes, "Thumbnail changed")
+	}
+
+	images := [][2]string{
+		{before.Image1, after.Image1},
+		{before.Image2, after.Image2},
+		{before.Image3, after.Image3},
+		{before.Image4, after.Image4},
+		{before.Image5, after.Image5},
+	}
+	for i, img := range images {
+		switch {
+		case img[0] == img[1]:
+		case img[0] == "":
+			changes = append(changes, fmt.Sprintf("Image %d added", i+1))
+		case img[1] == "":
+			changes = append(changes, fmt.Sprintf("Image %d removed", i+1))
+		default:
+			changes = append(changes, fmt.Sprintf("Image %d changed", i+1))
+		}
+	}
+
+	if !jsonEqual(rawJSON(before.Equipments), rawJSON(after.Equipments)) {
+		changes = append(changes, "Equipment changed")
+	}
+
+	added, removed := diffTags(tagList(before.Tags), tagList(after.Tags))
+	for _, tag := range added {
+		changes = append(changes, "Tag added: "+tag)
+	}
+	for _, tag := range removed {
+		changes = append(changes, "Tag removed: "+tag)
+	}
+
+	return changes
+}
+
+// diffTags returns the tags only present in after and the tags only present in before
+func diffTags(before, after []string) (added, removed []string) {
+	inBefore := make(map[string]bool, len(before))
+	for _, tag := range before {
+		inBefore[tag] = true
+	}
+	inAfter := make(map[string]bool, len(after))
+	for _, tag := range after {
+		inAfter[tag] = true
+		if !inBefore[tag] {
+			added = append(added, tag)
+		}
+	}
+	for _, tag := range before {
+		if !inAfter[tag] {
+			removed = append(removed, tag)
+		}
+	}
+	return added, removed
+}
+
+// rawJSON converts a JSON column value scanned into interface{} back into raw JSON
+// so it is encoded as JSON instead of a base64 byte string
+func rawJSON(v interface{}) json.RawMessage {
+	switch value := v.(type) {
+	case nil:
+		return nil
+	case json.RawMessage:
+		return value
+	case []byte:
+		return json.RawMessage(value)
+	default:
+		data, err := json.Marshal(value)
+		if err != nil {
+			return nil
+		}
+		return data
+	}
+}
+
+// jsonEqual compares two JSON documents ignoring insignificant whitespace
+func jsonEqual(a, b json.RawMessage) bool {
+	var bufA, bufB bytes.Buffer
+	if json.Compact(&bufA, a) != nil || json.Compact(&bufB, b) != nil {
+		return bytes.Equal(a, b)
+	}
+	return bytes.Equal(bufA.Bytes(), bufB.Bytes())
+}
+
+// tagList parses a tags JSON array into a string slice
+func tagList(v interface{}) []string {
+	var tags []string
+	if err := json.Unmarshal(rawJSON(v), &tags); err != nil {
+		return nil
+	}
+	return tags
+}
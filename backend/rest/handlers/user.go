@@ -1,8 +1,10 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 
+	"github.com/NesoHQ/gw2style/repo"
 	"github.com/NesoHQ/gw2style/rest/utils"
 )
 
@@ -15,27 +17,33 @@ func (h *Handlers) GetCurrentUserHandler(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	role, err := h.roleRepo.GetUserRole(r.Context(), user.ID)
+	if err != nil {
+		utils.SendError(w, http.StatusInternalServerError, "failed to fetch role", err)
+		return
+	}
+
 	utils.SendData(w, http.StatusOK, map[string]interface{}{
 		"success": true,
 		"user": map[string]interface{}{
 			"id":       user.ID,
 			"username": user.Name,
+			"role":     role,
 		},
 	})
 }
 
-// LogoutHandler clears the JWT cookie
+// LogoutHandler revokes the current session and clears the token cookies
 func (h *Handlers) LogoutHandler(w http.ResponseWriter, r *http.Request) {
-	// Clear the HTTP-only cookie
-	http.SetCookie(w, &http.Cookie{
-		Name:     "jwt_token",
-		Value:    "",
-		HttpOnly: true,
-		Secure:   false, // Set to true in production with HTTPS
-		SameSite: http.SameSiteStrictMode,
-		Path:     "/",
-		MaxAge:   -1, // Delete cookie
-	})
+	if cookie, err := r.Cookie(refreshTokenCookie); err == nil && cookie.Value != "" {
+		err := h.sessionRepo.RevokeSessionByRefreshToken(r.Context(), cookie.Value, repo.SessionRevokedLogout)
+		if err != nil && !errors.Is(err, repo.ErrSessionNotFound) {
+			utils.SendError(w, http.StatusInternalServerError, "failed to revoke session", err)
+			return
+		}
+	}
+
+	h.clearAuthCookies(w)
 
 	utils.SendData(w, http.StatusOK, map[string]interface{}{
 		"success": true,
@@ -1,11 +1,34 @@
 package handlers
 
 import (
+	"encoding/json"
+	"log/slog"
 	"net/http"
+	"time"
 
+	"github.com/NesoHQ/gw2style/apikey"
+	"github.com/NesoHQ/gw2style/gw2api"
+	"github.com/NesoHQ/gw2style/repo"
 	"github.com/NesoHQ/gw2style/rest/utils"
 )
 
+// UserAPIKeyResponse describes the user's stored GW2 API key without the key itself
+type UserAPIKeyResponse struct {
+	APIKey        string     `json:"apiKey"` // Masked, see apikey.Mask
+	Permissions   []string   `json:"permissions"`
+	ValidatedAt   *time.Time `json:"validatedAt"`
+	InvalidatedAt *time.Time `json:"invalidatedAt"`
+}
+
+func newUserAPIKeyResponse(user *repo.User) UserAPIKeyResponse {
+	return UserAPIKeyResponse{
+		APIKey:        apikey.Mask(user.ApiKey),
+		Permissions:   user.ApiKeyPermissions,
+		ValidatedAt:   user.ApiKeyValidatedAt,
+		InvalidatedAt: user.ApiKeyInvalidatedAt,
+	}
+}
+
 func (h *Handlers) GetUserAPIKeyHandler(w http.ResponseWriter, r *http.Request) {
 	// Get user from JWT context
 	user, err := utils.GetUserFromContext(r.Context())
@@ -14,10 +37,11 @@ func (h *Handlers) GetUserAPIKeyHandler(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	// Get user details including API key
+	// Get user details including the decrypted API key
 	dbUser, err := h.repoUser.FindUser(user.ID)
 	if err != nil {
-		utils.SendError(w, http.StatusInternalServerError, "failed to fetch user data", err)
+		slog.Error("Failed to fetch user data", "user_id", user.ID, "error", err.Error())
+		utils.SendError(w, http.StatusInternalServerError, "failed to fetch user data", nil)
 		return
 	}
 
@@ -26,12 +50,67 @@ func (h *Handlers) GetUserAPIKeyHandler(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	// Return only the API key
-	response := struct {
+	// Never send the key itself back to the browser
+	utils.SendData(w, http.StatusOK, newUserAPIKeyResponse(dbUser))
+}
+
+// ReplaceUserAPIKeyHandler handles PUT /api/v1/user/apikey
+// Swaps in a new GW2 API key after checking its permissions and that it belongs to the same account
+func (h *Handlers) ReplaceUserAPIKeyHandler(w http.ResponseWriter, r *http.Request) {
+	user, err := utils.GetUserFromContext(r.Context())
+	if err != nil {
+		utils.SendError(w, http.StatusUnauthorized, "unauthorized", err)
+		return
+	}
+
+	var req struct {
 		APIKey string `json:"apiKey"`
-	}{
-		APIKey: dbUser.ApiKey,
 	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.SendError(w, http.StatusBadRequest, "invalid JSON body", nil)
+		return
+	}
+	if req.APIKey == "" {
+		utils.SendError(w, http.StatusBadRequest, "API key is required", nil)
+		return
+	}
+
+	permissions, err := h.gw2.CheckPermissions(r.Context(), req.APIKey, gw2api.RequiredPermissions...)
+	if err != nil {
+		sendGW2Error(w, err)
+		return
+	}
+
+	account, err := h.gw2.Account(r.Context(), req.APIKey)
+	if err != nil {
+		sendGW2Error(w, err)
+		return
+	}
+
+	if account.ID != user.ID {
+		utils.SendError(w, http.StatusForbidden, "API key belongs to a different GW2 account", nil)
+		return
+	}
+
+	dbUser, err := h.repoUser.FindUser(user.ID)
+	if err != nil {
+		slog.Error("Failed to fetch user data", "user_id", user.ID, "error", err.Error())
+		utils.SendError(w, http.StatusInternalServerError, "failed to fetch user data", nil)
+		return
+	}
+
+	updated, err := h.repoUser.ReplaceAPIKey(repo.User{
+		ID:                user.ID,
+		Name:              account.Name,
+		ApiKey:            req.APIKey,
+		ApiKeyPermissions: permissions,
+	})
+	if err != nil {
+		slog.Error("Failed to store API key", "user_id", user.ID, "error", err.Error())
+		utils.SendError(w, http.StatusInternalServerError, "failed to store API key", nil)
+		return
+	}
+	h.gw2.Invalidate(dbUser.ApiKey)
 
-	utils.SendData(w, http.StatusOK, response)
+	utils.SendData(w, http.StatusOK, newUserAPIKeyResponse(updated))
 }
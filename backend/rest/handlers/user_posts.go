@@ -0,0 +1,50 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/NesoHQ/gw2style/repo"
+	"github.com/NesoHQ/gw2style/rest/utils"
+)
+
+// GetUserPostsHandler handles GET /api/v1/user/posts
+// Returns the authenticated user's own posts in every status with their moderation outcome
+func (h *Handlers) GetUserPostsHandler(w http.ResponseWriter, r *http.Request) {
+	user, err := utils.GetUserFromContext(r.Context())
+	if err != nil {
+		utils.SendError(w, http.StatusUnauthorized, "unauthorized", err)
+		return
+	}
+
+	status := r.URL.Query().Get("status")
+	if status != "" && !repo.ValidPostStatus(status) {
+		utils.SendError(w, http.StatusBadRequest, "invalid status", nil)
+		return
+	}
+
+	page, limit := parsePage(r, 20)
+
+	posts, totalCount, err := h.postRepo.GetAuthorPosts(r.Context(), user.Name, repo.PostStatus(status), limit, (page-1)*limit)
+	if err != nil {
+		utils.SendError(w, http.StatusInternalServerError, "failed to fetch posts", err)
+		return
+	}
+
+	stats, err := h.postRepo.GetAuthorPostStats(r.Context(), user.Name)
+	if err != nil {
+		utils.SendError(w, http.StatusInternalServerError, "failed to fetch post stats", err)
+		return
+	}
+
+	utils.SendData(w, http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    posts,
+		"stats":   stats,
+		"pagination": map[string]interface{}{
+			"page":        page,
+			"limit":       limit,
+			"total":       totalCount,
+			"total_pages": (totalCount + limit - 1) / limit,
+		},
+	})
+}
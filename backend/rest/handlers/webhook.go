@@ -5,11 +5,16 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"strings"
+	"time"
 
 	"github.com/NesoHQ/gw2style/config"
 	"github.com/NesoHQ/gw2style/repo"
 )
 
+// webhookClient posts to Discord; the timeout keeps a slow Discord from holding up requests
+var webhookClient = &http.Client{Timeout: 10 * time.Second}
+
 type DiscordEmbed struct {
 	Title       string                 `json:"title"`
 	Description string                 `json:"description"`
@@ -43,10 +48,10 @@ type DiscordWebhookPayload struct {
 	Embeds  []DiscordEmbed `json:"embeds,omitempty"`
 }
 
-// SendPostToDiscord sends a new post notification to Discord
-func (h *Handlers) SendPostToDiscord(post *repo.Post) error {
-	cfg := config.GetConfig()
-
+// SendPostToDiscord sends a post notification to Discord for moderation.
+// When changes is non-empty the post is announced as an edit of a post that
+// was already reviewed and the changes are listed in the embed.
+func (h *Handlers) SendPostToDiscord(post *repo.Post, changes []string) error {
 	// Build tags string
 	tagsStr := "None"
 	if post.Tags != nil {
@@ -91,6 +96,18 @@ func (h *Handlers) SendPostToDiscord(post *repo.Post) error {
 		},
 	}
 
+	content := fmt.Sprintf("📋 **New post awaiting moderation** (ID: %s)", post.ID)
+	if len(changes) > 0 {
+		embed.Title = "✏️ Post Edited"
+		embed.Color = 15105570 // Orange color
+		embed.Fields = append(embed.Fields, DiscordEmbedField{
+			Name:   "Changes",
+			Value:  truncateEmbedValue(strings.Join(changes, "\n")),
+			Inline: false,
+		})
+		content = fmt.Sprintf("📋 **Edited post awaiting moderation** (ID: %s)", post.ID)
+	}
+
 	// Add thumbnail if available
 	if post.Thumbnail != "" {
 		embed.Thumbnail = &DiscordEmbedThumbnail{
@@ -99,16 +116,21 @@ func (h *Handlers) SendPostToDiscord(post *repo.Post) error {
 	}
 
 	payload := DiscordWebhookPayload{
-		Content: fmt.Sprintf("📋 **New post awaiting moderation** (ID: %s)", post.ID),
+		Content: content,
 		Embeds:  []DiscordEmbed{embed},
 	}
 
+	return postDiscordWebhook(payload)
+}
+
+// postDiscordWebhook sends a payload to the moderation channel's webhook
+func postDiscordWebhook(payload DiscordWebhookPayload) error {
 	jsonData, err := json.Marshal(payload)
 	if err != nil {
 		return fmt.Errorf("error marshaling webhook payload: %w", err)
 	}
 
-	resp, err := http.Post(cfg.DiscordWebhookURL, "application/json", bytes.NewBuffer(jsonData))
+	resp, err := webhookClient.Post(config.GetConfig().DiscordWebhookURL, "application/json", bytes.NewBuffer(jsonData))
 	if err != nil {
 		return fmt.Errorf("error sending webhook: %w", err)
 	}
@@ -120,3 +142,102 @@ func (h *Handlers) SendPostToDiscord(post *repo.Post) error {
 
 	return nil
 }
+
+// truncateEmbedValue keeps a field value within Discord's 1024 character limit
+func truncateEmbedValue(value string) string {
+	const maxLen = 1024
+	runes := []rune(value)
+	if len(runes) <= maxLen {
+		return value
+	}
+	return string(runes[:maxLen-3]) + "..."
+}
+
+// SendReportToDiscord forwards a new user report to the moderation channel
+func (h *Handlers) SendReportToDiscord(report *repo.Report, post *repo.Post) error {
+	details := report.Description
+	if details == "" {
+		details = "No details provided"
+	}
+
+	embed := DiscordEmbed{
+		Title:       "🚩 Post Reported",
+		Description: fmt.Sprintf("**%s**\n\n%s", post.Title, truncateEmbedValue(details)),
+		Color:       15158332, // Red color
+		Fields: []DiscordEmbedField{
+			{
+				Name:   "Report ID",
+				Value:  fmt.Sprint(report.ID),
+				Inline: true,
+			},
+			{
+				Name:   "Reported Post",
+				Value:  fmt.Sprintf("#%s by %s", post.ID, post.AuthorName),
+				Inline: true,
+			},
+			{
+				Name:   "Reason",
+				Value:  report.Reason,
+				Inline: true,
+			},
+			{
+				Name:   "Reporter",
+				Value:  report.ReporterUsername,
+				Inline: true,
+			},
+		},
+		Footer: &DiscordEmbedFooter{
+			Text: "React with ✅ to uphold the report and unpublish the post, or ❌ to dismiss it",
+		},
+	}
+
+	if post.Thumbnail != "" {
+		embed.Thumbnail = &DiscordEmbedThumbnail{
+			URL: post.Thumbnail,
+		}
+	}
+
+	payload := DiscordWebhookPayload{
+		Content: fmt.Sprintf("🚩 **New report awaiting review** (Report ID: %d)", report.ID),
+		Embeds:  []DiscordEmbed{embed},
+	}
+
+	return postDiscordWebhook(payload)
+}
+
+// SendAutoHideToDiscord tells moderators that a post was hidden after reaching the report threshold
+func (h *Handlers) SendAutoHideToDiscord(post *repo.Post, reporters int) error {
+	embed := DiscordEmbed{
+		Title:       "🙈 Post Hidden For Review",
+		Description: fmt.Sprintf("**%s**\n\nHidden automatically after reports from %d users.", post.Title, reporters),
+		Color:       10181046, // Purple color
+		Fields: []DiscordEmbedField{
+			{
+				Name:   "Hidden Post",
+				Value:  fmt.Sprintf("#%s by %s", post.ID, post.AuthorName),
+				Inline: true,
+			},
+			{
+				Name:   "Reporters",
+				Value:  fmt.Sprint(reporters),
+				Inline: true,
+			},
+		},
+		Footer: &DiscordEmbedFooter{
+			Text: "Review the open reports to uphold or dismiss them",
+		},
+	}
+
+	if post.Thumbnail != "" {
+		embed.Thumbnail = &DiscordEmbedThumbnail{
+			URL: post.Thumbnail,
+		}
+	}
+
+	payload := DiscordWebhookPayload{
+		Content: fmt.Sprintf("🙈 **Post #%s was hidden automatically** and is now under review", post.ID),
+		Embeds:  []DiscordEmbed{embed},
+	}
+
+	return postDiscordWebhook(payload)
+}
@@ -10,50 +10,89 @@ import (
 // AuthenticateJWT middleware validates the JWT token and sets the user in context
 func (m *Middlewares) AuthenticateJWT(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		var token string
+		user, status, message, data := m.authenticate(r)
+		if user == nil {
+			utils.SendError(w, status, message, data)
+			return
+		}
+
+		// Add user to request context
+		ctx := context.WithValue(r.Context(), utils.UserContextKey, user)
+
+		// Call next handler with updated context
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// OptionalJWT sets the user in context like AuthenticateJWT when the request
+// carries a valid session, and otherwise lets it through anonymously. It is
+// for public routes that show more to the logged in owner of a resource.
+func (m *Middlewares) OptionalJWT(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if user, _, _, _ := m.authenticate(r); user != nil {
+			r = r.WithContext(context.WithValue(r.Context(), utils.UserContextKey, user))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
 
-		// Try to get JWT from cookie first (HTTP-only cookie)
-		cookie, err := r.Cookie("jwt_token")
+// authenticate returns the user of the request's session, or the error
+// response to send when there is no valid, active and unbanned session
+func (m *Middlewares) authenticate(r *http.Request) (*utils.User, int, string, interface{}) {
+	var token string
+
+	// Try to get JWT from cookie first (HTTP-only cookie)
+	cookie, err := r.Cookie("jwt_token")
+	if err == nil {
+		token = cookie.Value
+	} else {
+		// Fallback: check old cookie name for backward compatibility
+		cookie, err = r.Cookie("jwt")
 		if err == nil {
 			token = cookie.Value
-		} else {
-			// Fallback: check old cookie name for backward compatibility
-			cookie, err = r.Cookie("jwt")
-			if err == nil {
-				token = cookie.Value
-			}
 		}
+	}
 
-		// If no cookie found, check Authorization header for backward compatibility
-		if token == "" {
-			authHeader := r.Header.Get("Authorization")
-			if len(authHeader) > 7 && authHeader[:7] == "Bearer " {
-				token = authHeader[7:]
-			}
+	// If no cookie found, check Authorization header for backward compatibility
+	if token == "" {
+		authHeader := r.Header.Get("Authorization")
+		if len(authHeader) > 7 && authHeader[:7] == "Bearer " {
+			token = authHeader[7:]
 		}
+	}
 
-		if token == "" {
-			utils.SendError(w, http.StatusUnauthorized, "missing authentication token", nil)
-			return
-		}
+	if token == "" {
+		return nil, http.StatusUnauthorized, "missing authentication token", nil
+	}
 
-		// Validate JWT token
-		claims, err := utils.ValidateJWT(token)
-		if err != nil {
-			utils.SendError(w, http.StatusUnauthorized, "invalid token", err)
-			return
-		}
+	// Validate JWT token
+	claims, err := m.JWTSigner.ValidateJWT(token)
+	if err != nil {
+		return nil, http.StatusUnauthorized, "invalid token", err
+	}
 
-		// Create user from claims
-		user := &utils.User{
-			ID:   claims.UserID,
-			Name: claims.Username,
-		}
+	// Reject tokens whose session was logged out or revoked
+	active, err := m.SessionRepo.IsSessionActive(r.Context(), claims.SessionID, claims.UserID)
+	if err != nil {
+		return nil, http.StatusInternalServerError, "failed to check session", err
+	}
+	if !active {
+		return nil, http.StatusUnauthorized, "session revoked", nil
+	}
 
-		// Add user to request context
-		ctx := context.WithValue(r.Context(), utils.UserContextKey, user)
+	// Banned and suspended accounts cannot use their existing sessions either
+	ban, err := m.BanRepo.GetActiveBan(r.Context(), claims.UserID)
+	if err != nil {
+		return nil, http.StatusInternalServerError, "failed to check ban", err
+	}
+	if ban != nil {
+		return nil, http.StatusForbidden, ban.Message(), ban.Notice()
+	}
 
-		// Call next handler with updated context
-		next.ServeHTTP(w, r.WithContext(ctx))
-	})
+	// Create user from claims
+	return &utils.User{
+		ID:        claims.UserID,
+		Name:      claims.Username,
+		SessionID: claims.SessionID,
+	}, 0, "", nil
 }
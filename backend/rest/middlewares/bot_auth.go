@@ -1,37 +1,30 @@
 package middlewares
 
 import (
+	"errors"
+	"log/slog"
 	"net/http"
-	"strings"
 
-	"github.com/NesoHQ/gw2style/config"
 	"github.com/NesoHQ/gw2style/rest/utils"
+	"github.com/NesoHQ/gw2style/serviceauth"
 )
 
-// AuthenticateBot validates that the request comes from the Discord bot
+// AuthenticateBot validates that the request was signed by the Discord bot
+// with one of the SERVICE_AUTH_KEYS
 func (m *Middlewares) AuthenticateBot(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		authHeader := r.Header.Get("Authorization")
-		if authHeader == "" {
-			utils.SendError(w, http.StatusUnauthorized, "missing authorization header", nil)
-			return
+		err := m.ServiceVerifier.Verify(r)
+		switch {
+		case err == nil:
+			next.ServeHTTP(w, r)
+		case errors.Is(err, serviceauth.ErrMissingSignature):
+			utils.SendError(w, http.StatusUnauthorized, "missing service signature", nil)
+		case errors.Is(err, serviceauth.ErrUnknownKey), errors.Is(err, serviceauth.ErrStaleTimestamp),
+			errors.Is(err, serviceauth.ErrReplayed), errors.Is(err, serviceauth.ErrBadSignature):
+			slog.Warn("Rejected service request", "error", err.Error(), "ip", r.RemoteAddr)
+			utils.SendError(w, http.StatusUnauthorized, err.Error(), nil)
+		default:
+			utils.SendError(w, http.StatusBadRequest, "failed to read request", err)
 		}
-
-		// Expected format: "Bot <token>"
-		parts := strings.Split(authHeader, " ")
-		if len(parts) != 2 || parts[0] != "Bot" {
-			utils.SendError(w, http.StatusUnauthorized, "invalid authorization format", nil)
-			return
-		}
-
-		token := parts[1]
-		cfg := config.GetConfig()
-
-		if token != cfg.DiscordBotToken {
-			utils.SendError(w, http.StatusUnauthorized, "invalid bot token", nil)
-			return
-		}
-
-		next.ServeHTTP(w, r)
 	})
 }
@@ -2,19 +2,29 @@ package middlewares
 
 import (
 	"net/http"
+	"strings"
 
 	"github.com/rs/cors"
 )
 
-func EnableCors(mux *http.ServeMux) http.Handler {
+// EnableCors lets the allowed origins (CORS_ALLOWED_ORIGINS) call the API with
+// credentials. Other origins get no CORS headers, so browsers block them from
+// reading responses and from sending non-simple requests. Origins are matched
+// with AllowOriginFunc because rs/cors treats an empty AllowedOrigins as
+// allowing every origin.
+func EnableCors(mux *http.ServeMux, origins []string) http.Handler {
+	allowed := make(map[string]bool, len(origins))
+	for _, origin := range origins {
+		allowed[strings.ToLower(origin)] = true
+	}
+
 	c := cors.New(cors.Options{
 		AllowOriginFunc: func(origin string) bool {
-			// Allow all origins for now
-			return true
+			return allowed[strings.ToLower(origin)]
 		},
 		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS", "PATCH"},
 		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token", "Cookie"},
-		ExposedHeaders:   []string{"Set-Cookie"},
+		ExposedHeaders:   []string{"Set-Cookie", "Retry-After", "RateLimit-Limit", "RateLimit-Remaining", "RateLimit-Reset"},
 		AllowCredentials: true,
 		Debug:            false,
 	})
@@ -0,0 +1,42 @@
+package middlewares
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func corsPreflight(handler http.Handler, origin string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodOptions, "/api/v1/posts", nil)
+	req.Header.Set("Origin", origin)
+	req.Header.Set("Access-Control-Request-Method", http.MethodPost)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestEnableCorsAllowsListedOrigins(t *testing.T) {
+	handler := EnableCors(http.NewServeMux(), []string{"https://gw2style.com"})
+
+	rec := corsPreflight(handler, "https://gw2style.com")
+	if got := rec.Header().Get("Access-Control-Allow-Origin"); got != "https://gw2style.com" {
+		t.Errorf("Access-Control-Allow-Origin = %q, want https://gw2style.com", got)
+	}
+	if got := rec.Header().Get("Access-Control-Allow-Credentials"); got != "true" {
+		t.Errorf("Access-Control-Allow-Credentials = %q, want true", got)
+	}
+
+	rec = corsPreflight(handler, "https://evil.example")
+	if got := rec.Header().Get("Access-Control-Allow-Origin"); got != "" {
+		t.Errorf("Access-Control-Allow-Origin = %q for an unlisted origin", got)
+	}
+}
+
+func TestEnableCorsEmptyListAllowsNoOrigin(t *testing.T) {
+	handler := EnableCors(http.NewServeMux(), nil)
+
+	rec := corsPreflight(handler, "https://evil.example")
+	if got := rec.Header().Get("Access-Control-Allow-Origin"); got != "" {
+		t.Errorf("Access-Control-Allow-Origin = %q with no allowed origins", got)
+	}
+}
@@ -0,0 +1,56 @@
+package middlewares
+
+import (
+	"crypto/subtle"
+	"net/http"
+
+	"github.com/NesoHQ/gw2style/rest/utils"
+)
+
+// Cookies that authenticate a request on their own; browsers attach them to
+// cross-site requests, which is what CSRF tokens guard against
+var authCookies = []string{"jwt_token", "jwt", "refresh_token"}
+
+// RequireCSRF checks the double-submit CSRF token on state-changing requests
+// authenticated by cookies: the X-CSRF-Token header must match the csrf_token
+// cookie, and once AuthenticateJWT has run, belong to the user's session. List
+// it before AuthenticateJWT in Manager.With. Requests without auth cookies,
+// such as Bearer-token and signed bot requests, are exempt.
+func (m *Middlewares) RequireCSRF(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if !hasAuthCookie(r) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		header := r.Header.Get(utils.CSRFHeaderName)
+		cookie, err := r.Cookie(utils.CSRFCookieName)
+		if header == "" || err != nil || subtle.ConstantTimeCompare([]byte(header), []byte(cookie.Value)) != 1 {
+			utils.SendError(w, http.StatusForbidden, "invalid CSRF token", nil)
+			return
+		}
+
+		// Refresh and logout run without AuthenticateJWT, so only the double submit is checked there
+		if user, err := utils.GetUserFromContext(r.Context()); err == nil && !m.JWTSigner.ValidCSRFToken(header, user.SessionID) {
+			utils.SendError(w, http.StatusForbidden, "invalid CSRF token", nil)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+func hasAuthCookie(r *http.Request) bool {
+	for _, name := range authCookies {
+		if cookie, err := r.Cookie(name); err == nil && cookie.Value != "" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,104 @@
+package middlewares
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/NesoHQ/gw2style/config"
+	"github.com/NesoHQ/gw2style/rest/utils"
+)
+
+func newCSRFMiddlewares(t *testing.T) *Middlewares {
+	t.Helper()
+	signer, err := utils.NewJWTSigner(&config.Config{JwtSecret: strings.Repeat("j", config.MinJWTSecretLength)})
+	if err != nil {
+		t.Fatalf("NewJWTSigner: %v", err)
+	}
+	return &Middlewares{JWTSigner: signer}
+}
+
+// csrfRequest builds a cookie-authenticated request carrying the given CSRF cookie and header
+func csrfRequest(method, cookie, header string) *http.Request {
+	req := httptest.NewRequest(method, "/api/v1/posts", nil)
+	req.AddCookie(&http.Cookie{Name: "jwt_token", Value: "token"})
+	if cookie != "" {
+		req.AddCookie(&http.Cookie{Name: utils.CSRFCookieName, Value: cookie})
+	}
+	if header != "" {
+		req.Header.Set(utils.CSRFHeaderName, header)
+	}
+	return req
+}
+
+func withSession(req *http.Request, sessionID string) *http.Request {
+	user := &utils.User{ID: "acc-1", Name: "Player.1234", SessionID: sessionID}
+	return req.WithContext(context.WithValue(req.Context(), utils.UserContextKey, user))
+}
+
+func serveCSRF(m *Middlewares, req *http.Request) int {
+	rec := httptest.NewRecorder()
+	m.RequireCSRF(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})).ServeHTTP(rec, req)
+	return rec.Code
+}
+
+func TestCSRFToken(t *testing.T) {
+	m := newCSRFMiddlewares(t)
+
+	token, err := m.JWTSigner.CSRFToken("session-1")
+	if err != nil {
+		t.Fatalf("CSRFToken: %v", err)
+	}
+	if !m.JWTSigner.ValidCSRFToken(token, "session-1") {
+		t.Error("token is not valid for its own session")
+	}
+	if m.JWTSigner.ValidCSRFToken(token, "session-2") {
+		t.Error("token is valid for another session")
+	}
+
+	parts := strings.Split(token, ".")
+	for _, bad := range []string{"", "a.b", token + "x", "unknown." + parts[1] + "." + parts[2]} {
+		if m.JWTSigner.ValidCSRFToken(bad, "session-1") {
+			t.Errorf("ValidCSRFToken(%q) = true", bad)
+		}
+	}
+}
+
+func TestRequireCSRF(t *testing.T) {
+	m := newCSRFMiddlewares(t)
+	token, err := m.JWTSigner.CSRFToken("session-1")
+	if err != nil {
+		t.Fatalf("CSRFToken: %v", err)
+	}
+	other, err := m.JWTSigner.CSRFToken("session-2")
+	if err != nil {
+		t.Fatalf("CSRFToken: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		req  *http.Request
+		want int
+	}{
+		{"safe method", csrfRequest(http.MethodGet, "", ""), http.StatusNoContent},
+		{"no auth cookie", httptest.NewRequest(http.MethodPost, "/api/v1/posts", nil), http.StatusNoContent},
+		{"missing header", csrfRequest(http.MethodPost, token, ""), http.StatusForbidden},
+		{"missing cookie", csrfRequest(http.MethodPost, "", token), http.StatusForbidden},
+		{"header differs from cookie", csrfRequest(http.MethodPost, token, other), http.StatusForbidden},
+		{"double submit without session", csrfRequest(http.MethodPost, token, token), http.StatusNoContent},
+		{"token of the session", withSession(csrfRequest(http.MethodPost, token, token), "session-1"), http.StatusNoContent},
+		{"token of another session", withSession(csrfRequest(http.MethodPost, other, other), "session-1"), http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := serveCSRF(m, tt.req); got != tt.want {
+				t.Errorf("status = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
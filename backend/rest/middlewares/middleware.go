@@ -2,14 +2,31 @@ package middlewares
 
 import (
 	"github.com/NesoHQ/gw2style/config"
+	"github.com/NesoHQ/gw2style/repo"
+	"github.com/NesoHQ/gw2style/rest/utils"
+	"github.com/NesoHQ/gw2style/serviceauth"
 )
 
 type Middlewares struct {
-	Cnf *config.Config
+	Cnf         *config.Config
+	JWTSigner   *utils.JWTSigner
+	SessionRepo *repo.SessionRepository
+	RoleRepo    *repo.RoleRepository
+	BanRepo     *repo.BanRepository
+	// Verifies requests signed by the Discord bot
+	ServiceVerifier *serviceauth.Verifier
+	// Token buckets of RateLimit; replace it to share limits between instances
+	RateLimitStore RateLimitStore
 }
 
-func NewMiddleware(cnf *config.Config) *Middlewares {
+func NewMiddleware(cnf *config.Config, jwtSigner *utils.JWTSigner, serviceVerifier *serviceauth.Verifier, sessionRepo *repo.SessionRepository, roleRepo *repo.RoleRepository, banRepo *repo.BanRepository) *Middlewares {
 	return &Middlewares{
-		Cnf: cnf,
+		Cnf:             cnf,
+		JWTSigner:       jwtSigner,
+		ServiceVerifier: serviceVerifier,
+		SessionRepo:     sessionRepo,
+		RoleRepo:        roleRepo,
+		BanRepo:         banRepo,
+		RateLimitStore:  NewMemoryRateLimitStore(),
 	}
 }
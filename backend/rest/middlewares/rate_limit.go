@@ -0,0 +1,73 @@
+package middlewares
+
+import (
+	"log/slog"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/NesoHQ/gw2style/rest/utils"
+)
+
+// maxRateLimitWindow is the longest window a policy may use; idle buckets
+// are dropped from the memory store after it
+const maxRateLimitWindow = 24 * time.Hour
+
+// RateLimitPolicy allows bursts of Limit requests, refilled evenly over Window
+type RateLimitPolicy struct {
+	Name   string // separates the buckets of different routes
+	Limit  int
+	Window time.Duration
+}
+
+func (p RateLimitPolicy) rate() float64 {
+	return float64(p.Limit) / p.Window.Seconds()
+}
+
+// RateLimit throttles requests with a token bucket per policy and client. The
+// client is the logged in user when AuthenticateJWT ran first (list RateLimit
+// before AuthenticateJWT in Manager.With), otherwise the client IP.
+func (m *Middlewares) RateLimit(policy RateLimitPolicy) Middleware {
+	if policy.Limit < 1 || policy.Window <= 0 || policy.Window > maxRateLimitWindow {
+		panic("invalid rate limit policy " + policy.Name)
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := policy.Name + ":ip:" + m.clientIP(r)
+			if user, err := utils.GetUserFromContext(r.Context()); err == nil {
+				key = policy.Name + ":user:" + user.ID
+			}
+
+			result, err := m.RateLimitStore.Take(r.Context(), key, policy)
+			if err != nil {
+				// Fail open: a broken store must not take the API down
+				slog.Error("Rate limit store failed", "policy", policy.Name, "error", err.Error())
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			w.Header().Set("RateLimit-Limit", strconv.Itoa(result.Limit))
+			w.Header().Set("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+			w.Header().Set("RateLimit-Reset", ceilSeconds(result.Reset))
+
+			if !result.Allowed {
+				w.Header().Set("Retry-After", ceilSeconds(result.RetryAfter))
+				utils.SendError(w, http.StatusTooManyRequests, "too many requests, try again later", nil)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// clientIP is the address of the client, see utils.ClientIP
+func (m *Middlewares) clientIP(r *http.Request) string {
+	return utils.ClientIP(r, m.Cnf.TrustProxyHeaders)
+}
+
+func ceilSeconds(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
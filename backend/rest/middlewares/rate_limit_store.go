@@ -0,0 +1,92 @@
+package middlewares
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// RateLimitResult is the outcome of taking a token from a bucket
+type RateLimitResult struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int
+	RetryAfter time.Duration // until the next token, when not allowed
+	Reset      time.Duration // until the bucket is full again
+}
+
+// RateLimitStore keeps the token buckets. The in-memory store only limits a
+// single instance; a shared store (e.g. Redis) can implement the same interface.
+type RateLimitStore interface {
+	Take(ctx context.Context, key string, policy RateLimitPolicy) (RateLimitResult, error)
+}
+
+type bucket struct {
+	tokens   float64
+	lastFill time.Time
+}
+
+// MemoryRateLimitStore is a RateLimitStore kept in process memory
+type MemoryRateLimitStore struct {
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+func NewMemoryRateLimitStore() *MemoryRateLimitStore {
+	return &MemoryRateLimitStore{buckets: make(map[string]*bucket), lastSweep: time.Now()}
+}
+
+// memorySweepInterval is how often buckets that have refilled completely are dropped
+const memorySweepInterval = time.Minute
+
+func (s *MemoryRateLimitStore) Take(ctx context.Context, key string, policy RateLimitPolicy) (RateLimitResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	rate := policy.rate()
+	capacity := float64(policy.Limit)
+
+	if now.Sub(s.lastSweep) > memorySweepInterval {
+		s.sweep(now)
+	}
+
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &bucket{tokens: capacity, lastFill: now}
+		s.buckets[key] = b
+	}
+	b.tokens = math.Min(capacity, b.tokens+now.Sub(b.lastFill).Seconds()*rate)
+	b.lastFill = now
+
+	result := RateLimitResult{Limit: policy.Limit}
+	if b.tokens >= 1 {
+		b.tokens--
+		result.Allowed = true
+	} else {
+		result.RetryAfter = secondsToDuration((1 - b.tokens) / rate)
+	}
+	result.Remaining = int(b.tokens)
+	result.Reset = secondsToDuration((capacity - b.tokens) / rate)
+
+	return result, nil
+}
+
+// sweep drops full buckets; a missing bucket behaves exactly like a full one.
+// Buckets do not record their policy, so a bucket counts as full once it has
+// been idle long enough to refill at the slowest rate, which is bounded by
+// maxRateLimitWindow.
+func (s *MemoryRateLimitStore) sweep(now time.Time) {
+	for key, b := range s.buckets {
+		if now.Sub(b.lastFill) > maxRateLimitWindow {
+			delete(s.buckets, key)
+		}
+	}
+	s.lastSweep = now
+}
+
+func secondsToDuration(seconds float64) time.Duration {
+	return time.Duration(seconds * float64(time.Second))
+}
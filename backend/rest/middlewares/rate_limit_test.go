@@ -0,0 +1,137 @@
+package middlewares
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/NesoHQ/gw2style/config"
+	"github.com/NesoHQ/gw2style/rest/utils"
+)
+
+var testPolicy = RateLimitPolicy{Name: "test", Limit: 3, Window: time.Minute}
+
+func TestMemoryRateLimitStoreBurst(t *testing.T) {
+	store := NewMemoryRateLimitStore()
+	ctx := context.Background()
+
+	for i := range testPolicy.Limit {
+		result, err := store.Take(ctx, "client", testPolicy)
+		if err != nil {
+			t.Fatalf("Take: %v", err)
+		}
+		if !result.Allowed {
+			t.Fatalf("request %d denied inside the burst", i+1)
+		}
+		if want := testPolicy.Limit - i - 1; result.Remaining != want {
+			t.Errorf("request %d: Remaining = %d, want %d", i+1, result.Remaining, want)
+		}
+	}
+
+	result, err := store.Take(ctx, "client", testPolicy)
+	if err != nil {
+		t.Fatalf("Take: %v", err)
+	}
+	if result.Allowed {
+		t.Fatal("request allowed past the burst")
+	}
+	// One token is refilled every Window/Limit
+	if result.RetryAfter <= 0 || result.RetryAfter > testPolicy.Window/time.Duration(testPolicy.Limit) {
+		t.Errorf("RetryAfter = %v, want at most %v", result.RetryAfter, testPolicy.Window/time.Duration(testPolicy.Limit))
+	}
+	if result.Reset <= result.RetryAfter || result.Reset > testPolicy.Window {
+		t.Errorf("Reset = %v, want between RetryAfter and the window", result.Reset)
+	}
+
+	// Other clients have their own bucket
+	if result, _ := store.Take(ctx, "other", testPolicy); !result.Allowed {
+		t.Error("another client was denied")
+	}
+}
+
+func TestMemoryRateLimitStoreRefill(t *testing.T) {
+	store := NewMemoryRateLimitStore()
+	ctx := context.Background()
+
+	for range testPolicy.Limit {
+		store.Take(ctx, "client", testPolicy)
+	}
+
+	// Pretend one refill interval has passed
+	store.buckets["client"].lastFill = time.Now().Add(-testPolicy.Window / time.Duration(testPolicy.Limit))
+
+	if result, _ := store.Take(ctx, "client", testPolicy); !result.Allowed {
+		t.Fatal("request denied after a token was refilled")
+	}
+	if result, _ := store.Take(ctx, "client", testPolicy); result.Allowed {
+		t.Fatal("more than one token was refilled")
+	}
+
+	// A bucket never refills past its limit
+	store.buckets["client"].lastFill = time.Now().Add(-10 * testPolicy.Window)
+	if result, _ := store.Take(ctx, "client", testPolicy); result.Remaining != testPolicy.Limit-1 {
+		t.Errorf("Remaining = %d after a long idle, want %d", result.Remaining, testPolicy.Limit-1)
+	}
+}
+
+func TestMemoryRateLimitStoreSweep(t *testing.T) {
+	store := NewMemoryRateLimitStore()
+	ctx := context.Background()
+
+	store.Take(ctx, "idle", testPolicy)
+	store.buckets["idle"].lastFill = time.Now().Add(-maxRateLimitWindow - time.Minute)
+	store.lastSweep = time.Now().Add(-memorySweepInterval - time.Second)
+
+	store.Take(ctx, "active", testPolicy)
+	if _, ok := store.buckets["idle"]; ok {
+		t.Error("idle bucket was not swept")
+	}
+	if _, ok := store.buckets["active"]; !ok {
+		t.Error("active bucket was swept")
+	}
+}
+
+func TestRateLimitMiddleware(t *testing.T) {
+	m := &Middlewares{Cnf: &config.Config{}, RateLimitStore: NewMemoryRateLimitStore()}
+	handler := m.RateLimit(RateLimitPolicy{Name: "test", Limit: 1, Window: time.Minute})(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNoContent)
+		}))
+
+	serve := func(req *http.Request) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+	anonymous := func() *http.Request {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/login", nil)
+		req.RemoteAddr = "203.0.113.7:51234"
+		return req
+	}
+
+	rec := serve(anonymous())
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusNoContent)
+	}
+	if rec.Header().Get("RateLimit-Limit") != "1" || rec.Header().Get("RateLimit-Remaining") != "0" {
+		t.Errorf("RateLimit headers = %v", rec.Header())
+	}
+
+	rec = serve(anonymous())
+	if rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusTooManyRequests)
+	}
+	if rec.Header().Get("Retry-After") != "60" {
+		t.Errorf("Retry-After = %q, want 60", rec.Header().Get("Retry-After"))
+	}
+
+	// A logged in user from the same address has their own bucket
+	req := anonymous()
+	user := &utils.User{ID: "acc-1", Name: "Player.1234"}
+	req = req.WithContext(context.WithValue(req.Context(), utils.UserContextKey, user))
+	if rec := serve(req); rec.Code != http.StatusNoContent {
+		t.Errorf("status = %d for a logged in user, want %d", rec.Code, http.StatusNoContent)
+	}
+}
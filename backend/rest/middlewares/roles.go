@@ -0,0 +1,61 @@
+package middlewares
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/NesoHQ/gw2style/repo"
+	"github.com/NesoHQ/gw2style/rest/utils"
+	"github.com/NesoHQ/gw2style/serviceauth"
+)
+
+// RequireRole only lets through users whose role is at least role. It must run
+// after AuthenticateJWT, so list it before AuthenticateJWT in Manager.With.
+// The role is read from the database on every request so a revoked role takes
+// effect immediately.
+func (m *Middlewares) RequireRole(role string) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			user, err := utils.GetUserFromContext(r.Context())
+			if err != nil {
+				utils.SendError(w, http.StatusUnauthorized, "unauthorized", err)
+				return
+			}
+
+			userRole, err := m.RoleRepo.GetUserRole(r.Context(), user.ID)
+			if errors.Is(err, repo.ErrUserNotFound) {
+				utils.SendError(w, http.StatusUnauthorized, "unauthorized", nil)
+				return
+			}
+			if err != nil {
+				utils.SendError(w, http.StatusInternalServerError, "failed to check role", err)
+				return
+			}
+
+			if !repo.HasRole(userRole, role) {
+				utils.SendError(w, http.StatusForbidden, "requires the "+role+" role", nil)
+				return
+			}
+
+			user.Role = userRole
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// AuthenticateBotOrRole accepts either a request signed by the Discord bot or
+// a logged in user with at least role
+func (m *Middlewares) AuthenticateBotOrRole(role string) Middleware {
+	return func(next http.Handler) http.Handler {
+		bot := m.AuthenticateBot(next)
+		user := m.AuthenticateJWT(m.RequireRole(role)(next))
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if serviceauth.IsSigned(r) {
+				bot.ServeHTTP(w, r)
+				return
+			}
+			user.ServeHTTP(w, r)
+		})
+	}
+}
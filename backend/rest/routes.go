@@ -2,15 +2,34 @@ package rest
 
 import (
 	"net/http"
+	"time"
 
+	"github.com/NesoHQ/gw2style/repo"
 	"github.com/NesoHQ/gw2style/rest/middlewares"
 )
 
+// Rate limit policies, per logged in user or, on public routes, per client IP
+var (
+	// Login and key replacement make two GW2 API requests per unknown key
+	loginRateLimit   = middlewares.RateLimitPolicy{Name: "login", Limit: 10, Window: time.Minute}
+	apiKeyRateLimit  = middlewares.RateLimitPolicy{Name: "apikey", Limit: 10, Window: time.Minute}
+	refreshRateLimit = middlewares.RateLimitPolicy{Name: "refresh", Limit: 30, Window: time.Minute}
+	postRateLimit    = middlewares.RateLimitPolicy{Name: "posts", Limit: 5, Window: time.Hour}
+	// Edits and submissions send the post back to the Discord moderation channel
+	postEditRateLimit = middlewares.RateLimitPolicy{Name: "post-edits", Limit: 10, Window: time.Hour}
+	likeRateLimit     = middlewares.RateLimitPolicy{Name: "likes", Limit: 60, Window: time.Minute}
+	reportRateLimit   = middlewares.RateLimitPolicy{Name: "reports", Limit: 10, Window: time.Hour}
+	exportRateLimit   = middlewares.RateLimitPolicy{Name: "export", Limit: 5, Window: time.Hour}
+	// Character imports make up to four GW2 API requests with the user's key
+	characterRateLimit = middlewares.RateLimitPolicy{Name: "characters", Limit: 30, Window: time.Minute}
+)
+
 func (server *Server) initRoutes(mux *http.ServeMux, manager *middlewares.Manager) {
 	mux.Handle(
 		"POST /api/v1/login",
 		manager.With(
 			http.HandlerFunc(server.handlers.LoginHandler),
+			server.middlewares.RateLimit(loginRateLimit),
 			// server.middlewares.AuthenticateJWT,
 		),
 	)
@@ -40,6 +59,50 @@ func (server *Server) initRoutes(mux *http.ServeMux, manager *middlewares.Manage
 		"GET /api/v1/posts/{id}",
 		manager.With(
 			http.HandlerFunc(server.handlers.GetPostByIDHandler),
+			server.middlewares.OptionalJWT,
+		),
+	)
+
+	mux.Handle(
+		"GET /api/v1/tags",
+		manager.With(
+			http.HandlerFunc(server.handlers.GetTagsHandler),
+		),
+	)
+
+	// Local copy of the GW2 cosmetics, filled by `gw2style catalog sync`
+	mux.Handle(
+		"GET /api/v1/catalog/skins",
+		manager.With(
+			http.HandlerFunc(server.handlers.GetCatalogSkinsHandler),
+		),
+	)
+
+	mux.Handle(
+		"GET /api/v1/catalog/colors",
+		manager.With(
+			http.HandlerFunc(server.handlers.GetCatalogColorsHandler),
+		),
+	)
+
+	mux.Handle(
+		"GET /api/v1/catalog/outfits",
+		manager.With(
+			http.HandlerFunc(server.handlers.GetCatalogOutfitsHandler),
+		),
+	)
+
+	mux.Handle(
+		"GET /api/v1/catalog/gliders",
+		manager.With(
+			http.HandlerFunc(server.handlers.GetCatalogGlidersHandler),
+		),
+	)
+
+	mux.Handle(
+		"GET /api/v1/catalog/mount-skins",
+		manager.With(
+			http.HandlerFunc(server.handlers.GetCatalogMountSkinsHandler),
 		),
 	)
 
@@ -53,9 +116,69 @@ func (server *Server) initRoutes(mux *http.ServeMux, manager *middlewares.Manage
 	)
 
 	mux.Handle(
-		"POST /api/v1/logout",
+		"GET /api/v1/user/export",
+		manager.With(
+			http.HandlerFunc(server.handlers.ExportUserDataHandler),
+			server.middlewares.RateLimit(exportRateLimit),
+			server.middlewares.AuthenticateJWT,
+		),
+	)
+
+	mux.Handle(
+		"DELETE /api/v1/user",
+		manager.With(
+			http.HandlerFunc(server.handlers.DeleteUserHandler),
+			server.middlewares.RequireCSRF,
+			server.middlewares.AuthenticateJWT,
+		),
+	)
+
+	mux.Handle(
+		"GET /api/v1/user/posts",
+		manager.With(
+			http.HandlerFunc(server.handlers.GetUserPostsHandler),
+			server.middlewares.AuthenticateJWT,
+		),
+	)
+
+	mux.Handle(
+		"POST /api/v1/auth/refresh",
+		manager.With(
+			http.HandlerFunc(server.handlers.RefreshHandler),
+			server.middlewares.RequireCSRF,
+			server.middlewares.RateLimit(refreshRateLimit),
+		),
+	)
+
+	mux.Handle(
+		"GET /api/v1/csrf",
+		manager.With(
+			http.HandlerFunc(server.handlers.CSRFTokenHandler),
+		),
+	)
+
+	mux.Handle(
+		"GET /api/v1/user/sessions",
+		manager.With(
+			http.HandlerFunc(server.handlers.GetUserSessionsHandler),
+			server.middlewares.AuthenticateJWT,
+		),
+	)
+
+	mux.Handle(
+		"DELETE /api/v1/user/sessions/{id}",
+		manager.With(
+			http.HandlerFunc(server.handlers.RevokeSessionHandler),
+			server.middlewares.RequireCSRF,
+			server.middlewares.AuthenticateJWT,
+		),
+	)
+
+	mux.Handle(
+		"POST /api/v1/auth/logout",
 		manager.With(
 			http.HandlerFunc(server.handlers.LogoutHandler),
+			server.middlewares.RequireCSRF,
 		),
 	)
 
@@ -67,10 +190,50 @@ func (server *Server) initRoutes(mux *http.ServeMux, manager *middlewares.Manage
 		),
 	)
 
+	mux.Handle(
+		"PUT /api/v1/user/apikey",
+		manager.With(
+			http.HandlerFunc(server.handlers.ReplaceUserAPIKeyHandler),
+			server.middlewares.RequireCSRF,
+			server.middlewares.RateLimit(apiKeyRateLimit),
+			server.middlewares.AuthenticateJWT,
+		),
+	)
+
+	mux.Handle(
+		"GET /api/v1/user/characters",
+		manager.With(
+			http.HandlerFunc(server.handlers.GetCharactersHandler),
+			server.middlewares.RateLimit(characterRateLimit),
+			server.middlewares.AuthenticateJWT,
+		),
+	)
+
+	mux.Handle(
+		"GET /api/v1/user/characters/{name}/fashion",
+		manager.With(
+			http.HandlerFunc(server.handlers.GetCharacterFashionHandler),
+			server.middlewares.RateLimit(characterRateLimit),
+			server.middlewares.AuthenticateJWT,
+		),
+	)
+
 	mux.Handle(
 		"POST /api/v1/posts/create",
 		manager.With(
 			http.HandlerFunc(server.handlers.CreatePostHandler),
+			server.middlewares.RequireCSRF,
+			server.middlewares.RateLimit(postRateLimit),
+			server.middlewares.AuthenticateJWT,
+		),
+	)
+
+	mux.Handle(
+		"POST /api/v1/posts/{id}/submit",
+		manager.With(
+			http.HandlerFunc(server.handlers.SubmitPostHandler),
+			server.middlewares.RequireCSRF,
+			server.middlewares.RateLimit(postEditRateLimit),
 			server.middlewares.AuthenticateJWT,
 		),
 	)
@@ -79,6 +242,25 @@ func (server *Server) initRoutes(mux *http.ServeMux, manager *middlewares.Manage
 		"DELETE /api/v1/posts/{id}",
 		manager.With(
 			http.HandlerFunc(server.handlers.DeletePostHandler),
+			server.middlewares.RequireCSRF,
+			server.middlewares.AuthenticateJWT,
+		),
+	)
+
+	mux.Handle(
+		"PATCH /api/v1/posts/{id}",
+		manager.With(
+			http.HandlerFunc(server.handlers.UpdatePostHandler),
+			server.middlewares.RequireCSRF,
+			server.middlewares.RateLimit(postEditRateLimit),
+			server.middlewares.AuthenticateJWT,
+		),
+	)
+
+	mux.Handle(
+		"GET /api/v1/posts/{id}/revisions",
+		manager.With(
+			http.HandlerFunc(server.handlers.GetPostRevisionsHandler),
 			server.middlewares.AuthenticateJWT,
 		),
 	)
@@ -88,6 +270,8 @@ func (server *Server) initRoutes(mux *http.ServeMux, manager *middlewares.Manage
 		"POST /api/v1/posts/{id}/like",
 		manager.With(
 			http.HandlerFunc(server.handlers.LikePost),
+			server.middlewares.RequireCSRF,
+			server.middlewares.RateLimit(likeRateLimit),
 			server.middlewares.AuthenticateJWT,
 		),
 	)
@@ -96,6 +280,8 @@ func (server *Server) initRoutes(mux *http.ServeMux, manager *middlewares.Manage
 		"DELETE /api/v1/posts/{id}/like",
 		manager.With(
 			http.HandlerFunc(server.handlers.UnlikePost),
+			server.middlewares.RequireCSRF,
+			server.middlewares.RateLimit(likeRateLimit),
 			server.middlewares.AuthenticateJWT,
 		),
 	)
@@ -108,6 +294,13 @@ func (server *Server) initRoutes(mux *http.ServeMux, manager *middlewares.Manage
 		),
 	)
 
+	mux.Handle(
+		"GET /api/v1/posts/{id}/likes",
+		manager.With(
+			http.HandlerFunc(server.handlers.GetPostLikes),
+		),
+	)
+
 	// Get all liked posts for a user (for localStorage sync)
 	mux.Handle(
 		"GET /api/v1/user/liked-posts",
@@ -117,12 +310,13 @@ func (server *Server) initRoutes(mux *http.ServeMux, manager *middlewares.Manage
 		),
 	)
 
-	// Admin endpoints (bot-authenticated)
+	// Moderation endpoints (bot or web moderators)
 	mux.Handle(
 		"POST /api/v1/admin/posts/{id}/publish",
 		manager.With(
 			http.HandlerFunc(server.handlers.PublishPostHandler),
-			server.middlewares.AuthenticateBot,
+			server.middlewares.RequireCSRF,
+			server.middlewares.AuthenticateBotOrRole(repo.RoleModerator),
 		),
 	)
 
@@ -130,15 +324,119 @@ func (server *Server) initRoutes(mux *http.ServeMux, manager *middlewares.Manage
 		"POST /api/v1/admin/posts/{id}/reject",
 		manager.With(
 			http.HandlerFunc(server.handlers.RejectPostHandler),
+			server.middlewares.RequireCSRF,
+			server.middlewares.AuthenticateBotOrRole(repo.RoleModerator),
+		),
+	)
+
+	// Tag taxonomy endpoints (bot-authenticated)
+	mux.Handle(
+		"POST /api/v1/admin/tags",
+		manager.With(
+			http.HandlerFunc(server.handlers.CreateTagHandler),
 			server.middlewares.AuthenticateBot,
 		),
 	)
 
+	mux.Handle(
+		"POST /api/v1/admin/tags/{slug}/merge",
+		manager.With(
+			http.HandlerFunc(server.handlers.MergeTagHandler),
+			server.middlewares.AuthenticateBot,
+		),
+	)
+
+	// Report triage endpoints (bot or web moderators)
+	mux.Handle(
+		"GET /api/v1/admin/reports",
+		manager.With(
+			http.HandlerFunc(server.handlers.ListReportsHandler),
+			server.middlewares.AuthenticateBotOrRole(repo.RoleModerator),
+		),
+	)
+
+	mux.Handle(
+		"POST /api/v1/admin/reports/{id}/resolve",
+		manager.With(
+			http.HandlerFunc(server.handlers.ResolveReportHandler),
+			server.middlewares.RequireCSRF,
+			server.middlewares.AuthenticateBotOrRole(repo.RoleModerator),
+		),
+	)
+
+	mux.Handle(
+		"POST /api/v1/admin/reports/{id}/dismiss",
+		manager.With(
+			http.HandlerFunc(server.handlers.DismissReportHandler),
+			server.middlewares.RequireCSRF,
+			server.middlewares.AuthenticateBotOrRole(repo.RoleModerator),
+		),
+	)
+
+	// Ban endpoints (bot or web moderators)
+	mux.Handle(
+		"POST /api/v1/admin/bans",
+		manager.With(
+			http.HandlerFunc(server.handlers.CreateBanHandler),
+			server.middlewares.RequireCSRF,
+			server.middlewares.AuthenticateBotOrRole(repo.RoleModerator),
+		),
+	)
+
+	mux.Handle(
+		"GET /api/v1/admin/bans",
+		manager.With(
+			http.HandlerFunc(server.handlers.ListBansHandler),
+			server.middlewares.AuthenticateBotOrRole(repo.RoleModerator),
+		),
+	)
+
+	mux.Handle(
+		"POST /api/v1/admin/bans/{account}/lift",
+		manager.With(
+			http.HandlerFunc(server.handlers.LiftBanHandler),
+			server.middlewares.RequireCSRF,
+			server.middlewares.AuthenticateBotOrRole(repo.RoleModerator),
+		),
+	)
+
+	// Role management endpoints (admins only)
+	mux.Handle(
+		"PUT /api/v1/admin/users/{id}/role",
+		manager.With(
+			http.HandlerFunc(server.handlers.SetUserRoleHandler),
+			server.middlewares.RequireCSRF,
+			server.middlewares.RequireRole(repo.RoleAdmin),
+			server.middlewares.AuthenticateJWT,
+		),
+	)
+
+	mux.Handle(
+		"DELETE /api/v1/admin/users/{id}/role",
+		manager.With(
+			http.HandlerFunc(server.handlers.RevokeUserRoleHandler),
+			server.middlewares.RequireCSRF,
+			server.middlewares.RequireRole(repo.RoleAdmin),
+			server.middlewares.AuthenticateJWT,
+		),
+	)
+
+	mux.Handle(
+		"GET /api/v1/admin/roles/audit",
+		manager.With(
+			http.HandlerFunc(server.handlers.ListRoleChangesHandler),
+			server.middlewares.RequireRole(repo.RoleAdmin),
+			server.middlewares.AuthenticateJWT,
+		),
+	)
+
 	// Report endpoint (user-authenticated)
 	mux.Handle(
 		"POST /api/v1/posts/{id}/report",
 		manager.With(
 			http.HandlerFunc(server.handlers.CreateReportHandler),
+			server.middlewares.RequireCSRF,
+			server.middlewares.RateLimit(reportRateLimit),
 			server.middlewares.AuthenticateJWT,
 		),
 	)
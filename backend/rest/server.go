@@ -16,13 +16,20 @@ type Server struct {
 	middlewares *middlewares.Middlewares
 	handlers    *handlers.Handlers
 	cnf         *config.Config
+	corsOrigins []string
 	Wg          sync.WaitGroup
 }
 
 func NewServer(middlewares *middlewares.Middlewares, cnf *config.Config, handlers *handlers.Handlers) (*Server, error) {
+	corsOrigins, err := cnf.CorsOrigins()
+	if err != nil {
+		return nil, err
+	}
+
 	server := &Server{
 		middlewares: middlewares,
 		cnf:         cnf,
+		corsOrigins: corsOrigins,
 		handlers:    handlers,
 	}
 
@@ -41,7 +48,7 @@ func (server *Server) Start() {
 
 	server.initRoutes(mux, manager)
 
-	handler := middlewares.EnableCors(mux)
+	handler := middlewares.EnableCors(mux, server.corsOrigins)
 
 	//swagger.SetupSwagger(mux, manager)
 
@@ -6,20 +6,28 @@ import (
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/NesoHQ/gw2style/config"
 )
 
 type contextKey string
 
 const UserContextKey contextKey = "user"
 
+// clockSkew is the leeway allowed when checking exp, nbf and iat
+const clockSkew = 30 * time.Second
+
 type User struct {
-	ID   string `json:"id"`
-	Name string `json:"name"`
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	SessionID string `json:"-"`
+	Role      string `json:"-"` // Only set on routes behind RequireRole
 }
 
 type Claims struct {
-	UserID   string `json:"sub"`
-	Username string `json:"username"`
+	UserID    string `json:"sub"`
+	Username  string `json:"username"`
+	SessionID string `json:"sid"`
 	jwt.RegisteredClaims
 }
 
@@ -32,15 +40,60 @@ func GetUserFromContext(ctx context.Context) (*User, error) {
 	return user, nil
 }
 
+// JWTSigner issues and validates HS256 tokens. Tokens carry the ID of their
+// signing key in the kid header, so any configured key can verify them while
+// only the signing key issues new ones.
+type JWTSigner struct {
+	signingKey config.JWTKey
+	keys       map[string][]byte
+	issuer     string
+	audience   string
+	lifetime   time.Duration
+}
+
+func NewJWTSigner(cnf *config.Config) (*JWTSigner, error) {
+	keys, err := cnf.JWTKeys()
+	if err != nil {
+		return nil, err
+	}
+
+	signingKey, err := cnf.JWTSigningKey()
+	if err != nil {
+		return nil, err
+	}
+
+	signer := &JWTSigner{
+		signingKey: signingKey,
+		keys:       make(map[string][]byte, len(keys)),
+		issuer:     cnf.JwtIssuer,
+		audience:   cnf.JwtAudience,
+		lifetime:   cnf.AccessTokenLifetime(),
+	}
+	for _, key := range keys {
+		signer.keys[key.ID] = key.Secret
+	}
+
+	return signer, nil
+}
+
 // ValidateJWT validates the JWT token and returns the claims
-func ValidateJWT(tokenString string) (*Claims, error) {
+func (s *JWTSigner) ValidateJWT(tokenString string) (*Claims, error) {
 	claims := &Claims{}
 	token, err := jwt.ParseWithClaims(tokenString, claims, func(t *jwt.Token) (interface{}, error) {
-		if t.Method.Alg() != jwt.SigningMethodHS256.Alg() {
-			return nil, fmt.Errorf("unexpected signing method: %v", t.Header["alg"])
+		kid, _ := t.Header["kid"].(string)
+		secret, ok := s.keys[kid]
+		if !ok {
+			return nil, fmt.Errorf("unknown signing key %q", kid)
 		}
-		return []byte("secret"), nil
-	})
+		return secret, nil
+	},
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}),
+		jwt.WithIssuer(s.issuer),
+		jwt.WithAudience(s.audience),
+		jwt.WithIssuedAt(),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(clockSkew),
+	)
 
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse token: %w", err)
@@ -50,22 +103,47 @@ func ValidateJWT(tokenString string) (*Claims, error) {
 		return nil, fmt.Errorf("invalid token")
 	}
 
+	// nbf and iat are only checked by the parser when present; our tokens always carry them
+	if claims.NotBefore == nil || claims.IssuedAt == nil {
+		return nil, fmt.Errorf("token is missing nbf or iat")
+	}
+
+	if claims.SessionID == "" {
+		return nil, fmt.Errorf("token is not bound to a session")
+	}
+
 	return claims, nil
 }
 
-func GenerateJWT(user User) (string, error) {
-	expirationTime := time.Now().Add(30 * 24 * time.Hour).Unix() // 30 days (1 month)
+// Lifetime is how long issued access tokens stay valid
+func (s *JWTSigner) Lifetime() time.Duration {
+	return s.lifetime
+}
 
-	claims := jwt.MapClaims{
-		"sub":      user.ID,
-		"username": user.Name,
-		"exp":      expirationTime,
+// GenerateJWT issues a short-lived access token for the user's session
+func (s *JWTSigner) GenerateJWT(user User) (string, error) {
+	now := time.Now()
+
+	claims := Claims{
+		UserID:    user.ID,
+		Username:  user.Name,
+		SessionID: user.SessionID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    s.issuer,
+			Audience:  jwt.ClaimStrings{s.audience},
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(s.lifetime)),
+		},
 	}
 
-	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte("secret"))
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	token.Header["kid"] = s.signingKey.ID
+
+	signed, err := token.SignedString(s.signingKey.Secret)
 	if err != nil {
 		return "", fmt.Errorf("failed to generate jwt: %w", err)
 	}
 
-	return token, nil
+	return signed, nil
 }
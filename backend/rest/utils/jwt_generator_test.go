@@ -0,0 +1,185 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/NesoHQ/gw2style/config"
+)
+
+var (
+	oldJWTSecret = strings.Repeat("o", config.MinJWTSecretLength)
+	newJWTSecret = strings.Repeat("n", config.MinJWTSecretLength)
+)
+
+var testUser = User{ID: "acc-1", Name: "Player.1234", SessionID: "session-1"}
+
+func newTestJWTSigner(t *testing.T, keys, signingKeyID string) *JWTSigner {
+	t.Helper()
+	s, err := NewJWTSigner(&config.Config{
+		JwtKeys:         keys,
+		JwtSigningKeyID: signingKeyID,
+		JwtIssuer:       "gw2style",
+		JwtAudience:     "gw2style-web",
+		AccessTokenTTL:  15,
+	})
+	if err != nil {
+		t.Fatalf("NewJWTSigner: %v", err)
+	}
+	return s
+}
+
+// signClaims signs claims with secret under kid, bypassing JWTSigner
+func signClaims(t *testing.T, kid, secret string, claims Claims) string {
+	t.Helper()
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	token.Header["kid"] = kid
+	signed, err := token.SignedString([]byte(secret))
+	if err != nil {
+		t.Fatalf("SignedString: %v", err)
+	}
+	return signed
+}
+
+// validClaims returns the claims GenerateJWT would issue for testUser
+func validClaims() Claims {
+	now := time.Now()
+	return Claims{
+		UserID:    testUser.ID,
+		Username:  testUser.Name,
+		SessionID: testUser.SessionID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    "gw2style",
+			Audience:  jwt.ClaimStrings{"gw2style-web"},
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(15 * time.Minute)),
+		},
+	}
+}
+
+func TestGenerateAndValidateJWT(t *testing.T) {
+	signer := newTestJWTSigner(t, "old:"+oldJWTSecret, "")
+
+	token, err := signer.GenerateJWT(testUser)
+	if err != nil {
+		t.Fatalf("GenerateJWT: %v", err)
+	}
+
+	claims, err := signer.ValidateJWT(token)
+	if err != nil {
+		t.Fatalf("ValidateJWT: %v", err)
+	}
+	if claims.UserID != testUser.ID || claims.Username != testUser.Name || claims.SessionID != testUser.SessionID {
+		t.Errorf("claims = %+v", claims)
+	}
+	if lifetime := claims.ExpiresAt.Sub(claims.IssuedAt.Time); lifetime != signer.Lifetime() {
+		t.Errorf("token lifetime = %v, want %v", lifetime, signer.Lifetime())
+	}
+}
+
+func TestValidateJWTKeyRotation(t *testing.T) {
+	before := newTestJWTSigner(t, "old:"+oldJWTSecret, "")
+	during := newTestJWTSigner(t, "old:"+oldJWTSecret+",new:"+newJWTSecret, "new")
+	after := newTestJWTSigner(t, "new:"+newJWTSecret, "")
+
+	oldToken, err := before.GenerateJWT(testUser)
+	if err != nil {
+		t.Fatalf("GenerateJWT: %v", err)
+	}
+	newToken, err := during.GenerateJWT(testUser)
+	if err != nil {
+		t.Fatalf("GenerateJWT: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		signer  *JWTSigner
+		token   string
+		wantErr bool
+	}{
+		{"old token while both keys are listed", during, oldToken, false},
+		{"new token while both keys are listed", during, newToken, false},
+		{"new token after the old key is removed", after, newToken, false},
+		{"old token after the old key is removed", after, oldToken, true},
+		{"new token before the new key is listed", before, newToken, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.signer.ValidateJWT(tt.token)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	// New tokens carry the ID of the signing key
+	parsed, _, err := jwt.NewParser().ParseUnverified(newToken, &Claims{})
+	if err != nil {
+		t.Fatalf("ParseUnverified: %v", err)
+	}
+	if parsed.Header["kid"] != "new" {
+		t.Errorf("kid = %v, want new", parsed.Header["kid"])
+	}
+}
+
+func TestValidateJWTRejectsInvalidClaims(t *testing.T) {
+	signer := newTestJWTSigner(t, "old:"+oldJWTSecret, "")
+
+	tests := []struct {
+		name   string
+		kid    string
+		secret string
+		modify func(*Claims)
+	}{
+		{"wrong issuer", "old", oldJWTSecret, func(c *Claims) { c.Issuer = "someone-else" }},
+		{"wrong audience", "old", oldJWTSecret, func(c *Claims) { c.Audience = jwt.ClaimStrings{"someone-else"} }},
+		{"not valid yet", "old", oldJWTSecret, func(c *Claims) {
+			c.NotBefore = jwt.NewNumericDate(time.Now().Add(clockSkew + time.Minute))
+		}},
+		{"expired", "old", oldJWTSecret, func(c *Claims) {
+			c.ExpiresAt = jwt.NewNumericDate(time.Now().Add(-clockSkew - time.Minute))
+		}},
+		{"missing expiry", "old", oldJWTSecret, func(c *Claims) { c.ExpiresAt = nil }},
+		{"missing nbf", "old", oldJWTSecret, func(c *Claims) { c.NotBefore = nil }},
+		{"missing session", "old", oldJWTSecret, func(c *Claims) { c.SessionID = "" }},
+		{"unknown kid", "unknown", oldJWTSecret, func(c *Claims) {}},
+		{"no kid", "", oldJWTSecret, func(c *Claims) {}},
+		{"wrong secret", "old", newJWTSecret, func(c *Claims) {}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims := validClaims()
+			tt.modify(&claims)
+			if _, err := signer.ValidateJWT(signClaims(t, tt.kid, tt.secret, claims)); err == nil {
+				t.Error("ValidateJWT accepted the token")
+			}
+		})
+	}
+
+	// Within the allowed clock skew a token is still accepted
+	claims := validClaims()
+	claims.NotBefore = jwt.NewNumericDate(time.Now().Add(clockSkew / 2))
+	if _, err := signer.ValidateJWT(signClaims(t, "old", oldJWTSecret, claims)); err != nil {
+		t.Errorf("ValidateJWT rejected a token within the clock skew: %v", err)
+	}
+}
+
+func TestValidateJWTRejectsOtherAlgorithms(t *testing.T) {
+	signer := newTestJWTSigner(t, "old:"+oldJWTSecret, "")
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS512, validClaims())
+	token.Header["kid"] = "old"
+	signed, err := token.SignedString([]byte(oldJWTSecret))
+	if err != nil {
+		t.Fatalf("SignedString: %v", err)
+	}
+	if _, err := signer.ValidateJWT(signed); err == nil {
+		t.Error("ValidateJWT accepted an HS512 token")
+	}
+}
@@ -0,0 +1,42 @@
+package serviceauth
+
+import (
+	"sync"
+	"time"
+)
+
+// nonceCache remembers nonces until the timestamp they were sent with falls
+// out of the allowed window; after that the timestamp check rejects replays.
+// It is in memory, which is enough while the bot talks to the API process it
+// runs in.
+type nonceCache struct {
+	mu        sync.Mutex
+	seen      map[string]time.Time
+	lastSweep time.Time
+}
+
+func newNonceCache() *nonceCache {
+	return &nonceCache{seen: make(map[string]time.Time), lastSweep: time.Now()}
+}
+
+// add records nonce until expires and reports false if it was already seen
+func (c *nonceCache) add(nonce string, expires time.Time) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(c.lastSweep) > MaxClockSkew {
+		for n, exp := range c.seen {
+			if now.After(exp) {
+				delete(c.seen, n)
+			}
+		}
+		c.lastSweep = now
+	}
+
+	if exp, ok := c.seen[nonce]; ok && now.Before(exp) {
+		return false
+	}
+	c.seen[nonce] = expires
+	return true
+}
@@ -0,0 +1,162 @@
+package serviceauth
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/NesoHQ/gw2style/config"
+)
+
+var (
+	botSecret   = strings.Repeat("b", config.MinServiceKeySecretLength)
+	otherSecret = strings.Repeat("o", config.MinServiceKeySecretLength)
+)
+
+func newTestSigner(t *testing.T, keys, keyID string) *Signer {
+	t.Helper()
+	s, err := NewSigner(&config.Config{ServiceAuthKeys: keys, ServiceAuthKeyID: keyID})
+	if err != nil {
+		t.Fatalf("NewSigner: %v", err)
+	}
+	return s
+}
+
+func newTestVerifier(t *testing.T, keys string) *Verifier {
+	t.Helper()
+	v, err := NewVerifier(&config.Config{ServiceAuthKeys: keys})
+	if err != nil {
+		t.Fatalf("NewVerifier: %v", err)
+	}
+	return v
+}
+
+// signedRequest builds a request with body and signs it with s
+func signedRequest(t *testing.T, s *Signer, method, target, body string) *http.Request {
+	t.Helper()
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	if err := s.Sign(req, []byte(body)); err != nil {
+		t.Fatalf("Sign: %v", err)
+	}
+	return req
+}
+
+func TestVerifyAcceptsSignedRequest(t *testing.T) {
+	signer := newTestSigner(t, "bot:"+botSecret, "")
+	verifier := newTestVerifier(t, "bot:"+botSecret)
+
+	req := signedRequest(t, signer, http.MethodPost, "/api/v1/admin/bans?notify=1", `{"account":"Player.1234"}`)
+	if !IsSigned(req) {
+		t.Fatal("IsSigned = false for a signed request")
+	}
+	if err := verifier.Verify(req); err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+
+	// Handlers can still read the body
+	body, _ := io.ReadAll(req.Body)
+	if string(body) != `{"account":"Player.1234"}` {
+		t.Errorf("body = %q after Verify", body)
+	}
+}
+
+func TestVerifyRejectsReplay(t *testing.T) {
+	signer := newTestSigner(t, "bot:"+botSecret, "")
+	verifier := newTestVerifier(t, "bot:"+botSecret)
+
+	req := signedRequest(t, signer, http.MethodPost, "/api/v1/admin/bans", `{}`)
+	if err := verifier.Verify(req); err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+
+	replay := httptest.NewRequest(http.MethodPost, "/api/v1/admin/bans", strings.NewReader(`{}`))
+	replay.Header = req.Header.Clone()
+	if err := verifier.Verify(replay); !errors.Is(err, ErrReplayed) {
+		t.Errorf("err = %v, want ErrReplayed", err)
+	}
+}
+
+func TestVerifyRejectsTampering(t *testing.T) {
+	signer := newTestSigner(t, "bot:"+botSecret, "")
+	verifier := newTestVerifier(t, "bot:"+botSecret)
+
+	tests := map[string]func(*http.Request) *http.Request{
+		"body": func(req *http.Request) *http.Request {
+			tampered := httptest.NewRequest(req.Method, req.URL.RequestURI(), strings.NewReader(`{"account":"Other.5678"}`))
+			tampered.Header = req.Header
+			return tampered
+		},
+		"method": func(req *http.Request) *http.Request {
+			req.Method = http.MethodDelete
+			return req
+		},
+		"uri": func(req *http.Request) *http.Request {
+			tampered := httptest.NewRequest(req.Method, "/api/v1/admin/roles", req.Body)
+			tampered.Header = req.Header
+			return tampered
+		},
+		"nonce": func(req *http.Request) *http.Request {
+			req.Header.Set(HeaderNonce, "00000000000000000000000000000000")
+			return req
+		},
+	}
+
+	for name, tamper := range tests {
+		t.Run(name, func(t *testing.T) {
+			req := signedRequest(t, signer, http.MethodPost, "/api/v1/admin/bans", `{"account":"Player.1234"}`)
+			if err := verifier.Verify(tamper(req)); !errors.Is(err, ErrBadSignature) {
+				t.Errorf("err = %v, want ErrBadSignature", err)
+			}
+		})
+	}
+}
+
+func TestVerifyRejectsStaleTimestamp(t *testing.T) {
+	signer := newTestSigner(t, "bot:"+botSecret, "")
+	verifier := newTestVerifier(t, "bot:"+botSecret)
+
+	for _, offset := range []time.Duration{-MaxClockSkew - time.Minute, MaxClockSkew + time.Minute} {
+		req := signedRequest(t, signer, http.MethodGet, "/api/v1/admin/bans", "")
+		req.Header.Set(HeaderTimestamp, strconv.FormatInt(time.Now().Add(offset).Unix(), 10))
+		if err := verifier.Verify(req); !errors.Is(err, ErrStaleTimestamp) {
+			t.Errorf("offset %v: err = %v, want ErrStaleTimestamp", offset, err)
+		}
+	}
+}
+
+func TestVerifyKeys(t *testing.T) {
+	verifier := newTestVerifier(t, "bot:"+botSecret+",next:"+otherSecret)
+
+	// Both keys are accepted while the signing key is rotated
+	for _, keyID := range []string{"bot", "next"} {
+		signer := newTestSigner(t, "bot:"+botSecret+",next:"+otherSecret, keyID)
+		req := signedRequest(t, signer, http.MethodGet, "/api/v1/admin/bans", "")
+		if err := verifier.Verify(req); err != nil {
+			t.Errorf("key %s: Verify: %v", keyID, err)
+		}
+	}
+
+	unknown := newTestSigner(t, "old:"+botSecret, "")
+	if err := verifier.Verify(signedRequest(t, unknown, http.MethodGet, "/api/v1/admin/bans", "")); !errors.Is(err, ErrUnknownKey) {
+		t.Errorf("err = %v, want ErrUnknownKey", err)
+	}
+
+	// A known key ID with the wrong secret
+	forged := newTestSigner(t, "bot:"+otherSecret, "")
+	if err := verifier.Verify(signedRequest(t, forged, http.MethodGet, "/api/v1/admin/bans", "")); !errors.Is(err, ErrBadSignature) {
+		t.Errorf("err = %v, want ErrBadSignature", err)
+	}
+
+	unsigned := httptest.NewRequest(http.MethodGet, "/api/v1/admin/bans", nil)
+	if IsSigned(unsigned) {
+		t.Error("IsSigned = true for an unsigned request")
+	}
+	if err := verifier.Verify(unsigned); !errors.Is(err, ErrMissingSignature) {
+		t.Errorf("err = %v, want ErrMissingSignature", err)
+	}
+}
@@ -3,7 +3,8 @@ import { useRouter } from 'next/router';
 import { useUser } from '../context/UserContext';
 import Layout from '@components/Layout';
 import styles from '../styles/CreatePost.module.css';
-import { generateTagsFromEquipment, categorizeTags } from '../utils/gw2AutoTagger';
+import { categorizeTags, filterTaxonomyTags } from '../utils/gw2AutoTagger';
+import { postsApi } from '../utils/postsApi';
 
 export default function CreatePost() {
   const router = useRouter();
@@ -26,15 +27,12 @@ export default function CreatePost() {
   const [categorizedTags, setCategorizedTags] = useState(null);
   const [generatingTags, setGeneratingTags] = useState(false);
   
-  // GW2 API related state
-  const [apiKey, setApiKey] = useState('');
+  // GW2 character related state; the backend reads the account with the user's API key
   const [characters, setCharacters] = useState([]);
+  const [charactersError, setCharactersError] = useState('');
   const [selectedCharacter, setSelectedCharacter] = useState('');
-  const [equipmentTabs, setEquipmentTabs] = useState([]);
-  const [selectedTab, setSelectedTab] = useState('');
-  const [loadingApiKey, setLoadingApiKey] = useState(false);
+  const [equipments, setEquipments] = useState(null);
   const [loadingCharacters, setLoadingCharacters] = useState(false);
-  const [loadingEquipment, setLoadingEquipment] = useState(false);
 
   // Redirect if not logged in (but wait for auth check to complete)
   useEffect(() => {
@@ -59,53 +57,26 @@ export default function CreatePost() {
     return null;
   }
 
-  // Fetch API key from backend on component mount
-  const fetchApiKey = async () => {
-    setLoadingApiKey(true);
+  // Fetch the account's characters on mount
+  const fetchCharacters = async () => {
+    setLoadingCharacters(true);
+    setCharactersError('');
+
     try {
-      // Note: HttpOnly cookies can't be read by JavaScript, but browser will send them
-      // Just try to fetch the API key - if user is logged in, it will work
-      
-      // Call backend directly with credentials
-      const apiUrl = process.env.NEXT_PUBLIC_API_URL;
-      const response = await fetch(`${apiUrl}/api/v1/user/apikey`, {
-        method: 'GET',
-        credentials: 'include',
-        headers: {
-          'Content-Type': 'application/json',
-        },
-      });
-      
-      if (response.ok) {
-        const data = await response.json();
-        console.log('API key response:', data);
-        
-        // Backend returns { data: { apiKey: "..." }, success: true }
-        const key = data.data?.apiKey || data.apiKey;
-        
-        if (key) {
-          setApiKey(key);
-          // Automatically fetch characters once we have the API key
-          fetchCharactersWithKey(key);
-        } else {
-          console.log('No API key found in user profile');
-        }
-      } else {
-        const errorData = await response.json();
-        console.log('Could not fetch API key:', response.status, errorData.message);
-        // Don't show error - user might just not have API key set
-      }
+      const data = await postsApi.getCharacters();
+      setCharacters(data.characters || []);
     } catch (err) {
-      console.error('Failed to fetch API key:', err);
+      console.error('Failed to fetch characters:', err);
+      setCharacters([]);
+      setCharactersError(err.message);
     } finally {
-      setLoadingApiKey(false);
+      setLoadingCharacters(false);
     }
   };
 
-  // Fetch API key on mount
   useEffect(() => {
     if (user) {
-      fetchApiKey();
+      fetchCharacters();
     }
   }, [user]);
 
@@ -117,104 +88,32 @@ export default function CreatePost() {
     }));
   };
 
-  // Fetch characters from GW2 API with provided key
-  const fetchCharactersWithKey = async (key) => {
-    if (!key) return;
+  // Handle character selection: prefill the equipment and tags from what the character wears
+  const handleCharacterChange = async (e) => {
+    const characterName = e.target.value;
+    setSelectedCharacter(characterName);
+    setEquipments(null);
+    setAutoGeneratedTags([]);
+    setCategorizedTags(null);
 
-    setLoadingCharacters(true);
-    
-    try {
-      const response = await fetch(`https://api.guildwars2.com/v2/characters?access_token=${key}`);
-      
-      if (!response.ok) {
-        throw new Error('Failed to fetch characters');
-      }
-      
-      const data = await response.json();
-      setCharacters(data);
-      setSelectedCharacter('');
-      setEquipmentTabs([]);
-      setSelectedTab('');
-    } catch (err) {
-      console.error('Failed to fetch characters:', err);
-      setCharacters([]);
-    } finally {
-      setLoadingCharacters(false);
+    if (!characterName) {
+      return;
     }
-  };
-
-  // Fetch equipment tabs for selected character
-  const fetchEquipmentTabs = async (characterName) => {
-    if (!characterName || !apiKey) return;
 
-    setLoadingEquipment(true);
+    setGeneratingTags(true);
     setError('');
-    
+
     try {
-      const encodedName = encodeURIComponent(characterName);
-      const response = await fetch(
-        `https://api.guildwars2.com/v2/characters/${encodedName}/equipmenttabs?tabs=all&access_token=${apiKey}`
-      );
-      
-      if (!response.ok) {
-        throw new Error('Failed to fetch equipment tabs');
-      }
-      
-      const data = await response.json();
-      setEquipmentTabs(data);
-      setSelectedTab('');
+      const { data } = await postsApi.getCharacterFashion(characterName);
+      const tags = data.suggested_tags || [];
+      setEquipments(data.equipments);
+      setAutoGeneratedTags(tags);
+      setCategorizedTags(categorizeTags(tags));
     } catch (err) {
-      setError(err.message);
-      setEquipmentTabs([]);
+      console.error('Failed to import character:', err);
+      setError(`Failed to import ${characterName}: ${err.message}`);
     } finally {
-      setLoadingEquipment(false);
-    }
-  };
-
-  // Handle character selection
-  const handleCharacterChange = (e) => {
-    const characterName = e.target.value;
-    setSelectedCharacter(characterName);
-    if (characterName) {
-      fetchEquipmentTabs(characterName);
-    } else {
-      setEquipmentTabs([]);
-      setSelectedTab('');
-      setAutoGeneratedTags([]);
-      setCategorizedTags(null);
-    }
-  };
-
-  // Handle equipment tab selection and auto-generate tags
-  const handleEquipmentTabChange = async (e) => {
-    const tabName = e.target.value;
-    setSelectedTab(tabName);
-    
-    if (tabName && selectedCharacter && apiKey) {
-      setGeneratingTags(true);
-      setError('');
-      
-      try {
-        console.log('Generating tags for:', selectedCharacter, tabName);
-        const tags = await generateTagsFromEquipment(selectedCharacter, tabName, apiKey);
-        setAutoGeneratedTags(tags);
-        
-        // Categorize tags for preview
-        const categorized = categorizeTags(tags);
-        setCategorizedTags(categorized);
-        
-        console.log('Tags generated successfully:', tags);
-      } catch (err) {
-        console.error('Failed to generate tags:', err);
-        setError('Failed to generate tags from equipment. Please try again.');
-        setAutoGeneratedTags([]);
-        setCategorizedTags(null);
-      } finally {
-        setGeneratingTags(false);
-      }
-    } else {
-      setAutoGeneratedTags([]);
-      setCategorizedTags(null);
+      setGeneratingTags(false);
     }
   };
 
@@ -234,7 +133,7 @@ export default function CreatePost() {
 
     // Validate that tags were generated
     if (autoGeneratedTags.length === 0) {
-      setError('Please select a character and equipment tab to generate tags');
+      setError('Please select a character to generate tags');
       return false;
     }
 
@@ -276,11 +175,13 @@ export default function CreatePost() {
     setLoading(true);
 
     try {
-      // Get the selected equipment tab data - send the full tab object
-      const selectedEquipment = equipmentTabs.find(tab => tab.name === selectedTab);
-      
+      // Tags outside the taxonomy would get the whole post rejected; leave them out
+      const { known: tags, unknown } = filterTaxonomyTags(autoGeneratedTags, await postsApi.getTags());
+      if (unknown.length > 0) {
+        console.warn('Leaving out tags missing from the taxonomy:', unknown);
+      }
+
       // Use postsApi service for direct backend communication
-      const { postsApi } = await import('../utils/postsApi');
       const data = await postsApi.createPost({
         title: formData.title,
         description: formData.description,
@@ -290,8 +191,8 @@ export default function CreatePost() {
         image3Url: formData.image3_url,
         image4Url: formData.image4_url,
         image5Url: formData.image5_url,
-        equipments: selectedEquipment || {}, // Send full equipment tab object
-        tags: autoGeneratedTags, // Send auto-generated tags
+        equipments: equipments, // Imported from the selected character
+        tags, // Auto-generated tags known to the taxonomy
         published: true,
       });
 
@@ -409,23 +310,19 @@ export default function CreatePost() {
             <div className={styles.apiSection}>
               <h3>Guild Wars 2 Equipment</h3>
               
-              {loadingApiKey && (
-                <div className={styles.loading}>Loading your GW2 data...</div>
+              {loadingCharacters && (
+                <div className={styles.loading}>Loading characters...</div>
               )}
 
-              {!loadingApiKey && !apiKey && (
+              {!loadingCharacters && charactersError && (
                 <div className={styles.note}>
                   <p>
-                    No GW2 API key found. Please add your API key in your profile settings to automatically fetch equipment data.
+                    Could not load your characters: {charactersError}. Check the GW2 API key in your profile settings.
                   </p>
                 </div>
               )}
 
-              {apiKey && loadingCharacters && (
-                <div className={styles.loading}>Loading characters...</div>
-              )}
-
-              {apiKey && characters.length > 0 && (
+              {characters.length > 0 && (
                 <div className={styles.formGroup}>
                   <label htmlFor="character">Select Character</label>
                   <select
@@ -444,32 +341,9 @@ export default function CreatePost() {
                 </div>
               )}
 
-              {loadingEquipment && (
-                <div className={styles.loading}>Loading equipment tabs...</div>
-              )}
-
-              {equipmentTabs.length > 0 && (
-                <div className={styles.formGroup}>
-                  <label htmlFor="equipmentTab">Select Equipment Tab</label>
-                  <select
-                    id="equipmentTab"
-                    value={selectedTab}
-                    onChange={handleEquipmentTabChange}
-                    className={styles.select}
-                  >
-                    <option value="">-- Choose an equipment tab --</option>
-                    {equipmentTabs.map((tab) => (
-                      <option key={tab.tab} value={tab.name}>
-                        {tab.name} {tab.is_active ? '(Active)' : ''}
-                      </option>
-                    ))}
-                  </select>
-                </div>
-              )}
-
               {generatingTags && (
                 <div className={styles.loading}>
-                  🔄 Generating tags from equipment...
+                  🔄 Importing equipment and tags from your character...
                 </div>
               )}
 
@@ -495,6 +369,12 @@ export default function CreatePost() {
                         <span className={styles.tagValue}>{categorizedTags.class}</span>
                       </div>
                     )}
+                    {categorizedTags.armorWeight && (
+                      <div className={styles.tagCategory}>
+                        <span className={styles.tagLabel}>Armor Weight:</span>
+                        <span className={styles.tagValue}>{categorizedTags.armorWeight}</span>
+                      </div>
+                    )}
                     {categorizedTags.colors.length > 0 && (
                       <div className={styles.tagCategory}>
                         <span className={styles.tagLabel}>Dye Colors:</span>
@@ -525,8 +405,8 @@ export default function CreatePost() {
               <p>
                 * Required fields
                 <br />
-                Note: Your character's equipment details will be automatically
-                fetched from your Guild Wars 2 account using your API key.
+                Note: Your character's equipment is imported from your Guild Wars 2
+                account using the API key saved in your profile.
               </p>
             </div>
 
@@ -2,6 +2,7 @@ import { useEffect, useState } from 'react';
 import { useRouter } from 'next/router';
 import { useUser } from '../context/UserContext';
 import Layout from '@components/Layout';
+import apiClient from '../utils/apiClient';
 
 export default function UserPage() {
   const router = useRouter();
@@ -52,31 +53,19 @@ export default function UserPage() {
     }
 
     try {
-      const apiUrl = process.env.NEXT_PUBLIC_API_URL;
-      const response = await fetch(
-        `${apiUrl}/api/v1/posts/search?author=${encodeURIComponent(user.username)}&limit=100&page=${page}`,
-        {
-          credentials: 'include',
-          headers: {
-            'Content-Type': 'application/json',
-          },
-        }
+      const data = await apiClient.request(
+        `/api/v1/posts/search?author=${encodeURIComponent(user.username)}&limit=100&page=${page}`
       );
-      const data = await response.json();
 
-      if (response.ok) {
-        const newPosts = data.data || [];
-        if (append) {
-          setPosts(prev => [...prev, ...newPosts]);
-        } else {
-          setPosts(newPosts);
-        }
-        setTotalPosts(data.pagination?.total || 0);
-        setCurrentPage(page);
-        setHasMore(page < (data.pagination?.total_pages || 1));
+      const newPosts = data.data || [];
+      if (append) {
+        setPosts(prev => [...prev, ...newPosts]);
       } else {
-        console.error('Failed to fetch user posts:', data.error);
+        setPosts(newPosts);
       }
+      setTotalPosts(data.pagination?.total || 0);
+      setCurrentPage(page);
+      setHasMore(page < (data.pagination?.total_pages || 1));
     } catch (error) {
       console.error('Error fetching user posts:', error);
     } finally {
@@ -109,26 +98,12 @@ export default function UserPage() {
     closeDeleteModal();
 
     try {
-      const apiUrl = process.env.NEXT_PUBLIC_API_URL;
-      const response = await fetch(`${apiUrl}/api/v1/posts/${postId}`, {
-        method: 'DELETE',
-        credentials: 'include',
-        headers: {
-          'Content-Type': 'application/json',
-        },
-      });
-
-      const data = await response.json();
-
-      if (response.ok) {
-        // Remove the post from the list
-        setPosts(posts.filter(post => post.id !== postId));
-      } else {
-        alert(data.error || 'Failed to delete post');
-      }
+      await apiClient.delete(`/api/v1/posts/${postId}`);
+      // Remove the post from the list
+      setPosts(posts.filter(post => post.id !== postId));
     } catch (error) {
       console.error('Error deleting post:', error);
-      alert('Failed to delete post');
+      alert(error.message || 'Failed to delete post');
     }
   };
 
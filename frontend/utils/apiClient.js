@@ -4,14 +4,65 @@ class APIClient {
     this.baseURL = baseURL || process.env.NEXT_PUBLIC_API_URL;
     this.cache = new Map();
     this.cacheTimeout = 5 * 60 * 1000; // 5 minutes
+    this.csrfToken = null;
   }
 
-  async request(endpoint, options = {}) {
+  // The API is on another origin, so the csrf_token cookie can't be read here.
+  // The token comes from the login and refresh responses, or from /api/v1/csrf after a reload.
+  async getCSRFToken() {
+    if (!this.csrfToken) {
+      try {
+        const response = await fetch(`${this.baseURL}/api/v1/csrf`, { credentials: 'include' });
+        if (response.ok) {
+          const data = await response.json();
+          this.csrfToken = data.csrfToken || null;
+        }
+      } catch (e) {
+        // Not logged in or offline; the request itself will report the error
+      }
+    }
+    return this.csrfToken;
+  }
+
+  // Headers for state-changing requests authenticated by cookies
+  async csrfHeaders() {
+    const token = await this.getCSRFToken();
+    return token ? { 'X-CSRF-Token': token } : {};
+  }
+
+  // Exchange the refresh token cookie for a new access token.
+  // Concurrent callers share one request so the refresh token is only rotated once.
+  refreshSession() {
+    if (!this.refreshing) {
+      this.refreshing = this.csrfHeaders()
+        .then(headers => fetch(`${this.baseURL}/api/v1/auth/refresh`, {
+          method: 'POST',
+          headers,
+          credentials: 'include',
+        }))
+        .then(async response => {
+          if (!response.ok) return false;
+          const data = await response.json();
+          this.csrfToken = data.csrfToken || this.csrfToken;
+          return true;
+        })
+        .catch(() => false)
+        .finally(() => {
+          this.refreshing = null;
+        });
+    }
+    return this.refreshing;
+  }
+
+  async request(endpoint, options = {}, retried = false) {
     const url = `${this.baseURL}${endpoint}`;
+    const method = options.method || 'GET';
+    const csrf = method === 'GET' || endpoint === '/api/v1/login' ? {} : await this.csrfHeaders();
     const config = {
       ...options,
       headers: {
         'Content-Type': 'application/json',
+        ...csrf,
         ...options.headers,
       },
       credentials: 'include', // Include HTTP-only cookies
@@ -19,6 +70,22 @@ class APIClient {
 
     try {
       const response = await fetch(url, config);
+
+      // Access tokens are short-lived; refresh once and retry
+      if (response.status === 401 && !retried && endpoint !== '/api/v1/login') {
+        if (await this.refreshSession()) {
+          return this.request(endpoint, options, true);
+        }
+      }
+
+      // The token belongs to an older session; fetch the current one and retry
+      if (response.status === 403 && !retried && csrf['X-CSRF-Token']) {
+        const error = await response.clone().json().catch(() => ({}));
+        if (error.message === 'invalid CSRF token') {
+          this.csrfToken = null;
+          return this.request(endpoint, options, true);
+        }
+      }
       
       if (!response.ok) {
         let error;
@@ -36,7 +103,11 @@ class APIClient {
         throw new Error(error.message || error.error || `Request failed with status ${response.status}`);
       }
 
-      return response.json();
+      const data = await response.json();
+      if (data && data.csrfToken) {
+        this.csrfToken = data.csrfToken;
+      }
+      return data;
     } catch (error) {
       // Network error or other fetch failure
       if (error.name === 'TypeError' && error.message.includes('fetch')) {
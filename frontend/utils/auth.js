@@ -56,11 +56,12 @@ export const auth = {
   async logout() {
     // Call backend logout endpoint to clear HTTP-only cookie
     try {
-      await apiClient.post('/api/v1/logout');
+      await apiClient.post('/api/v1/auth/logout');
     } catch (e) {
       console.error('Logout error:', e);
       // Continue even if backend call fails
     }
+    apiClient.csrfToken = null;
   },
 
   async getCurrentUser() {
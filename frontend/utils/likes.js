@@ -4,6 +4,8 @@
  * Manages like/unlike operations with localStorage caching for instant UI updates
  */
 
+import apiClient from './apiClient';
+
 const LIKED_POSTS_KEY = 'gw2_liked_posts';
 
 /**
@@ -82,20 +84,8 @@ export function clearLikedCache() {
  */
 export async function syncLikedPostsFromBackend() {
   try {
-    const apiUrl = process.env.NEXT_PUBLIC_API_URL;
-    const response = await fetch(`${apiUrl}/api/v1/user/liked-posts`, {
-      credentials: 'include', // Include cookies for JWT
-      headers: {
-        'Content-Type': 'application/json',
-      },
-    });
-
-    if (!response.ok) {
-      throw new Error('Failed to fetch liked posts');
-    }
+    const data = await apiClient.request('/api/v1/user/liked-posts');
 
-    const data = await response.json();
-    
     if (data.success && data.liked_posts) {
       const likedPosts = Array.isArray(data.liked_posts) 
         ? data.liked_posts 
@@ -122,21 +112,13 @@ export async function likePost(postId) {
     // Optimistic update
     addToLikedCache(postId);
 
-    const apiUrl = process.env.NEXT_PUBLIC_API_URL;
-    const response = await fetch(`${apiUrl}/api/v1/posts/${postId}/like`, {
-      method: 'POST',
-      credentials: 'include',
-      headers: {
-        'Content-Type': 'application/json',
-      },
-    });
-
-    const data = await response.json();
-
-    if (!response.ok) {
+    let data;
+    try {
+      data = await apiClient.request(`/api/v1/posts/${postId}/like`, { method: 'POST' });
+    } catch (error) {
       // Rollback on error
       removeFromLikedCache(postId);
-      throw new Error(data.error || 'Failed to like post');
+      throw error;
     }
 
     return {
@@ -159,21 +141,13 @@ export async function unlikePost(postId) {
     // Optimistic update
     removeFromLikedCache(postId);
 
-    const apiUrl = process.env.NEXT_PUBLIC_API_URL;
-    const response = await fetch(`${apiUrl}/api/v1/posts/${postId}/like`, {
-      method: 'DELETE',
-      credentials: 'include',
-      headers: {
-        'Content-Type': 'application/json',
-      },
-    });
-
-    const data = await response.json();
-
-    if (!response.ok) {
+    let data;
+    try {
+      data = await apiClient.request(`/api/v1/posts/${postId}/like`, { method: 'DELETE' });
+    } catch (error) {
       // Rollback on error
       addToLikedCache(postId);
-      throw new Error(data.error || 'Failed to unlike post');
+      throw error;
     }
 
     return {
@@ -34,6 +34,19 @@ export const postsApi = {
     return apiClient.get('/api/v1/user/liked-posts');
   },
 
+  async getTags() {
+    return apiClient.get('/api/v1/tags');
+  },
+
+  async getCharacters() {
+    return apiClient.get('/api/v1/user/characters');
+  },
+
+  // Not cached: the character's look may have just changed in game
+  async getCharacterFashion(name) {
+    return apiClient.request(`/api/v1/user/characters/${encodeURIComponent(name)}/fashion`);
+  },
+
   async getSkins() {
     return apiClient.get('/api/v1/skins');
   },